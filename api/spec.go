@@ -0,0 +1,8 @@
+package apidef
+
+import _ "embed"
+
+// Spec is the bundled OpenAPI spec the server is generated from.
+//
+//go:embed swimlogsAPI.gen.yaml
+var Spec []byte
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/config"
+)
+
+const backupUsage = `Usage: swimlogs backup [flags] [file]
+
+Writes an archive of everything stored to file, or to stdout when file is
+missing or "-".
+
+Flags are the same as the server's, run swimlogs -h to list them.
+`
+
+const restoreUsage = `Usage: swimlogs restore [flags] file [merge|replace]
+
+Imports an archive written by swimlogs backup from file, or from stdin when
+file is "-". Everything is imported in one transaction.
+
+Modes:
+  merge      overwrite stored entities with the ones from the archive and
+             keep the rest, the default
+  replace    delete everything stored before importing the archive
+
+Flags are the same as the server's, run swimlogs -h to list them.
+`
+
+// backupCommand runs the backup subcommand with args after "backup" and
+// returns exit code.
+func backupCommand(args []string) int {
+	cfg, code, ok := loadCommandConfig("swimlogs backup", args, backupUsage, nil)
+	if !ok {
+		return code
+	}
+	if len(cfg.Args) > 1 {
+		fmt.Fprint(os.Stderr, backupUsage)
+		return 2
+	}
+
+	// stdout can be the archive, so logs always go to stderr
+	setupLogger(cfg, os.Stderr)
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		log.Error().Err(err).Str("driver", cfg.Database.Driver).Msg("failed to connect to database")
+		return 1
+	}
+	defer db.Close()
+
+	path := "-"
+	if len(cfg.Args) == 1 {
+		path = cfg.Args[0]
+	}
+
+	out := os.Stdout
+	if path != "-" {
+		out, err = os.Create(path)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create backup file")
+			return 1
+		}
+		defer out.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	w := bufio.NewWriter(out)
+	swimlogs := app.New(db, cfg.Database.Timeout, nil)
+	contents, err := swimlogs.Backup(ctx, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil && path != "-" {
+		err = out.Sync()
+	}
+	if err != nil {
+		log.Error().Err(err).Str("file", path).Msg("failed to back up")
+		return 1
+	}
+
+	log.Info().Str("file", path).Interface("contents", contents).Msg("backed up")
+	return 0
+}
+
+// restoreCommand runs the restore subcommand with args after "restore" and
+// returns exit code.
+func restoreCommand(args []string) int {
+	cfg, code, ok := loadCommandConfig("swimlogs restore", args, restoreUsage, nil)
+	if !ok {
+		return code
+	}
+	if len(cfg.Args) == 0 || len(cfg.Args) > 2 {
+		fmt.Fprint(os.Stderr, restoreUsage)
+		return 2
+	}
+
+	path, mode := cfg.Args[0], app.RestoreMerge
+	if len(cfg.Args) == 2 {
+		mode = app.RestoreMode(cfg.Args[1])
+	}
+	if mode != app.RestoreMerge && mode != app.RestoreReplace {
+		fmt.Fprintf(os.Stderr, "unknown mode %q\n\n%s", mode, restoreUsage)
+		return 2
+	}
+
+	setupLogger(cfg, os.Stderr)
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to open backup file")
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		log.Error().Err(err).Str("driver", cfg.Database.Driver).Msg("failed to connect to database")
+		return 1
+	}
+	defer db.Close()
+
+	if cfg.Database.SkipMigrations {
+		log.Info().Msg("skipping migrations")
+	} else if err := db.MigrateUp(true); err != nil {
+		log.Error().Err(err).Msg("failed to migrate up")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	swimlogs := app.New(db, cfg.Database.Timeout, nil)
+	contents, err := swimlogs.Restore(ctx, bufio.NewReader(in), mode)
+	if err != nil {
+		log.Error().Err(err).Str("file", path).Msg("failed to restore")
+		return 1
+	}
+
+	log.Info().
+		Str("file", path).
+		Str("mode", string(mode)).
+		Interface("contents", contents).
+		Msg("restored")
+	return 0
+}
+
+// loadCommandConfig loads config of a subcommand, commandFlags defines its
+// own flags and can be nil. When it returns false the command is done and
+// should exit with the returned code.
+func loadCommandConfig(
+	name string,
+	args []string,
+	usage string,
+	commandFlags func(fs *flag.FlagSet),
+) (config.Config, int, bool) {
+	cfg, err := config.LoadCommandFlags(name, args, os.LookupEnv, commandFlags)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return config.Config{}, 0, false
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return config.Config{}, 2, false
+	}
+
+	if cfg.PrintConfig {
+		return config.Config{}, printConfig(cfg), false
+	}
+	return cfg, 0, true
+}
@@ -1,91 +1,200 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 	_ "time/tzdata"
 
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/Nesquiko/swimlogs/migrations"
 	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/config"
 	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/metrics"
 	"github.com/Nesquiko/swimlogs/pkg/server"
+	"github.com/Nesquiko/swimlogs/pkg/tracing"
 )
 
-const (
-	AppHostEnvVar       = "APP_HOST"
-	AppPortEnvVar       = "APP_PORT"
-	AppDebugLevelEnvVar = "APP_DEBUG_LEVEL"
-
-	DbHostEnvVar = "DATABASE_HOST"
-	DbPortEnvVar = "DATABASE_PORT"
-	DbNameEnvVar = "DATABASE_NAME"
-	DbUserEnvVar = "DATABASE_USER"
-	DbPassEnvVar = "DATABASE_PASSWORD"
-
-	FEOriginEnvVar = "FE_ORIGIN"
-)
+// commands are subcommands of swimlogs, each gets args after its name and
+// returns exit code.
+var commands = map[string]func(args []string) int{
+	"migrate": migrateCommand,
+	"backup":  backupCommand,
+	"restore": restoreCommand,
+	"seed":    seedCommand,
+}
 
 func main() {
-	appHost := flag.String("host", os.Getenv(AppHostEnvVar), "application host")
-	appPort := flag.String("port", os.Getenv(AppPortEnvVar), "application port")
-	appDebugLevel := flag.Int(
-		"debug-level",
-		1,
-		"application debug level (trace = -1, debug = 0, info = 1, warn = 2, error = 3, fatal = 4, panic = 5)",
-	)
-
-	dbHost := flag.String("db-host", os.Getenv(DbHostEnvVar), "db host")
-	dbPort := flag.String("db-port", os.Getenv(DbPortEnvVar), "db port")
-	dbUser := flag.String("db-user", os.Getenv(DbUserEnvVar), "user connecting to db")
-	dbPass := flag.String("db-pass", os.Getenv(DbPassEnvVar), "password for connecting to db")
-	dbName := flag.String("db-name", os.Getenv(DbNameEnvVar), "to which db to connnect")
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
 
-	feOrigin := flag.String("fe-origin", os.Getenv(FEOriginEnvVar), "frontend origin")
-	_ = feOrigin
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(cfg.Args) > 0 {
+		fmt.Fprintf(os.Stderr, "unknown command %q, must be one of migrate, backup, restore or seed\n", cfg.Args[0])
+		os.Exit(2)
+	}
 
-	jsonLogs := flag.Bool("json-logs", false, "whether to log in json format")
+	if cfg.PrintConfig {
+		os.Exit(printConfig(cfg))
+	}
 
-	tz := flag.String("tz", os.Getenv("TZ"), "timezone in which the app is running")
-	flag.Parse()
+	setupLogger(cfg, os.Stdout)
 
-	zerolog.SetGlobalLevel(zerolog.Level(*appDebugLevel))
-	log.Logger = log.With().Caller().Logger()
-	if !*jsonLogs {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout, FormatTimestamp: func(i interface{}) string { return time.Now().Format("2006-01-02 15:04:05.000") }}).
-			With().
-			Caller().
-			Logger()
+	loc, err := time.LoadLocation(cfg.TZ)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load timezone")
 	}
+	log.Info().Str("tz", loc.String()).Msg("loaded default timezone")
 
-	loc, err := time.LoadLocation(*tz)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to load timezone")
+		log.Fatal().Err(err).Msg("failed to set up tracing")
 	}
-	log.Info().Str("tz", loc.String()).Msg("loaded timezone")
-	time.Local = loc
+	log.Info().Str("exporter", cfg.Tracing.Exporter).Msg("set up tracing")
 
-	conStr := data.ConnectionString(*dbUser, *dbPass, *dbHost, *dbName, *dbPort)
-	pool, err := data.NewPostgresPool(conStr, "migrations")
+	db, err := openDatabase(cfg)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to connect to database")
+		log.Fatal().Err(err).Str("driver", cfg.Database.Driver).Msg("failed to connect to database")
 	}
-	defer pool.Close()
 
-	if err := pool.MigrateUp(true); err != nil {
+	if cfg.Database.SkipMigrations {
+		log.Info().Msg("skipping migrations")
+	} else if err := db.MigrateUp(true); err != nil {
 		log.Fatal().Err(err).Msg("failed to migrate up")
 	}
 
-	swimlogs := app.New(pool)
-	h := server.NewServerHandler(swimlogs, *feOrigin)
+	switch db := db.(type) {
+	case *data.PostgresDbPool:
+		metrics.Registry.MustRegister(metrics.NewPgxPoolCollector(db.Pool))
+	case *data.SqliteDb:
+		metrics.Registry.MustRegister(collectors.NewDBStatsCollector(db.DB, "sqlite"))
+	}
+
+	swimlogs := app.New(db, cfg.Database.Timeout, loc)
+	shuttingDown := &atomic.Bool{}
+	h := server.NewServerHandler(
+		swimlogs,
+		cfg.Cors.ServerCors(),
+		cfg.Limits.ServerLimits(),
+		cfg.Admin.Token,
+		shuttingDown,
+	)
+
+	addr := cfg.Server.Host + ":" + cfg.Server.Port
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	addr := *appHost + ":" + *appPort
-	log.Info().Str("addr", addr).Msg("starting server")
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info().Str("addr", addr).Msg("starting server")
+		serverErr <- srv.ListenAndServe()
+	}()
 
-	if err := http.ListenAndServe(addr, h); err != nil {
+	select {
+	case err := <-serverErr:
 		log.Fatal().Err(err).Msg("handler failed")
+	case <-ctx.Done():
+		stop()
+	}
+
+	log.Info().
+		Dur("drain_delay", cfg.Server.DrainDelay).
+		Dur("timeout", cfg.Server.ShutdownTimeout).
+		Msg("shutting down server")
+
+	// second signal skips the rest of the drain delay
+	drainCtx, stopDrain := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopDrain()
+	err = server.GracefulShutdown(
+		drainCtx,
+		srv,
+		shuttingDown,
+		cfg.Server.DrainDelay,
+		cfg.Server.ShutdownTimeout,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to gracefully shut down server")
+	}
+	if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+		log.Error().Err(err).Msg("handler failed")
+	}
+
+	db.Close()
+	tracingCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		log.Error().Err(err).Msg("failed to flush traces")
+	}
+	log.Info().Msg("server stopped")
+}
+
+func printConfig(cfg config.Config) int {
+	b, err := cfg.Redacted().Yaml()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	os.Stdout.Write(b)
+	return 0
+}
+
+// setupLogger logs to out, unless logs are JSON which always go to stderr.
+func setupLogger(cfg config.Config, out io.Writer) {
+	zerolog.SetGlobalLevel(zerolog.Level(cfg.Log.Level))
+	log.Logger = log.With().Caller().Logger()
+	if !cfg.Log.JSON {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: out, FormatTimestamp: func(i interface{}) string { return time.Now().Format("2006-01-02 15:04:05.000") }}).
+			With().
+			Caller().
+			Logger()
+	}
+}
+
+func openDatabase(cfg config.Config) (data.Database, error) {
+	migrationsFS := migrations.Postgres()
+	if cfg.Database.Driver == config.DriverSqlite {
+		migrationsFS = migrations.Sqlite()
+	}
+	if cfg.Database.MigrationsDir != "" {
+		migrationsFS = os.DirFS(cfg.Database.MigrationsDir)
+	}
+
+	switch cfg.Database.Driver {
+	case config.DriverPostgres:
+		return data.NewPostgresPool(cfg.Database.ConnectionString(), migrationsFS)
+	case config.DriverSqlite:
+		return data.NewSqliteDb(cfg.Database.Path, migrationsFS)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Database.Driver)
 	}
 }
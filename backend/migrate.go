@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/pkg/config"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+const migrateUsage = `Usage: swimlogs migrate [flags] command
+
+Commands:
+  up         apply all pending migrations
+  down N     revert last N applied migrations
+  goto V     apply or revert migrations until schema is at version V
+  version    print schema version
+  force V    set schema version to V without running migrations and clear
+             the dirty flag, -1 means no migration is applied
+  status     print schema version and pending migrations
+
+Flags are the same as the server's, run swimlogs -h to list them.
+`
+
+var errUsage = errors.New("invalid usage")
+
+// migrateCommand runs the migrate subcommand with args after "migrate" and
+// returns exit code.
+func migrateCommand(args []string) int {
+	cfg, err := config.LoadCommand("swimlogs migrate", args, os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 0
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if cfg.PrintConfig {
+		return printConfig(cfg)
+	}
+	if len(cfg.Args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	setupLogger(cfg, os.Stdout)
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		log.Error().Err(err).Str("driver", cfg.Database.Driver).Msg("failed to connect to database")
+		return 1
+	}
+	defer db.Close()
+
+	err = migrate(context.Background(), db, cfg.Args[0], cfg.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "%s\n\n%s", err, migrateUsage)
+		return 2
+	} else if err != nil {
+		log.Error().Err(err).Str("command", cfg.Args[0]).Msg("failed to migrate")
+		return 1
+	}
+	return 0
+}
+
+func migrate(ctx context.Context, db data.Database, command string, args []string, out io.Writer) error {
+	wantArgs := 0
+	switch command {
+	case "up", "version", "status":
+	case "down", "goto", "force":
+		wantArgs = 1
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+	if len(args) != wantArgs {
+		return fmt.Errorf("%w: %s takes %d argument(s), got %d", errUsage, command, wantArgs, len(args))
+	}
+
+	switch command {
+	case "up":
+		return db.MigrateUp(true)
+	case "down":
+		steps, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil || steps == 0 {
+			return fmt.Errorf("%w: down takes positive number of migrations, got %q", errUsage, args[0])
+		}
+		return db.MigrateDown(uint(steps), true)
+	case "goto":
+		version, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("%w: goto takes a version, got %q", errUsage, args[0])
+		}
+		return db.MigrateTo(uint(version), true)
+	case "force":
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return fmt.Errorf("%w: force takes a version or -1, got %q", errUsage, args[0])
+		}
+		if err := db.ForceMigration(version); err != nil {
+			return err
+		}
+		log.Info().Int("version", version).Msg("forced schema version")
+		return nil
+	case "version":
+		status, err := db.Migrations(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Dirty {
+			_, err = fmt.Fprintf(out, "%d (dirty)\n", status.Version)
+		} else {
+			_, err = fmt.Fprintf(out, "%d\n", status.Version)
+		}
+		return err
+	case "status":
+		status, err := db.Migrations(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(out, status)
+	}
+	return nil
+}
+
+func printMigrationStatus(out io.Writer, status data.MigrationStatus) error {
+	pending := "none"
+	if versions := status.Pending(); len(versions) > 0 {
+		strs := make([]string, len(versions))
+		for i, v := range versions {
+			strs[i] = strconv.FormatUint(uint64(v), 10)
+		}
+		pending = strings.Join(strs, ", ")
+	}
+
+	_, err := fmt.Fprintf(
+		out,
+		"version: %d\ndirty:   %t\nlatest:  %d\npending: %s\n",
+		status.Version,
+		status.Dirty,
+		status.Latest,
+		pending,
+	)
+	return err
+}
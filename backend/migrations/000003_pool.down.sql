@@ -0,0 +1,4 @@
+alter table trainings drop constraint if exists trainings_pool_length_check;
+alter table trainings drop column if exists pool_unit;
+alter table trainings drop column if exists pool_length;
+drop type if exists pool_unit;
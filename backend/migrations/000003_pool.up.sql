@@ -0,0 +1,5 @@
+create type pool_unit as enum ('meters', 'yards');
+
+alter table trainings add column if not exists pool_length smallint not null default 25;
+alter table trainings add column if not exists pool_unit pool_unit not null default 'meters';
+alter table trainings add constraint trainings_pool_length_check check (pool_length > 0);
@@ -0,0 +1,23 @@
+create table if not exists users
+(
+    id          uuid primary key default gen_random_uuid(),
+
+    name        text                     not null,
+
+    created_at  timestamp with time zone not null,
+    modified_at timestamp with time zone not null
+);
+
+create table if not exists set_results
+(
+    set_id      uuid references sets on delete cascade  not null,
+    user_id     uuid references users on delete cascade not null,
+
+    times_ms    integer[]                               not null,
+
+    created_at  timestamp with time zone                not null,
+    modified_at timestamp with time zone                not null,
+
+    primary key (set_id, user_id),
+    constraint set_results_times_check check (cardinality(times_ms) > 0)
+);
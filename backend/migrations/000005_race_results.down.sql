@@ -0,0 +1,2 @@
+drop table if exists race_results;
+drop type if exists stroke;
@@ -0,0 +1,25 @@
+create type stroke as enum ('freestyle', 'backstroke', 'breaststroke', 'butterfly', 'medley', 'surface', 'bifins', 'apnea');
+
+create table if not exists race_results
+(
+    id               uuid primary key default gen_random_uuid(),
+    user_id          uuid references users on delete cascade      not null,
+    training_id      uuid references trainings on delete set null,
+
+    distance         smallint                                     not null,
+    stroke           stroke                                       not null,
+    pool_length      smallint                                     not null,
+    pool_unit        pool_unit                                    not null,
+    time_ms          integer                                      not null,
+    date             date                                         not null,
+    is_personal_best boolean                                      not null,
+
+    created_at       timestamp with time zone                     not null,
+    modified_at      timestamp with time zone                     not null,
+
+    constraint race_results_distance_check check (distance > 0),
+    constraint race_results_pool_length_check check (pool_length > 0),
+    constraint race_results_time_check check (time_ms > 0)
+);
+
+create index if not exists race_results_user_id_idx on race_results (user_id);
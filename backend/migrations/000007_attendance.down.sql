@@ -0,0 +1,2 @@
+drop table if exists attendance;
+drop type if exists attendance_status;
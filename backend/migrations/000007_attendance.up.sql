@@ -0,0 +1,19 @@
+create type attendance_status as enum ('present', 'absent', 'excused', 'partial');
+
+create table if not exists attendance
+(
+    training_id      uuid references trainings on delete cascade not null,
+    user_id          uuid references users on delete cascade     not null,
+
+    status           attendance_status                           not null,
+    meters_completed integer,
+
+    created_at       timestamp with time zone                    not null,
+    modified_at      timestamp with time zone                    not null,
+
+    primary key (training_id, user_id),
+    constraint attendance_partial_check check ((status = 'partial') = (meters_completed is not null)),
+    constraint attendance_meters_check check (meters_completed is null or meters_completed > 0)
+);
+
+create index if not exists attendance_user_id_idx on attendance (user_id);
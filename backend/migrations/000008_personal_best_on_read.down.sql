@@ -0,0 +1,12 @@
+alter table race_results add column if not exists is_personal_best boolean not null default false;
+
+update race_results r
+set is_personal_best = d.is_personal_best
+from (select id,
+             coalesce(time_ms < min(time_ms) over (
+                 partition by user_id, distance, stroke, pool_length, pool_unit
+                 order by date, created_at, id
+                 rows between unbounded preceding and 1 preceding
+             ), true) as is_personal_best
+      from race_results) d
+where r.id = d.id;
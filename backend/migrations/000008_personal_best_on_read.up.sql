@@ -0,0 +1,3 @@
+-- personal bests are derived on read, a stored flag goes stale when results
+-- are back-dated or inserted concurrently
+alter table race_results drop column if exists is_personal_best;
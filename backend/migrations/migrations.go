@@ -0,0 +1,28 @@
+// Package migrations bundles database migrations into the binary, so the
+// server doesn't depend on its working directory.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var postgres embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+// Postgres are migrations of the postgres database.
+func Postgres() fs.FS {
+	return postgres
+}
+
+// Sqlite are migrations of the sqlite database.
+func Sqlite() fs.FS {
+	sub, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
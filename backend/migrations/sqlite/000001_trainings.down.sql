@@ -0,0 +1,2 @@
+drop table if exists sets;
+drop table if exists trainings;
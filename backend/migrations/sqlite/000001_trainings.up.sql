@@ -0,0 +1,39 @@
+-- sqlite has no enums and arrays, enums are emulated with check constraints
+-- and arrays are stored as json
+create table if not exists trainings
+(
+    id             text primary key,
+
+    start          datetime not null,
+    duration_min   integer  not null,
+    total_distance integer  not null,
+
+    created_at     datetime not null,
+    modified_at    datetime not null,
+
+    constraint trainings_duration_check check (duration_min > 0),
+    constraint trainings_total_distance_check check (total_distance > 0)
+);
+
+create table if not exists sets
+(
+    id              text primary key,
+    training_id     text references trainings on delete cascade not null,
+
+    set_order       integer                                      not null,
+    repeat          integer                                      not null,
+    distance_meters integer                                      not null,
+    description     text,
+    start_type      text                                         not null,
+    start_seconds   integer,
+    total_distance  integer                                      not null,
+    equipment       text,
+
+    constraint sets_start_type_check check (start_type in ('None', 'Interval', 'Pause')),
+    constraint sets_equipment_check check (equipment is null or json_type(equipment) = 'array'),
+    constraint sets_repeat_check check (repeat > 0),
+    constraint sets_distance_check check (distance_meters > 0),
+    constraint sets_rule_check check (start_type = 'None' or (start_seconds is not null and start_seconds > 0))
+);
+
+create index if not exists sets_training_id_idx on sets (training_id);
@@ -0,0 +1 @@
+alter table sets drop column "group";
@@ -0,0 +1,2 @@
+alter table sets add column "group" text
+    constraint sets_group_check check ("group" in ('bifi', 'long', 'middle', 'mono', 'sprint'));
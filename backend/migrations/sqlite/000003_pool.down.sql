@@ -0,0 +1,2 @@
+alter table trainings drop column pool_unit;
+alter table trainings drop column pool_length;
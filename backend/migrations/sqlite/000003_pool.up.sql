@@ -0,0 +1,4 @@
+alter table trainings add column pool_length integer not null default 25
+    constraint trainings_pool_length_check check (pool_length > 0);
+alter table trainings add column pool_unit text not null default 'meters'
+    constraint trainings_pool_unit_check check (pool_unit in ('meters', 'yards'));
@@ -0,0 +1,2 @@
+drop table if exists set_results;
+drop table if exists users;
@@ -0,0 +1,25 @@
+create table if not exists users
+(
+    id          text primary key,
+
+    name        text     not null,
+
+    created_at  datetime not null,
+    modified_at datetime not null
+);
+
+create table if not exists set_results
+(
+    set_id      text references sets on delete cascade  not null,
+    user_id     text references users on delete cascade not null,
+
+    times_ms    text                                    not null,
+
+    created_at  datetime                                not null,
+    modified_at datetime                                not null,
+
+    primary key (set_id, user_id),
+    constraint set_results_times_check check (json_array_length(times_ms) > 0)
+);
+
+create index if not exists set_results_user_id_idx on set_results (user_id);
@@ -0,0 +1,27 @@
+create table if not exists race_results
+(
+    id               text primary key,
+    user_id          text references users on delete cascade      not null,
+    training_id      text references trainings on delete set null,
+
+    distance         integer                                      not null,
+    stroke           text                                         not null,
+    pool_length      integer                                      not null,
+    pool_unit        text                                         not null,
+    time_ms          integer                                      not null,
+    date             date                                         not null,
+    is_personal_best boolean                                      not null,
+
+    created_at       datetime                                     not null,
+    modified_at      datetime                                     not null,
+
+    constraint race_results_stroke_check check (stroke in ('freestyle', 'backstroke', 'breaststroke',
+        'butterfly', 'medley', 'surface', 'bifins', 'apnea')),
+    constraint race_results_pool_unit_check check (pool_unit in ('meters', 'yards')),
+    constraint race_results_distance_check check (distance > 0),
+    constraint race_results_pool_length_check check (pool_length > 0),
+    constraint race_results_time_check check (time_ms > 0)
+);
+
+create index if not exists race_results_user_id_idx on race_results (user_id);
+create index if not exists race_results_training_id_idx on race_results (training_id);
@@ -0,0 +1,17 @@
+create table if not exists css_tests
+(
+    id          text primary key,
+    user_id     text references users on delete cascade not null,
+
+    time_400_ms integer                                 not null,
+    time_200_ms integer                                 not null,
+    date        date                                    not null,
+
+    created_at  datetime                                not null,
+    modified_at datetime                                not null,
+
+    constraint css_tests_time_200_check check (time_200_ms > 0),
+    constraint css_tests_time_400_check check (time_400_ms > time_200_ms)
+);
+
+create index if not exists css_tests_user_id_idx on css_tests (user_id);
@@ -0,0 +1,18 @@
+create table if not exists attendance
+(
+    training_id      text references trainings on delete cascade not null,
+    user_id          text references users on delete cascade     not null,
+
+    status           text                                        not null,
+    meters_completed integer,
+
+    created_at       datetime                                    not null,
+    modified_at      datetime                                    not null,
+
+    primary key (training_id, user_id),
+    constraint attendance_status_check check (status in ('present', 'absent', 'excused', 'partial')),
+    constraint attendance_partial_check check ((status = 'partial') = (meters_completed is not null)),
+    constraint attendance_meters_check check (meters_completed is null or meters_completed > 0)
+);
+
+create index if not exists attendance_user_id_idx on attendance (user_id);
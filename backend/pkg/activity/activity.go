@@ -0,0 +1,66 @@
+// Package activity reads completed pool swims from activity files of
+// swimming watches, FIT or TCX, as intervals of swum lengths separated by
+// rests.
+package activity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/fit"
+)
+
+var ErrInvalid = errors.New("invalid activity")
+
+// Error is why an activity file can't be read.
+type Error struct {
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return "invalid activity: " + e.Reason
+}
+
+func (e *Error) Unwrap() error {
+	return ErrInvalid
+}
+
+func invalidf(format string, args ...any) error {
+	return &Error{Reason: fmt.Sprintf(format, args...)}
+}
+
+const metersPerYard = 0.9144
+
+type Activity struct {
+	Start time.Time
+	// Elapsed is the whole time of the activity, with rests
+	Elapsed time.Duration
+	Pool    apidef.Pool
+	// Intervals in order they were swum, never empty
+	Intervals []Interval
+}
+
+// Interval is lengths swum without a rest.
+type Interval struct {
+	Lengths int
+	// Stroke is the swim stroke name, empty if unknown
+	Stroke string
+	Swim   time.Duration
+	// Rest after the interval
+	Rest time.Duration
+}
+
+// Parse reads a FIT or TCX activity, told apart by content. Pool is the
+// pool of the activity if the file doesn't say, TCX files never do.
+func Parse(file []byte, pool apidef.Pool) (Activity, error) {
+	if fit.IsFIT(file) {
+		return parseFIT(file, pool)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(file, []byte("\xEF\xBB\xBF"))), []byte("<")) {
+		return parseTCX(file, pool)
+	}
+	return Activity{}, invalidf("neither FIT nor TCX file")
+}
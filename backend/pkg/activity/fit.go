@@ -0,0 +1,124 @@
+package activity
+
+import (
+	"math"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/fit"
+)
+
+const (
+	mesgSession uint16 = 18
+	mesgLength  uint16 = 101
+
+	fileActivity        = 4
+	sportSwimming       = 5
+	subSportLapSwimming = 17
+	lengthIdle          = 0
+	displayStatute      = 1
+
+	strokeMixed = "mixed"
+)
+
+// names of swim_stroke values
+var strokes = map[uint64]string{
+	0: "freestyle",
+	1: "backstroke",
+	2: "breaststroke",
+	3: "butterfly",
+	4: "drill",
+	5: strokeMixed,
+	6: "IM",
+}
+
+// parseFIT reads lengths of a FIT activity, active lengths one after
+// another are an interval and idle lengths are rests.
+func parseFIT(file []byte, pool apidef.Pool) (Activity, error) {
+	messages, err := fit.Decode(file)
+	if err != nil {
+		return Activity{}, invalidf("%v", err)
+	}
+
+	a := Activity{Pool: pool}
+	isActivity, hasSession, resting := false, false, true
+	var swum time.Duration
+	for _, m := range messages {
+		switch m.Num {
+		case fit.MesgFileId:
+			t, _ := m.Uint(0)
+			isActivity = t == fileActivity
+		case mesgSession:
+			if hasSession {
+				continue
+			}
+			hasSession = true
+			if err := readSession(m, &a); err != nil {
+				return Activity{}, err
+			}
+		case mesgLength:
+			elapsed, _ := m.Duration(3)
+			swum += elapsed
+			if t, ok := m.Uint(12); ok && t == lengthIdle {
+				if n := len(a.Intervals); n > 0 {
+					a.Intervals[n-1].Rest += elapsed
+				}
+				resting = true
+				continue
+			}
+
+			stroke := ""
+			if s, ok := m.Uint(7); ok {
+				stroke = strokes[s]
+			}
+			if resting {
+				a.Intervals = append(a.Intervals, Interval{Stroke: stroke})
+				resting = false
+			}
+			last := &a.Intervals[len(a.Intervals)-1]
+			if last.Lengths > 0 && last.Stroke != stroke {
+				last.Stroke = strokeMixed
+			}
+			last.Lengths++
+			last.Swim += elapsed
+		}
+	}
+
+	if !isActivity {
+		return Activity{}, invalidf("FIT file isn't an activity")
+	} else if !hasSession {
+		return Activity{}, invalidf("FIT activity has no session")
+	} else if len(a.Intervals) == 0 {
+		return Activity{}, invalidf("FIT activity has no swum lengths")
+	}
+	if a.Elapsed == 0 {
+		a.Elapsed = swum
+	}
+	return a, nil
+}
+
+func readSession(m fit.Message, a *Activity) error {
+	if sport, ok := m.Uint(5); ok && sport != sportSwimming {
+		return invalidf("sport %d isn't swimming", sport)
+	}
+	if subSport, ok := m.Uint(6); ok && subSport != subSportLapSwimming {
+		return invalidf("sub sport %d isn't pool swimming", subSport)
+	}
+
+	start, ok := m.Time(2)
+	if !ok {
+		return invalidf("session has no start")
+	}
+	a.Start = start
+	a.Elapsed, _ = m.Duration(7)
+
+	// pool_length is always in meters, scaled by 100
+	if length, ok := m.Uint(44); ok && length > 0 {
+		a.Pool = apidef.Pool{Length: int(math.Round(float64(length) / 100)), Unit: apidef.Meters}
+		if unit, _ := m.Uint(46); unit == displayStatute {
+			yards := math.Round(float64(length) / 100 / metersPerYard)
+			a.Pool = apidef.Pool{Length: int(yards), Unit: apidef.Yards}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+type tcxDatabase struct {
+	Activities []struct {
+		Laps []tcxLap `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxLap struct {
+	StartTime        time.Time `xml:"StartTime,attr"`
+	TotalTimeSeconds float64
+	DistanceMeters   float64
+}
+
+func (l tcxLap) duration() time.Duration {
+	return time.Duration(l.TotalTimeSeconds * float64(time.Second))
+}
+
+// parseTCX reads laps of the first activity in a TCX file. TCX has no
+// lengths, so each lap with a distance is one interval and laps without
+// one, or gaps between laps, are rests.
+func parseTCX(file []byte, pool apidef.Pool) (Activity, error) {
+	var db tcxDatabase
+	if err := xml.NewDecoder(bytes.NewReader(file)).Decode(&db); err != nil {
+		return Activity{}, invalidf("%v", err)
+	}
+	if len(db.Activities) == 0 || len(db.Activities[0].Laps) == 0 {
+		return Activity{}, invalidf("TCX file has no laps")
+	}
+	laps := db.Activities[0].Laps
+
+	poolMeters := float64(pool.Length)
+	if pool.Unit == apidef.Yards {
+		poolMeters *= metersPerYard
+	}
+
+	first, last := laps[0], laps[len(laps)-1]
+	a := Activity{
+		Start:   first.StartTime,
+		Elapsed: last.StartTime.Add(last.duration()).Sub(first.StartTime),
+		Pool:    pool,
+	}
+	for i, lap := range laps {
+		if lap.StartTime.IsZero() {
+			return Activity{}, invalidf("TCX lap %d has no start", i+1)
+		}
+
+		lengths := int(math.Round(lap.DistanceMeters / poolMeters))
+		if lengths > 0 {
+			a.Intervals = append(a.Intervals, Interval{Lengths: lengths, Swim: lap.duration()})
+		} else if n := len(a.Intervals); n > 0 {
+			a.Intervals[n-1].Rest += lap.duration()
+		}
+
+		if n := len(a.Intervals); n > 0 && i+1 < len(laps) {
+			gap := laps[i+1].StartTime.Sub(lap.StartTime.Add(lap.duration()))
+			a.Intervals[n-1].Rest += max(gap.Round(time.Second), 0)
+		}
+	}
+
+	if len(a.Intervals) == 0 {
+		return Activity{}, invalidf("TCX activity has no swum laps")
+	}
+	return a, nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/activity"
+)
+
+var ErrInvalidActivity = activity.ErrInvalid
+
+// ImportActivity creates a training from a swim recorded in a FIT or TCX
+// activity file. Pool is the pool of the swim when the file doesn't say,
+// the default pool if nil.
+func (app SwimLogsApp) ImportActivity(
+	ctx context.Context,
+	file []byte,
+	pool *apidef.Pool,
+) (apidef.TrainingDetail, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.ImportActivity")
+	defer span.End()
+
+	p := poolOrDefault(pool)
+	if err := validatePool(p); err != nil {
+		return apidef.TrainingDetail{}, fmt.Errorf("ImportActivity: %w", err)
+	}
+
+	a, err := activity.Parse(file, p)
+	if err != nil {
+		return apidef.TrainingDetail{}, fmt.Errorf("ImportActivity: %w", err)
+	}
+	if err := validatePool(a.Pool); err != nil {
+		return apidef.TrainingDetail{}, fmt.Errorf("ImportActivity: %w", err)
+	}
+
+	nt := apidef.NewTraining{
+		Start:       a.Start,
+		DurationMin: max(int(math.Round(a.Elapsed.Minutes())), 1),
+		Pool:        &a.Pool,
+		Sets:        activitySets(a),
+	}
+	td, err := app.CreateTraining(ctx, nt)
+	if err != nil {
+		return apidef.TrainingDetail{}, fmt.Errorf("ImportActivity: %w", err)
+	}
+	return td, nil
+}
+
+// activitySets makes a set of intervals of the same lengths and stroke one
+// after another. Median of rests between them is a Pause start, rest after
+// the last interval of the activity is only cooling down and is ignored.
+func activitySets(a activity.Activity) []apidef.NewTrainingSet {
+	sets := make([]apidef.NewTrainingSet, 0)
+	for i := 0; i < len(a.Intervals); {
+		first := a.Intervals[i]
+		end := i + 1
+		for end < len(a.Intervals) &&
+			a.Intervals[end].Lengths == first.Lengths &&
+			a.Intervals[end].Stroke == first.Stroke {
+			end++
+		}
+		intervals := a.Intervals[i:end]
+
+		distance := first.Lengths * a.Pool.Length
+		set := apidef.NewTrainingSet{
+			SetOrder:  len(sets),
+			Repeat:    len(intervals),
+			StartType: apidef.None,
+		}
+		if a.Pool.Unit == apidef.Yards {
+			set.DistanceYards = &distance
+		} else {
+			set.DistanceMeters = distance
+		}
+		if first.Stroke != "" {
+			stroke := first.Stroke
+			set.Description = &stroke
+		}
+
+		rested := intervals
+		if len(intervals) > 1 || end == len(a.Intervals) {
+			rested = intervals[:len(intervals)-1]
+		}
+		if rest := medianRest(rested); rest > 0 {
+			set.StartType = apidef.Pause
+			set.StartSeconds = &rest
+		}
+
+		sets = append(sets, set)
+		i = end
+	}
+	return sets
+}
+
+// medianRest returns median of rests after intervals in whole seconds, 0
+// if there are none.
+func medianRest(intervals []activity.Interval) int {
+	if len(intervals) == 0 {
+		return 0
+	}
+	rests := make([]time.Duration, 0, len(intervals))
+	for _, i := range intervals {
+		rests = append(rests, i.Rest)
+	}
+	slices.Sort(rests)
+	return int(rests[len(rests)/2].Round(time.Second) / time.Second)
+}
@@ -1,55 +1,102 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 
 	"github.com/Nesquiko/swimlogs/apidef"
 	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/metrics"
 )
 
 var ErrNotFound = errors.New("resource not found")
 
-func New(pool *data.PostgresDbPool) SwimLogsApp {
-	return SwimLogsApp{pool}
+var tracer = otel.Tracer("github.com/Nesquiko/swimlogs/pkg/app")
+
+// New creates the app, loc is the timezone of day and week boundaries when
+// a call doesn't specify one, time.Local if nil.
+func New(repo data.Repository, dbTimeout time.Duration, loc *time.Location) SwimLogsApp {
+	if loc == nil {
+		loc = time.Local
+	}
+	return SwimLogsApp{repo, dbTimeout, loc}
 }
 
 type SwimLogsApp struct {
-	pool *data.PostgresDbPool
+	repo data.Repository
+
+	// dbTimeout limits how long can all database queries of one app call
+	// take, zero means no limit
+	dbTimeout time.Duration
+
+	loc *time.Location
+}
+
+func (app SwimLogsApp) dbContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if app.dbTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, app.dbTimeout)
 }
 
 func (app SwimLogsApp) CreateTraining(
+	ctx context.Context,
 	newTraining apidef.NewTraining,
 ) (apidef.TrainingDetail, error) {
-	recalcDistanceOnNewTraining(&newTraining)
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.CreateTraining")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	if err := recalcDistanceOnNewTraining(&newTraining); err != nil {
+		return apidef.TrainingDetail{}, fmt.Errorf("CreateTraining: %w", err)
+	}
 	t := newTrainingToDataTraining(newTraining)
 
 	t.Start = t.Start.Truncate(time.Minute)
-	t, err := app.pool.PersistTraining(t)
+	t, err := app.repo.PersistTraining(ctx, t)
 	if err != nil {
 		return apidef.TrainingDetail{}, fmt.Errorf("CreateTraining: %w", err)
 	}
+	metrics.TrainingsCreated.Inc()
 
 	return trainingToDetail(t), nil
 }
 
-func (app SwimLogsApp) DeleteTraining(id uuid.UUID) error {
-	err := app.pool.DeleteTraining(id)
+func (app SwimLogsApp) DeleteTraining(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.DeleteTraining")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	err := app.repo.DeleteTraining(ctx, id)
 	if errors.Is(err, data.ErrRowsNotFound) {
 		return fmt.Errorf("DeleteTraining: %w", ErrNotFound)
 	} else if err != nil {
 		return fmt.Errorf("DeleteTraining: %w", err)
 	}
+	metrics.TrainingsDeleted.Inc()
 	return nil
 }
 
 func (app SwimLogsApp) TrainingDetailsPage(
+	ctx context.Context,
 	page, pageSize int,
 ) ([]apidef.TrainingDetail, int, error) {
-	detailsPage, total, err := app.pool.TrainingDetails(page, pageSize)
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.TrainingDetailsPage")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	detailsPage, total, err := app.repo.TrainingDetails(ctx, page, pageSize)
 	if err != nil {
 		return nil, 0, fmt.Errorf("TrainingDetailsPage: %w", err)
 	}
@@ -62,12 +109,25 @@ func (app SwimLogsApp) TrainingDetailsPage(
 	return details, total, nil
 }
 
-func (app SwimLogsApp) TrainingDetailsCurrentWeek() ([]apidef.TrainingDetail, error) {
-	now := time.Now()
-	startOfWeek := now.AddDate(0, 0, -(int(now.Weekday())+6)%7)
-	endOfWeek := now.AddDate(0, 0, (7-int(now.Weekday()))%7)
+// TrainingDetailsCurrentWeek returns trainings from Monday to Sunday of the
+// current week in timezone tz, or in the default timezone if tz is nil.
+func (app SwimLogsApp) TrainingDetailsCurrentWeek(
+	ctx context.Context,
+	tz *string,
+) ([]apidef.TrainingDetail, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.TrainingDetailsCurrentWeek")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	loc, err := app.location(tz)
+	if err != nil {
+		return nil, fmt.Errorf("TrainingDetailsCurrentWeek: %w", err)
+	}
+	startOfWeek, endOfWeek := weekRange(time.Now(), loc)
 
-	detailsInRange, err := app.pool.TrainingDetailsInRange(startOfWeek, endOfWeek)
+	detailsInRange, err := app.repo.TrainingDetailsInRange(ctx, startOfWeek, endOfWeek)
 	if err != nil {
 		return nil, fmt.Errorf("TrainingDetailsCurrentWeek: %w", err)
 	}
@@ -79,8 +139,14 @@ func (app SwimLogsApp) TrainingDetailsCurrentWeek() ([]apidef.TrainingDetail, er
 	return details, nil
 }
 
-func (app SwimLogsApp) Training(id uuid.UUID) (apidef.Training, error) {
-	t, err := app.pool.Training(id)
+func (app SwimLogsApp) Training(ctx context.Context, id uuid.UUID) (apidef.Training, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.Training")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	t, err := app.repo.Training(ctx, id)
 	if errors.Is(err, data.ErrRowsNotFound) {
 		return apidef.Training{}, fmt.Errorf("Training: %w", ErrNotFound)
 	} else if err != nil {
@@ -91,18 +157,40 @@ func (app SwimLogsApp) Training(id uuid.UUID) (apidef.Training, error) {
 }
 
 func (app SwimLogsApp) EditTraining(
+	ctx context.Context,
 	id uuid.UUID,
 	t apidef.Training,
 ) (apidef.TrainingDetail, error) {
-	recalcDistanceOnTraining(&t)
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.EditTraining")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	if t.Pool == nil {
+		stored, err := app.repo.Training(ctx, id)
+		if errors.Is(err, data.ErrRowsNotFound) {
+			return apidef.TrainingDetail{}, fmt.Errorf("EditTraining: %w", ErrNotFound)
+		} else if err != nil {
+			return apidef.TrainingDetail{}, fmt.Errorf("EditTraining: %w", err)
+		}
+		// edits without a pool keep the pool of the training
+		pool := dataPool(stored)
+		t.Pool = &pool
+	}
+
+	if err := recalcDistanceOnTraining(&t); err != nil {
+		return apidef.TrainingDetail{}, fmt.Errorf("EditTraining: %w", err)
+	}
 	training := trainingToDataTraining(t)
 
-	edited, err := app.pool.EditTraining(id, training)
+	edited, err := app.repo.EditTraining(ctx, id, training)
 	if errors.Is(err, data.ErrRowsNotFound) {
 		return apidef.TrainingDetail{}, fmt.Errorf("EditTraining: %w", ErrNotFound)
 	} else if err != nil {
 		return apidef.TrainingDetail{}, fmt.Errorf("EditTraining: %w", err)
 	}
+	metrics.TrainingsEdited.Inc()
 
 	return trainingToDetail(edited), nil
 }
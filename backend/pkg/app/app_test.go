@@ -0,0 +1,29 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+func newApp() app.SwimLogsApp {
+	return app.New(data.NewMemoryRepository(), 0, time.UTC)
+}
+
+func createTraining(t *testing.T, a app.SwimLogsApp, nt apidef.NewTraining) apidef.Training {
+	td, err := a.CreateTraining(context.Background(), nt)
+	require.NoError(t, err)
+	training, err := a.Training(context.Background(), td.Id)
+	require.NoError(t, err)
+	return training
+}
+
+func asPtr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var (
+	ErrInvalidAttendance = errors.New("invalid attendance")
+	ErrInvalidDateRange  = errors.New("invalid date range")
+)
+
+func (app SwimLogsApp) MarkAttendance(
+	ctx context.Context,
+	trainingId uuid.UUID,
+	attendance []apidef.Attendance,
+) ([]apidef.Attendance, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.MarkAttendance")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	t, err := app.repo.Training(ctx, trainingId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return nil, fmt.Errorf("MarkAttendance training: %w", ErrNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("MarkAttendance training: %w", err)
+	}
+
+	users, err := app.repo.Users(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("MarkAttendance users: %w", err)
+	}
+	userIds := make(map[uuid.UUID]bool, len(users))
+	for _, u := range users {
+		userIds[u.Id] = true
+	}
+
+	marked := make(map[uuid.UUID]bool, len(attendance))
+	for _, a := range attendance {
+		if !userIds[a.UserId] {
+			return nil, fmt.Errorf("MarkAttendance user %s: %w", a.UserId, ErrNotFound)
+		}
+		if marked[a.UserId] {
+			return nil, fmt.Errorf(
+				"MarkAttendance: %w: user %s is marked more than once",
+				ErrInvalidAttendance,
+				a.UserId,
+			)
+		}
+		marked[a.UserId] = true
+
+		if err := validateAttendance(a, t.TotalDistance); err != nil {
+			return nil, fmt.Errorf("MarkAttendance user %s: %w", a.UserId, err)
+		}
+	}
+
+	err = app.repo.UpsertAttendance(ctx, attendanceToDataAttendance(attendance, trainingId))
+	if err != nil {
+		return nil, fmt.Errorf("MarkAttendance: %w", err)
+	}
+
+	return app.attendance(ctx, trainingId)
+}
+
+func validateAttendance(a apidef.Attendance, trainingDistance int) error {
+	if a.Status != apidef.Partial {
+		if a.MetersCompleted != nil {
+			return fmt.Errorf(
+				"%w: meters completed can be set only on partial attendance",
+				ErrInvalidAttendance,
+			)
+		}
+		return nil
+	}
+
+	if a.MetersCompleted == nil ||
+		*a.MetersCompleted <= 0 ||
+		*a.MetersCompleted > trainingDistance {
+		return fmt.Errorf(
+			"%w: partial attendance must have meters completed between 1 and %d",
+			ErrInvalidAttendance,
+			trainingDistance,
+		)
+	}
+	return nil
+}
+
+func (app SwimLogsApp) Attendance(ctx context.Context, trainingId uuid.UUID) ([]apidef.Attendance, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.Attendance")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	_, err := app.repo.Training(ctx, trainingId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return nil, fmt.Errorf("Attendance training: %w", ErrNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("Attendance training: %w", err)
+	}
+
+	return app.attendance(ctx, trainingId)
+}
+
+func (app SwimLogsApp) attendance(ctx context.Context, trainingId uuid.UUID) ([]apidef.Attendance, error) {
+	dataAttendance, err := app.repo.Attendance(ctx, trainingId)
+	if err != nil {
+		return nil, fmt.Errorf("attendance: %w", err)
+	}
+
+	attendance := make([]apidef.Attendance, len(dataAttendance))
+	for i, a := range dataAttendance {
+		attendance[i] = dataAttendanceToApiAttendance(a)
+	}
+	return attendance, nil
+}
+
+// AttendanceReport returns how many trainings took place between from and
+// to, both inclusive days in timezone tz, or in the default timezone if tz
+// is nil, and attendance summary of every user on them.
+func (app SwimLogsApp) AttendanceReport(
+	ctx context.Context,
+	from, to time.Time,
+	tz *string,
+) (int, []apidef.AttendanceSummary, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.AttendanceReport")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	if to.Before(from) {
+		return 0, nil, fmt.Errorf(
+			"AttendanceReport: %w: %s is before %s",
+			ErrInvalidDateRange,
+			to.Format(time.DateOnly),
+			from.Format(time.DateOnly),
+		)
+	}
+
+	loc, err := app.location(tz)
+	if err != nil {
+		return 0, nil, fmt.Errorf("AttendanceReport: %w", err)
+	}
+	start, end := dateRange(from, to, loc)
+
+	trainings, dataSummaries, err := app.repo.AttendanceSummaries(ctx, start, end)
+	if err != nil {
+		return 0, nil, fmt.Errorf("AttendanceReport: %w", err)
+	}
+
+	summaries := make([]apidef.AttendanceSummary, len(dataSummaries))
+	for i, s := range dataSummaries {
+		summaries[i] = dataAttendanceSummaryToApi(s, attendanceRate(s, trainings))
+	}
+	return trainings, summaries, nil
+}
+
+// attendanceRate is a ratio of attended trainings to all trainings on which
+// the user wasn't excused.
+func attendanceRate(s data.AttendanceSummary, trainings int) float64 {
+	expected := trainings - s.Excused
+	if expected <= 0 {
+		return 0
+	}
+	return float64(s.Present+s.Partial) / float64(expected)
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/pkg/backup"
+)
+
+type RestoreMode string
+
+const (
+	// RestoreMerge overwrites stored entities with the ones from backup
+	// and keeps the rest
+	RestoreMerge RestoreMode = "merge"
+	// RestoreReplace deletes everything stored before importing backup
+	RestoreReplace RestoreMode = "replace"
+)
+
+var (
+	ErrInvalidBackup      = backup.ErrInvalid
+	ErrInvalidRestoreMode = errors.New("invalid restore mode")
+)
+
+// Backup streams an archive of everything stored to w. It isn't limited by
+// the database timeout, a backup of all data can take longer than a
+// request.
+func (app SwimLogsApp) Backup(ctx context.Context, w io.Writer) (backup.Contents, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.Backup")
+	defer span.End()
+
+	s, err := app.repo.Snapshot(ctx)
+	if err != nil {
+		return backup.Contents{}, fmt.Errorf("Backup: %w", err)
+	}
+
+	contents, err := backup.Write(w, s, time.Now())
+	if err != nil {
+		return backup.Contents{}, fmt.Errorf("Backup: %w", err)
+	}
+	return contents, nil
+}
+
+// Restore imports an archive from r in one transaction. Same as Backup, it
+// isn't limited by the database timeout.
+func (app SwimLogsApp) Restore(
+	ctx context.Context,
+	r io.Reader,
+	mode RestoreMode,
+) (backup.Contents, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.Restore")
+	defer span.End()
+
+	if mode != RestoreMerge && mode != RestoreReplace {
+		return backup.Contents{}, fmt.Errorf(
+			"Restore: %w: %q, must be %q or %q",
+			ErrInvalidRestoreMode,
+			mode,
+			RestoreMerge,
+			RestoreReplace,
+		)
+	}
+
+	s, contents, err := backup.Read(r)
+	if err != nil {
+		return backup.Contents{}, fmt.Errorf("Restore: %w", err)
+	}
+
+	if err := app.repo.Restore(ctx, s, mode == RestoreReplace); err != nil {
+		return backup.Contents{}, fmt.Errorf("Restore: %w", err)
+	}
+	return contents, nil
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var ErrInvalidCssTest = errors.New("invalid css test")
+
+// intensityZone describes how much slower, or faster, than CSS pace is a
+// repetition swum and how much rest is taken after it, both per 100m.
+type intensityZone struct {
+	intensity    apidef.IntensityEnum
+	paceOffsetMs int
+	restPer100Ms int
+}
+
+var intensityZones = []intensityZone{
+	{intensity: apidef.Easy, paceOffsetMs: 12_000, restPer100Ms: 5_000},
+	{intensity: apidef.Endurance, paceOffsetMs: 6_000, restPer100Ms: 10_000},
+	{intensity: apidef.Threshold, paceOffsetMs: 0, restPer100Ms: 15_000},
+	{intensity: apidef.Speed, paceOffsetMs: -4_000, restPer100Ms: 30_000},
+}
+
+const (
+	minRestMs          = 5_000
+	intervalRoundingMs = 5_000
+)
+
+func (app SwimLogsApp) CreateCssTest(
+	ctx context.Context,
+	userId uuid.UUID,
+	nc apidef.NewCssTest,
+) (apidef.CssTest, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.CreateCssTest")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	if nc.Time200Ms <= 0 || nc.Time400Ms <= nc.Time200Ms {
+		return apidef.CssTest{}, fmt.Errorf(
+			"CreateCssTest: %w: 400m time %d must be slower than positive 200m time %d",
+			ErrInvalidCssTest,
+			nc.Time400Ms,
+			nc.Time200Ms,
+		)
+	}
+
+	_, err := app.repo.User(ctx, userId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return apidef.CssTest{}, fmt.Errorf("CreateCssTest user: %w", ErrNotFound)
+	} else if err != nil {
+		return apidef.CssTest{}, fmt.Errorf("CreateCssTest user: %w", err)
+	}
+
+	c, err := app.repo.PersistCssTest(ctx, newCssTestToDataCssTest(userId, nc))
+	if err != nil {
+		return apidef.CssTest{}, fmt.Errorf("CreateCssTest: %w", err)
+	}
+
+	return dataCssTestToApiCssTest(c), nil
+}
+
+func (app SwimLogsApp) CssTests(ctx context.Context, userId uuid.UUID) ([]apidef.CssTest, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.CssTests")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	_, err := app.repo.User(ctx, userId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return nil, fmt.Errorf("CssTests user: %w", ErrNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("CssTests user: %w", err)
+	}
+
+	dataTests, err := app.repo.CssTests(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("CssTests: %w", err)
+	}
+
+	tests := make([]apidef.CssTest, len(dataTests))
+	for i, c := range dataTests {
+		tests[i] = dataCssTestToApiCssTest(c)
+	}
+	return tests, nil
+}
+
+func (app SwimLogsApp) IntervalSuggestions(
+	ctx context.Context,
+	trainingId, userId uuid.UUID,
+) (apidef.CssTest, []apidef.SetIntervalSuggestion, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.IntervalSuggestions")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	t, err := app.repo.Training(ctx, trainingId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return apidef.CssTest{}, nil, fmt.Errorf("IntervalSuggestions training: %w", ErrNotFound)
+	} else if err != nil {
+		return apidef.CssTest{}, nil, fmt.Errorf("IntervalSuggestions training: %w", err)
+	}
+
+	c, err := app.repo.LatestCssTest(ctx, userId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return apidef.CssTest{}, nil, fmt.Errorf("IntervalSuggestions css test: %w", ErrNotFound)
+	} else if err != nil {
+		return apidef.CssTest{}, nil, fmt.Errorf("IntervalSuggestions css test: %w", err)
+	}
+
+	return dataCssTestToApiCssTest(c), suggestIntervals(t, cssPace100Ms(c)), nil
+}
+
+// cssPace100Ms computes critical swim speed pace per 100m, which is the
+// time it takes to swim the 200m difference between the test swims, halved.
+func cssPace100Ms(c data.CssTest) int {
+	return (c.Time400Ms - c.Time200Ms + 1) / 2
+}
+
+// suggestIntervals suggests StartSeconds of an Interval start of every set
+// in a training for each intensity zone, given the swimmer's CSS pace.
+func suggestIntervals(t data.Training, pace100Ms int) []apidef.SetIntervalSuggestion {
+	suggestions := make([]apidef.SetIntervalSuggestion, 0, len(t.Sets))
+	for _, s := range t.Sets {
+		intervals := make([]apidef.IntervalSuggestion, 0, len(intensityZones))
+		for _, zone := range intensityZones {
+			intervals = append(intervals, apidef.IntervalSuggestion{
+				Intensity:    zone.intensity,
+				StartSeconds: intervalSeconds(s.DistanceMeters, pace100Ms, zone),
+			})
+		}
+		suggestions = append(suggestions, apidef.SetIntervalSuggestion{
+			SetId:     s.Id,
+			SetOrder:  s.SetOrder,
+			Intervals: intervals,
+		})
+	}
+	return suggestions
+}
+
+func intervalSeconds(distanceMeters, pace100Ms int, zone intensityZone) int {
+	pace := max(pace100Ms+zone.paceOffsetMs, pace100Ms/2)
+	swimMs := distanceMeters * pace / 100
+	restMs := max(distanceMeters*zone.restPer100Ms/100, minRestMs)
+
+	intervalMs := swimMs + restMs
+	rounded := (intervalMs + intervalRoundingMs - 1) / intervalRoundingMs * intervalRoundingMs
+	return rounded / 1000
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"github.com/google/uuid"
+	"github.com/oapi-codegen/runtime/types"
 
 	"github.com/Nesquiko/swimlogs/apidef"
 	"github.com/Nesquiko/swimlogs/pkg/data"
@@ -9,11 +10,14 @@ import (
 
 func newTrainingToDataTraining(nt apidef.NewTraining) data.Training {
 	id := uuid.New()
+	pool := poolOrDefault(nt.Pool)
 	return data.Training{
 		Id:            id,
 		Start:         nt.Start,
 		DurationMin:   nt.DurationMin,
 		TotalDistance: nt.TotalDistance,
+		PoolLength:    pool.Length,
+		PoolUnit:      string(pool.Unit),
 		Sets:          newSetsToDataSets(nt.Sets, id),
 	}
 }
@@ -55,44 +59,59 @@ func newSetToDataSet(set apidef.NewTrainingSet, tId uuid.UUID) data.TrainingSet
 	return ts
 }
 
+func dataPool(t data.Training) apidef.Pool {
+	return apidef.Pool{Length: t.PoolLength, Unit: apidef.PoolUnitEnum(t.PoolUnit)}
+}
+
 func trainingToDetail(t data.Training) apidef.TrainingDetail {
+	pool := dataPool(t)
 	return apidef.TrainingDetail{
-		Id:            t.Id,
-		Start:         t.Start,
-		DurationMin:   t.DurationMin,
-		TotalDistance: t.TotalDistance,
+		Id:                 t.Id,
+		Start:              t.Start,
+		DurationMin:        t.DurationMin,
+		TotalDistance:      t.TotalDistance,
+		TotalDistanceYards: toYards(t.TotalDistance, pool),
+		Pool:               pool,
 	}
 }
 
 func dataTrainingToApiTraining(t data.Training) apidef.Training {
+	pool := dataPool(t)
+	totalYards := trainingYards(t, pool)
 	return apidef.Training{
-		Id:            t.Id,
-		DurationMin:   t.DurationMin,
-		Start:         t.Start,
-		TotalDistance: t.TotalDistance,
-		Sets:          dataSetsToApiSets(t.Sets),
+		Id:                 t.Id,
+		DurationMin:        t.DurationMin,
+		Start:              t.Start,
+		TotalDistance:      t.TotalDistance,
+		TotalDistanceYards: &totalYards,
+		Pool:               &pool,
+		Sets:               dataSetsToApiSets(t.Sets, pool),
 	}
 }
 
-func dataSetsToApiSets(sets []data.TrainingSet) []apidef.TrainingSet {
+func dataSetsToApiSets(sets []data.TrainingSet, pool apidef.Pool) []apidef.TrainingSet {
 	apiSets := make([]apidef.TrainingSet, 0, len(sets))
 	for _, set := range sets {
-		apiSets = append(apiSets, dataSetToApiSet(set))
+		apiSets = append(apiSets, dataSetToApiSet(set, pool))
 	}
 	return apiSets
 }
 
-func dataSetToApiSet(s data.TrainingSet) apidef.TrainingSet {
+func dataSetToApiSet(s data.TrainingSet, pool apidef.Pool) apidef.TrainingSet {
+	distanceYards := toYards(s.DistanceMeters, pool)
+	totalDistanceYards := setYards(s, pool)
 	set := apidef.TrainingSet{
-		Id:             s.Id,
-		SetOrder:       s.SetOrder,
-		Repeat:         s.Repeat,
-		Description:    s.Description,
-		DistanceMeters: s.DistanceMeters,
-		StartType:      apidef.StartTypeEnum(s.StartType),
-		StartSeconds:   s.StartSeconds,
-		TotalDistance:  s.TotalDistance,
-		Group:          (*apidef.GroupEnum)(s.Group),
+		Id:                 s.Id,
+		SetOrder:           s.SetOrder,
+		Repeat:             s.Repeat,
+		Description:        s.Description,
+		DistanceMeters:     s.DistanceMeters,
+		DistanceYards:      &distanceYards,
+		StartType:          apidef.StartTypeEnum(s.StartType),
+		StartSeconds:       s.StartSeconds,
+		TotalDistance:      s.TotalDistance,
+		TotalDistanceYards: &totalDistanceYards,
+		Group:              (*apidef.GroupEnum)(s.Group),
 	}
 
 	if s.Equipment == nil {
@@ -108,11 +127,14 @@ func dataSetToApiSet(s data.TrainingSet) apidef.TrainingSet {
 }
 
 func trainingToDataTraining(t apidef.Training) data.Training {
+	pool := poolOrDefault(t.Pool)
 	return data.Training{
 		Id:            t.Id,
 		Start:         t.Start,
 		DurationMin:   t.DurationMin,
 		TotalDistance: t.TotalDistance,
+		PoolLength:    pool.Length,
+		PoolUnit:      string(pool.Unit),
 		Sets:          setsToDataSets(t.Sets, t.Id),
 	}
 }
@@ -153,3 +175,105 @@ func setToDataSet(set apidef.TrainingSet, tId uuid.UUID) data.TrainingSet {
 
 	return ts
 }
+
+func dataUserToApiUser(u data.User) apidef.User {
+	return apidef.User{Id: u.Id, Name: u.Name}
+}
+
+func dataSetResultToApiSetResult(r data.SetResult) apidef.SetResult {
+	stats := resultStats(r.TimesMs)
+	return apidef.SetResult{
+		SetId:     r.SetId,
+		UserId:    r.UserId,
+		TimesMs:   r.TimesMs,
+		AverageMs: stats.average,
+		BestMs:    stats.best,
+		WorstMs:   stats.worst,
+	}
+}
+
+func newRaceResultToDataRaceResult(userId uuid.UUID, nr apidef.NewRaceResult) data.RaceResult {
+	return data.RaceResult{
+		Id:         uuid.New(),
+		UserId:     userId,
+		TrainingId: nr.TrainingId,
+		Distance:   nr.Distance,
+		Stroke:     string(nr.Stroke),
+		PoolLength: nr.Pool.Length,
+		PoolUnit:   string(nr.Pool.Unit),
+		TimeMs:     nr.TimeMs,
+		Date:       nr.Date.Time,
+	}
+}
+
+func dataRaceResultToApiRaceResult(r data.RaceResult) apidef.RaceResult {
+	return apidef.RaceResult{
+		Id:             r.Id,
+		UserId:         r.UserId,
+		TrainingId:     r.TrainingId,
+		Distance:       r.Distance,
+		Stroke:         apidef.StrokeEnum(r.Stroke),
+		Pool:           apidef.Pool{Length: r.PoolLength, Unit: apidef.PoolUnitEnum(r.PoolUnit)},
+		TimeMs:         r.TimeMs,
+		Date:           types.Date{Time: r.Date},
+		IsPersonalBest: r.IsPersonalBest,
+	}
+}
+
+func newCssTestToDataCssTest(userId uuid.UUID, nc apidef.NewCssTest) data.CssTest {
+	return data.CssTest{
+		Id:        uuid.New(),
+		UserId:    userId,
+		Time400Ms: nc.Time400Ms,
+		Time200Ms: nc.Time200Ms,
+		Date:      nc.Date.Time,
+	}
+}
+
+func dataCssTestToApiCssTest(c data.CssTest) apidef.CssTest {
+	return apidef.CssTest{
+		Id:        c.Id,
+		UserId:    c.UserId,
+		Time400Ms: c.Time400Ms,
+		Time200Ms: c.Time200Ms,
+		Date:      types.Date{Time: c.Date},
+		Pace100Ms: cssPace100Ms(c),
+	}
+}
+
+func attendanceToDataAttendance(
+	attendance []apidef.Attendance,
+	tId uuid.UUID,
+) []data.Attendance {
+	dataAttendance := make([]data.Attendance, 0, len(attendance))
+	for _, a := range attendance {
+		dataAttendance = append(dataAttendance, data.Attendance{
+			TrainingId:      tId,
+			UserId:          a.UserId,
+			Status:          string(a.Status),
+			MetersCompleted: a.MetersCompleted,
+		})
+	}
+	return dataAttendance
+}
+
+func dataAttendanceToApiAttendance(a data.Attendance) apidef.Attendance {
+	return apidef.Attendance{
+		UserId:          a.UserId,
+		Status:          apidef.AttendanceStatusEnum(a.Status),
+		MetersCompleted: a.MetersCompleted,
+	}
+}
+
+func dataAttendanceSummaryToApi(s data.AttendanceSummary, rate float64) apidef.AttendanceSummary {
+	return apidef.AttendanceSummary{
+		UserId:            s.UserId,
+		Name:              s.Name,
+		Present:           s.Present,
+		Partial:           s.Partial,
+		Absent:            s.Absent,
+		Excused:           s.Excused,
+		AttendanceRate:    rate,
+		CompletedDistance: s.CompletedDistance,
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+const (
+	DependencyDatabase   = "database"
+	DependencyMigrations = "migrations"
+)
+
+// Readiness checks every dependency the app needs for serving requests and
+// returns an error for each of them, nil if the dependency is ready.
+// Repositories which aren't a data.HealthChecker have no dependencies.
+func (app SwimLogsApp) Readiness(ctx context.Context) map[string]error {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.Readiness")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	checker, ok := app.repo.(data.HealthChecker)
+	if !ok {
+		return map[string]error{}
+	}
+
+	deps := map[string]error{
+		DependencyDatabase:   nil,
+		DependencyMigrations: nil,
+	}
+	if err := checker.Ping(ctx); err != nil {
+		deps[DependencyDatabase] = fmt.Errorf("Readiness: %w", err)
+	}
+
+	status, err := checker.Migrations(ctx)
+	switch {
+	case err != nil:
+		deps[DependencyMigrations] = fmt.Errorf("Readiness: %w", err)
+	case status.Dirty:
+		deps[DependencyMigrations] = fmt.Errorf("schema version %d is dirty", status.Version)
+	case !status.UpToDate():
+		deps[DependencyMigrations] = fmt.Errorf(
+			"schema version %d doesn't match newest migration %d",
+			status.Version,
+			status.Latest,
+		)
+	}
+
+	return deps
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/notation"
+)
+
+var ErrInvalidNotation = notation.ErrInvalid
+
+// ParseNotation parses sets written in the workout notation, with distances
+// and totals calculated as in a new training in the same pool.
+func (app SwimLogsApp) ParseNotation(
+	ctx context.Context,
+	req apidef.ParseNotationRequest,
+) (apidef.ParseNotationResponse, error) {
+	_, span := tracer.Start(ctx, "SwimLogsApp.ParseNotation")
+	defer span.End()
+
+	pool := poolOrDefault(req.Pool)
+	if err := validatePool(pool); err != nil {
+		return apidef.ParseNotationResponse{}, fmt.Errorf("ParseNotation: %w", err)
+	}
+
+	sets, err := notation.Parse(req.Notation, pool)
+	if err != nil {
+		return apidef.ParseNotationResponse{}, fmt.Errorf("ParseNotation: %w", err)
+	}
+
+	nt := apidef.NewTraining{Pool: &pool, Sets: sets}
+	if err := recalcDistanceOnNewTraining(&nt); err != nil {
+		return apidef.ParseNotationResponse{}, fmt.Errorf("ParseNotation: %w", err)
+	}
+	for i := range nt.Sets {
+		yards := toYards(nt.Sets[i].TotalDistance, pool)
+		nt.Sets[i].TotalDistanceYards = &yards
+	}
+
+	return apidef.ParseNotationResponse{
+		Sets:               nt.Sets,
+		TotalDistance:      nt.TotalDistance,
+		TotalDistanceYards: toYards(nt.TotalDistance, pool),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var ErrInvalidRaceResult = errors.New("invalid race result")
+
+func (app SwimLogsApp) CreateRaceResult(
+	ctx context.Context,
+	userId uuid.UUID,
+	nr apidef.NewRaceResult,
+) (apidef.RaceResult, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.CreateRaceResult")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	if err := validatePool(nr.Pool); err != nil {
+		return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult: %w", err)
+	}
+	if err := validatePoolDistance(nr.Pool, nr.Distance); err != nil {
+		return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult: %w", err)
+	}
+	if nr.TimeMs <= 0 {
+		return apidef.RaceResult{}, fmt.Errorf(
+			"CreateRaceResult: %w: time must be positive",
+			ErrInvalidRaceResult,
+		)
+	}
+
+	_, err := app.repo.User(ctx, userId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult user: %w", ErrNotFound)
+	} else if err != nil {
+		return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult user: %w", err)
+	}
+
+	if nr.TrainingId != nil {
+		_, err := app.repo.Training(ctx, *nr.TrainingId)
+		if errors.Is(err, data.ErrRowsNotFound) {
+			return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult training: %w", ErrNotFound)
+		} else if err != nil {
+			return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult training: %w", err)
+		}
+	}
+
+	r, err := app.repo.PersistRaceResult(ctx, newRaceResultToDataRaceResult(userId, nr))
+	if err != nil {
+		return apidef.RaceResult{}, fmt.Errorf("CreateRaceResult: %w", err)
+	}
+
+	return dataRaceResultToApiRaceResult(r), nil
+}
+
+func (app SwimLogsApp) PersonalBests(ctx context.Context, userId uuid.UUID) ([]apidef.PersonalBest, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.PersonalBests")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	_, err := app.repo.User(ctx, userId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return nil, fmt.Errorf("PersonalBests user: %w", ErrNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("PersonalBests user: %w", err)
+	}
+
+	results, err := app.repo.RaceResults(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("PersonalBests: %w", err)
+	}
+
+	return personalBests(results), nil
+}
+
+// personalBests expects results to be grouped by event and sorted by date
+// within each event. Progression of an event contains every result which
+// was faster than all results swum before it.
+func personalBests(results []data.RaceResult) []apidef.PersonalBest {
+	pbs := make([]apidef.PersonalBest, 0)
+
+	for _, r := range results {
+		ar := dataRaceResultToApiRaceResult(r)
+		last := len(pbs) - 1
+		if last < 0 || !sameEvent(pbs[last], ar) {
+			pbs = append(pbs, apidef.PersonalBest{
+				Distance:    ar.Distance,
+				Stroke:      ar.Stroke,
+				Pool:        ar.Pool,
+				Best:        ar,
+				Progression: []apidef.RaceResult{ar},
+			})
+			continue
+		}
+
+		if ar.TimeMs < pbs[last].Best.TimeMs {
+			pbs[last].Best = ar
+			pbs[last].Progression = append(pbs[last].Progression, ar)
+		}
+	}
+
+	return pbs
+}
+
+func sameEvent(pb apidef.PersonalBest, r apidef.RaceResult) bool {
+	return pb.Distance == r.Distance && pb.Stroke == r.Stroke && pb.Pool == r.Pool
+}
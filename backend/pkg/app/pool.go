@@ -0,0 +1,110 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+const metersPerYard = 0.9144
+
+var (
+	ErrInvalidPool     = errors.New("invalid pool")
+	ErrInvalidDistance = errors.New("distance is not a multiple of pool length")
+)
+
+var DefaultPool = apidef.Pool{Length: 25, Unit: apidef.Meters}
+
+func poolOrDefault(p *apidef.Pool) apidef.Pool {
+	if p == nil {
+		return DefaultPool
+	}
+	return *p
+}
+
+func validatePool(p apidef.Pool) error {
+	if p.Length <= 0 {
+		return fmt.Errorf("%w: length %d must be positive", ErrInvalidPool, p.Length)
+	}
+	if p.Unit != apidef.Meters && p.Unit != apidef.Yards {
+		return fmt.Errorf("%w: unknown unit %q", ErrInvalidPool, p.Unit)
+	}
+	return nil
+}
+
+// setPoolDistance returns distance of one repetition in units of the pool.
+func setPoolDistance(p apidef.Pool, distanceMeters int, distanceYards *int) (int, error) {
+	distance := distanceMeters
+	if p.Unit == apidef.Yards {
+		if distanceYards != nil {
+			distance = *distanceYards
+		} else {
+			distance = metersToYards(distanceMeters)
+		}
+	}
+
+	if err := validatePoolDistance(p, distance); err != nil {
+		return 0, err
+	}
+	return distance, nil
+}
+
+func validatePoolDistance(p apidef.Pool, distance int) error {
+	if distance <= 0 || distance%p.Length != 0 {
+		return fmt.Errorf(
+			"%w: %d %s in %d %s pool",
+			ErrInvalidDistance,
+			distance,
+			p.Unit,
+			p.Length,
+			p.Unit,
+		)
+	}
+	return nil
+}
+
+// toMeters converts distance in units of the pool to stored meters.
+func toMeters(distance int, p apidef.Pool) int {
+	if p.Unit == apidef.Yards {
+		return yardsToMeters(distance)
+	}
+	return distance
+}
+
+// toYards converts meters to yards, in yard pools to whole pool lengths.
+func toYards(meters int, p apidef.Pool) int {
+	if p.Unit == apidef.Yards {
+		lengths := math.Round(float64(meters) / metersPerYard / float64(p.Length))
+		return int(lengths) * p.Length
+	}
+	return metersToYards(meters)
+}
+
+func setYards(s data.TrainingSet, p apidef.Pool) int {
+	if p.Unit == apidef.Yards {
+		return s.Repeat * toYards(s.DistanceMeters, p)
+	}
+	return toYards(s.TotalDistance, p)
+}
+
+func trainingYards(t data.Training, p apidef.Pool) int {
+	if p.Unit != apidef.Yards || len(t.Sets) == 0 {
+		return toYards(t.TotalDistance, p)
+	}
+	total := 0
+	for _, s := range t.Sets {
+		total += setYards(s, p)
+	}
+	return total
+}
+
+func yardsToMeters(yards int) int {
+	return int(math.Round(float64(yards) * metersPerYard))
+}
+
+func metersToYards(meters int) int {
+	return int(math.Round(float64(meters) / metersPerYard))
+}
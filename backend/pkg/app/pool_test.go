@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+var yards25 = apidef.Pool{Length: 25, Unit: apidef.Yards}
+
+func TestCreateTraining_YardTotals(t *testing.T) {
+	training := createTraining(t, newApp(), apidef.NewTraining{
+		DurationMin: 60,
+		Pool:        &yards25,
+		Start:       time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC),
+		Sets: []apidef.NewTrainingSet{
+			{Repeat: 4, DistanceYards: asPtr(100), StartType: apidef.None},
+			{Repeat: 1, DistanceYards: asPtr(75), SetOrder: 1, StartType: apidef.None},
+		},
+	})
+
+	assert.Equal(t, yards25, *training.Pool)
+	assert.Equal(t, asPtr(475), training.TotalDistanceYards)
+	assert.Equal(t, 434, training.TotalDistance, "meters are converted from the yard total once")
+	require.Len(t, training.Sets, 2)
+	assert.Equal(t, asPtr(100), training.Sets[0].DistanceYards)
+	assert.Equal(t, asPtr(400), training.Sets[0].TotalDistanceYards)
+	assert.Equal(t, asPtr(75), training.Sets[1].TotalDistanceYards)
+}
+
+func TestCreateTraining_DistanceNotInPoolLengths(t *testing.T) {
+	_, err := newApp().CreateTraining(context.Background(), apidef.NewTraining{
+		DurationMin: 60,
+		Start:       time.Now(),
+		Sets:        []apidef.NewTrainingSet{{Repeat: 1, DistanceMeters: 30, StartType: apidef.None}},
+	})
+	assert.ErrorIs(t, err, app.ErrInvalidDistance)
+}
+
+func TestEditTraining_KeepsPool(t *testing.T) {
+	a := newApp()
+	training := createTraining(t, a, apidef.NewTraining{
+		DurationMin: 60,
+		Pool:        &yards25,
+		Start:       time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC),
+		Sets:        []apidef.NewTrainingSet{{Repeat: 2, DistanceYards: asPtr(50), StartType: apidef.None}},
+	})
+
+	training.Pool = nil
+	training.Sets[0].Repeat = 3
+	td, err := a.EditTraining(context.Background(), training.Id, training)
+	require.NoError(t, err)
+	assert.Equal(t, yards25, td.Pool)
+	assert.Equal(t, 150, td.TotalDistanceYards)
+}
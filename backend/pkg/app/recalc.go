@@ -1,25 +1,53 @@
 package app
 
 import (
+	"fmt"
+
 	"github.com/Nesquiko/swimlogs/apidef"
 )
 
-func recalcDistanceOnNewTraining(nt *apidef.NewTraining) {
+func recalcDistanceOnNewTraining(nt *apidef.NewTraining) error {
+	pool := poolOrDefault(nt.Pool)
+	if err := validatePool(pool); err != nil {
+		return err
+	}
+	nt.Pool = &pool
+
+	// in units of the pool, converted to meters once so that the rounding
+	// of each set doesn't add up
 	total := 0
 	for i := 0; i < len(nt.Sets); i++ {
 		ns := &nt.Sets[i]
-		ns.TotalDistance = ns.Repeat * ns.DistanceMeters
-		total += ns.TotalDistance
+		distance, err := setPoolDistance(pool, ns.DistanceMeters, ns.DistanceYards)
+		if err != nil {
+			return fmt.Errorf("set %d: %w", i, err)
+		}
+		ns.DistanceMeters = toMeters(distance, pool)
+		ns.TotalDistance = toMeters(ns.Repeat*distance, pool)
+		total += ns.Repeat * distance
 	}
-	nt.TotalDistance = total
+	nt.TotalDistance = toMeters(total, pool)
+	return nil
 }
 
-func recalcDistanceOnTraining(t *apidef.Training) {
+func recalcDistanceOnTraining(t *apidef.Training) error {
+	pool := poolOrDefault(t.Pool)
+	if err := validatePool(pool); err != nil {
+		return err
+	}
+	t.Pool = &pool
+
 	total := 0
 	for i := 0; i < len(t.Sets); i++ {
 		ns := &t.Sets[i]
-		ns.TotalDistance = ns.Repeat * ns.DistanceMeters
-		total += t.Sets[i].TotalDistance
+		distance, err := setPoolDistance(pool, ns.DistanceMeters, ns.DistanceYards)
+		if err != nil {
+			return fmt.Errorf("set %d: %w", i, err)
+		}
+		ns.DistanceMeters = toMeters(distance, pool)
+		ns.TotalDistance = toMeters(ns.Repeat*distance, pool)
+		total += ns.Repeat * distance
 	}
-	t.TotalDistance = total
+	t.TotalDistance = toMeters(total, pool)
+	return nil
 }
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/notation"
+)
+
+// TextFormat is a text representation of a training meant for sharing.
+type TextFormat int
+
+const (
+	PlainText TextFormat = iota
+	Markdown
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"#", `\#`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"|", `\|`,
+)
+
+// RenderTraining writes t as text in format f. Sets are listed in their
+// order with distances in units of the pool, start is shown in tz, the
+// default timezone of the app if nil.
+func (app SwimLogsApp) RenderTraining(t apidef.Training, f TextFormat, tz *string) (string, error) {
+	loc, err := app.location(tz)
+	if err != nil {
+		return "", fmt.Errorf("RenderTraining: %w", err)
+	}
+
+	pool := poolOrDefault(t.Pool)
+	unit := unitSymbol(pool)
+
+	escape := func(s string) string { return s }
+	strong := escape
+	if f == Markdown {
+		escape = markdownEscaper.Replace
+		strong = func(s string) string { return "**" + s + "**" }
+	}
+
+	var b strings.Builder
+	title := trainingTitle(t.Start, loc)
+	if f == Markdown {
+		title = "# " + title
+	}
+	fmt.Fprintf(&b, "%s\n\n%d%s pool\n\n", title, pool.Length, unit)
+
+	sets := slices.Clone(t.Sets)
+	slices.SortStableFunc(sets, func(a, b apidef.TrainingSet) int { return a.SetOrder - b.SetOrder })
+	for i, s := range sets {
+		distance := notation.SetDistance(s, pool)
+
+		head := fmt.Sprintf("%d%s", distance, unit)
+		if s.Repeat != 1 {
+			head = fmt.Sprintf("%d × %s", s.Repeat, head)
+		}
+		if s.Description != nil && *s.Description != "" {
+			head += " " + escape(*s.Description)
+		}
+
+		parts := []string{head}
+		if s.StartSeconds != nil {
+			switch s.StartType {
+			case apidef.Interval:
+				parts = append(parts, "interval "+notation.FormatSeconds(*s.StartSeconds))
+			case apidef.Pause:
+				parts = append(parts, "pause "+notation.FormatSeconds(*s.StartSeconds))
+			}
+		}
+		if s.Equipment != nil && len(*s.Equipment) > 0 {
+			items := make([]string, 0, len(*s.Equipment))
+			for _, e := range *s.Equipment {
+				items = append(items, strings.ToLower(string(e)))
+			}
+			parts = append(parts, strings.Join(items, " + "))
+		}
+		if s.Group != nil {
+			parts = append(parts, "group "+string(*s.Group))
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, strings.Join(parts, ", "))
+	}
+	if len(sets) > 0 {
+		b.WriteByte('\n')
+	}
+
+	total := t.TotalDistance
+	if pool.Unit == apidef.Yards {
+		total = toYards(t.TotalDistance, pool)
+		if t.TotalDistanceYards != nil {
+			total = *t.TotalDistanceYards
+		}
+	}
+	fmt.Fprintf(&b, "%s %d%s in %d min\n", strong("Total"), total, unit, t.DurationMin)
+	return b.String(), nil
+}
+
+// trainingTitle names a training by its start in loc.
+func trainingTitle(start time.Time, loc *time.Location) string {
+	return "Training " + start.In(loc).Format("Mon 2006-01-02 15:04")
+}
+
+func unitSymbol(p apidef.Pool) string {
+	if p.Unit == apidef.Yards {
+		return "yd"
+	}
+	return "m"
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var ErrInvalidResult = errors.New("invalid result")
+
+func (app SwimLogsApp) SubmitSetResult(
+	ctx context.Context,
+	setId uuid.UUID,
+	newResult apidef.NewSetResult,
+) (apidef.SetResult, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.SubmitSetResult")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	set, err := app.repo.Set(ctx, setId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return apidef.SetResult{}, fmt.Errorf("SubmitSetResult set: %w", ErrNotFound)
+	} else if err != nil {
+		return apidef.SetResult{}, fmt.Errorf("SubmitSetResult set: %w", err)
+	}
+
+	_, err = app.repo.User(ctx, newResult.UserId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return apidef.SetResult{}, fmt.Errorf("SubmitSetResult user: %w", ErrNotFound)
+	} else if err != nil {
+		return apidef.SetResult{}, fmt.Errorf("SubmitSetResult user: %w", err)
+	}
+
+	if len(newResult.TimesMs) != set.Repeat {
+		return apidef.SetResult{}, fmt.Errorf(
+			"SubmitSetResult: %w: got %d times for set with %d repetitions",
+			ErrInvalidResult,
+			len(newResult.TimesMs),
+			set.Repeat,
+		)
+	}
+	for i, t := range newResult.TimesMs {
+		if t <= 0 {
+			return apidef.SetResult{}, fmt.Errorf(
+				"SubmitSetResult: %w: time of repetition %d must be positive",
+				ErrInvalidResult,
+				i,
+			)
+		}
+	}
+
+	r, err := app.repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   setId,
+		UserId:  newResult.UserId,
+		TimesMs: newResult.TimesMs,
+	})
+	if err != nil {
+		return apidef.SetResult{}, fmt.Errorf("SubmitSetResult: %w", err)
+	}
+
+	return dataSetResultToApiSetResult(r), nil
+}
+
+func (app SwimLogsApp) SetResults(ctx context.Context, setId uuid.UUID) ([]apidef.SetResult, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.SetResults")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	_, err := app.repo.Set(ctx, setId)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return nil, fmt.Errorf("SetResults: %w", ErrNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("SetResults: %w", err)
+	}
+
+	dataResults, err := app.repo.SetResults(ctx, setId)
+	if err != nil {
+		return nil, fmt.Errorf("SetResults: %w", err)
+	}
+
+	results := make([]apidef.SetResult, len(dataResults))
+	for i, r := range dataResults {
+		results[i] = dataSetResultToApiSetResult(r)
+	}
+	return results, nil
+}
+
+type timeStats struct {
+	average int
+	best    int
+	worst   int
+}
+
+// resultStats computes average, best and worst time of a result,
+// times must not be empty.
+func resultStats(timesMs []int) timeStats {
+	stats := timeStats{best: timesMs[0], worst: timesMs[0]}
+	sum := 0
+	for _, t := range timesMs {
+		sum += t
+		stats.best = min(stats.best, t)
+		stats.worst = max(stats.worst, t)
+	}
+	stats.average = (sum + len(timesMs)/2) / len(timesMs)
+	return stats
+}
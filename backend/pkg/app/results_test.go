@@ -0,0 +1,74 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+func TestSubmitSetResult_Validation(t *testing.T) {
+	ctx := context.Background()
+	a := newApp()
+	training := createTraining(t, a, apidef.NewTraining{
+		DurationMin: 60,
+		Start:       time.Now(),
+		Sets:        []apidef.NewTrainingSet{{Repeat: 2, DistanceMeters: 100, StartType: apidef.None}},
+	})
+	setId := training.Sets[0].Id
+	user, err := a.CreateUser(ctx, apidef.NewUser{Name: "swimmer"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		setId  uuid.UUID
+		result apidef.NewSetResult
+		err    error
+	}{
+		{"too few times", setId, apidef.NewSetResult{UserId: user.Id, TimesMs: []int{80_000}}, app.ErrInvalidResult},
+		{"zero time", setId, apidef.NewSetResult{UserId: user.Id, TimesMs: []int{80_000, 0}}, app.ErrInvalidResult},
+		{"unknown set", uuid.New(), apidef.NewSetResult{UserId: user.Id, TimesMs: []int{80_000, 81_000}}, app.ErrNotFound},
+		{"unknown user", setId, apidef.NewSetResult{UserId: uuid.New(), TimesMs: []int{80_000, 81_000}}, app.ErrNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := a.SubmitSetResult(ctx, c.setId, c.result)
+			assert.ErrorIs(t, err, c.err)
+		})
+	}
+
+	_, err = a.SubmitSetResult(ctx, setId, apidef.NewSetResult{UserId: user.Id, TimesMs: []int{80_000, 81_000}})
+	require.NoError(t, err)
+	results, err := a.SetResults(ctx, setId)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []int{80_000, 81_000}, results[0].TimesMs)
+}
+
+func TestEditTraining_DropsStaleSetResults(t *testing.T) {
+	ctx := context.Background()
+	a := newApp()
+	training := createTraining(t, a, apidef.NewTraining{
+		DurationMin: 60,
+		Start:       time.Now(),
+		Sets:        []apidef.NewTrainingSet{{Repeat: 2, DistanceMeters: 100, StartType: apidef.None}},
+	})
+	user, err := a.CreateUser(ctx, apidef.NewUser{Name: "swimmer"})
+	require.NoError(t, err)
+	_, err = a.SubmitSetResult(ctx, training.Sets[0].Id, apidef.NewSetResult{UserId: user.Id, TimesMs: []int{80_000, 81_000}})
+	require.NoError(t, err)
+
+	training.Sets[0].Repeat = 3
+	_, err = a.EditTraining(ctx, training.Id, training)
+	require.NoError(t, err)
+
+	results, err := a.SetResults(ctx, training.Sets[0].Id)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
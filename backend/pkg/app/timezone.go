@@ -0,0 +1,38 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// location returns the IANA timezone tz, or the default timezone of the app
+// when tz isn't set.
+func (app SwimLogsApp) location(tz *string) (*time.Location, error) {
+	if tz == nil || *tz == "" {
+		return app.loc, nil
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, *tz)
+	}
+	return loc, nil
+}
+
+// weekRange returns start of Monday of the week in which is t in loc, and
+// start of the next Monday.
+func weekRange(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// dateRange returns start of the day from and start of the day after to,
+// with both days taken as calendar dates in loc.
+func dateRange(from, to time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc)
+	return start, end
+}
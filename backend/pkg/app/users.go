@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var ErrInvalidUser = errors.New("invalid user")
+
+func (app SwimLogsApp) CreateUser(ctx context.Context, newUser apidef.NewUser) (apidef.User, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.CreateUser")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	name := strings.TrimSpace(newUser.Name)
+	if name == "" {
+		return apidef.User{}, fmt.Errorf("CreateUser: %w: name must not be empty", ErrInvalidUser)
+	}
+
+	u, err := app.repo.PersistUser(ctx, data.User{Id: uuid.New(), Name: name})
+	if err != nil {
+		return apidef.User{}, fmt.Errorf("CreateUser: %w", err)
+	}
+
+	return dataUserToApiUser(u), nil
+}
+
+func (app SwimLogsApp) Users(ctx context.Context) ([]apidef.User, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.Users")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	dataUsers, err := app.repo.Users(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Users: %w", err)
+	}
+
+	users := make([]apidef.User, len(dataUsers))
+	for i, u := range dataUsers {
+		users[i] = dataUserToApiUser(u)
+	}
+	return users, nil
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/fit"
+)
+
+type WorkoutFile struct {
+	Filename string
+	Content  []byte
+}
+
+// TrainingWorkout exports training with id as a FIT structured workout for
+// swimming watches.
+func (app SwimLogsApp) TrainingWorkout(ctx context.Context, id uuid.UUID) (WorkoutFile, error) {
+	ctx, span := tracer.Start(ctx, "SwimLogsApp.TrainingWorkout")
+	defer span.End()
+
+	ctx, cancel := app.dbContext(ctx)
+	defer cancel()
+
+	t, err := app.repo.Training(ctx, id)
+	if errors.Is(err, data.ErrRowsNotFound) {
+		return WorkoutFile{}, fmt.Errorf("TrainingWorkout: %w", ErrNotFound)
+	} else if err != nil {
+		return WorkoutFile{}, fmt.Errorf("TrainingWorkout: %w", err)
+	}
+
+	content, err := fit.Workout(t, trainingTitle(t.Start, app.loc))
+	if err != nil {
+		return WorkoutFile{}, fmt.Errorf("TrainingWorkout: %w", err)
+	}
+
+	filename := fmt.Sprintf("training-%s.fit", t.Start.In(app.loc).Format("2006-01-02-1504"))
+	return WorkoutFile{Filename: filename, Content: content}, nil
+}
@@ -0,0 +1,416 @@
+// Package backup is the format of swimlogs backups, a versioned JSON
+// archive of everything stored. The archive starts with its format, version
+// and counts of entities it contains, followed by one array per entity.
+// Sets are nested in their trainings.
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/runtime/types"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+const (
+	Format = "swimlogs-backup"
+	// Version of the archive, increased on every change of its entities.
+	// Archives of newer versions than this can't be read.
+	Version = 1
+)
+
+var (
+	ErrInvalid            = errors.New("invalid backup")
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+)
+
+// Contents counts entities in an archive.
+type Contents struct {
+	Users       int `json:"users"`
+	Trainings   int `json:"trainings"`
+	Sets        int `json:"sets"`
+	SetResults  int `json:"setResults"`
+	RaceResults int `json:"raceResults"`
+	CssTests    int `json:"cssTests"`
+	Attendance  int `json:"attendance"`
+}
+
+func contentsOf(s data.Snapshot) Contents {
+	return Contents{
+		Users:       len(s.Users),
+		Trainings:   len(s.Trainings),
+		Sets:        len(s.Sets),
+		SetResults:  len(s.SetResults),
+		RaceResults: len(s.RaceResults),
+		CssTests:    len(s.CssTests),
+		Attendance:  len(s.Attendance),
+	}
+}
+
+type header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Contents  Contents  `json:"contents"`
+}
+
+type archive struct {
+	header
+	Users       []user       `json:"users"`
+	Trainings   []training   `json:"trainings"`
+	SetResults  []setResult  `json:"setResults"`
+	RaceResults []raceResult `json:"raceResults"`
+	CssTests    []cssTest    `json:"cssTests"`
+	Attendance  []attendance `json:"attendance"`
+}
+
+type user struct {
+	Id         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+type training struct {
+	Id            uuid.UUID `json:"id"`
+	Start         time.Time `json:"start"`
+	DurationMin   int       `json:"durationMin"`
+	TotalDistance int       `json:"totalDistance"`
+	PoolLength    int       `json:"poolLength"`
+	PoolUnit      string    `json:"poolUnit"`
+	Sets          []set     `json:"sets"`
+	CreatedAt     time.Time `json:"createdAt"`
+	ModifiedAt    time.Time `json:"modifiedAt"`
+}
+
+type set struct {
+	Id             uuid.UUID `json:"id"`
+	SetOrder       int       `json:"setOrder"`
+	Repeat         int       `json:"repeat"`
+	DistanceMeters int       `json:"distanceMeters"`
+	TotalDistance  int       `json:"totalDistance"`
+	StartType      string    `json:"startType"`
+	StartSeconds   *int      `json:"startSeconds,omitempty"`
+	Description    *string   `json:"description,omitempty"`
+	Equipment      *[]string `json:"equipment,omitempty"`
+	Group          *string   `json:"group,omitempty"`
+}
+
+type setResult struct {
+	SetId      uuid.UUID `json:"setId"`
+	UserId     uuid.UUID `json:"userId"`
+	TimesMs    []int     `json:"timesMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+type raceResult struct {
+	Id             uuid.UUID  `json:"id"`
+	UserId         uuid.UUID  `json:"userId"`
+	TrainingId     *uuid.UUID `json:"trainingId,omitempty"`
+	Distance       int        `json:"distance"`
+	Stroke         string     `json:"stroke"`
+	PoolLength     int        `json:"poolLength"`
+	PoolUnit       string     `json:"poolUnit"`
+	TimeMs         int        `json:"timeMs"`
+	Date           types.Date `json:"date"`
+	IsPersonalBest bool       `json:"isPersonalBest"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ModifiedAt     time.Time  `json:"modifiedAt"`
+}
+
+type cssTest struct {
+	Id         uuid.UUID  `json:"id"`
+	UserId     uuid.UUID  `json:"userId"`
+	Time400Ms  int        `json:"time400Ms"`
+	Time200Ms  int        `json:"time200Ms"`
+	Date       types.Date `json:"date"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ModifiedAt time.Time  `json:"modifiedAt"`
+}
+
+type attendance struct {
+	TrainingId      uuid.UUID `json:"trainingId"`
+	UserId          uuid.UUID `json:"userId"`
+	Status          string    `json:"status"`
+	MetersCompleted *int      `json:"metersCompleted,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	ModifiedAt      time.Time `json:"modifiedAt"`
+}
+
+// Write streams s as an archive to w, one entity at a time.
+func Write(w io.Writer, s data.Snapshot, createdAt time.Time) (Contents, error) {
+	contents := contentsOf(s)
+	sw := &streamWriter{w: bufio.NewWriter(w)}
+
+	h, err := json.Marshal(header{Format, Version, createdAt, contents})
+	if err != nil {
+		return Contents{}, fmt.Errorf("Write header: %w", err)
+	}
+	// the object is left open for entity arrays
+	sw.write(h[:len(h)-1])
+
+	setsByTraining := make(map[uuid.UUID][]set, len(s.Trainings))
+	for _, ts := range s.Sets {
+		setsByTraining[ts.TrainingId] = append(setsByTraining[ts.TrainingId], toSet(ts))
+	}
+
+	writeArray(sw, "users", s.Users, toUser)
+	writeArray(sw, "trainings", s.Trainings, func(t data.Training) training {
+		return toTraining(t, setsByTraining[t.Id])
+	})
+	writeArray(sw, "setResults", s.SetResults, toSetResult)
+	writeArray(sw, "raceResults", s.RaceResults, toRaceResult)
+	writeArray(sw, "cssTests", s.CssTests, toCssTest)
+	writeArray(sw, "attendance", s.Attendance, toAttendance)
+	sw.write([]byte("}\n"))
+
+	if sw.err == nil {
+		sw.err = sw.w.Flush()
+	}
+	if sw.err != nil {
+		return Contents{}, fmt.Errorf("Write: %w", sw.err)
+	}
+	return contents, nil
+}
+
+// streamWriter remembers the first error, so that writes can be chained
+// and checked once.
+type streamWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (sw *streamWriter) write(b []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(b)
+	}
+}
+
+func writeArray[T, A any](sw *streamWriter, name string, rows []T, convert func(T) A) {
+	sw.write([]byte(fmt.Sprintf(",\n%q:[", name)))
+	for i, row := range rows {
+		if i > 0 {
+			sw.write([]byte(","))
+		}
+		sw.write([]byte("\n"))
+
+		b, err := json.Marshal(convert(row))
+		if err != nil && sw.err == nil {
+			sw.err = fmt.Errorf("%s %d: %w", name, i, err)
+		}
+		sw.write(b)
+	}
+	sw.write([]byte("]"))
+}
+
+// Read reads an archive from r, which must be of a version up to Version.
+func Read(r io.Reader) (data.Snapshot, Contents, error) {
+	var a archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return data.Snapshot{}, Contents{}, fmt.Errorf("Read: %w: %w", ErrInvalid, err)
+	}
+
+	if a.Format != Format {
+		return data.Snapshot{}, Contents{}, fmt.Errorf("Read: %w: format %q isn't %q", ErrInvalid, a.Format, Format)
+	} else if a.Version < 1 {
+		return data.Snapshot{}, Contents{}, fmt.Errorf("Read: %w: version %d", ErrInvalid, a.Version)
+	} else if a.Version > Version {
+		return data.Snapshot{}, Contents{}, fmt.Errorf(
+			"Read: %w: %w: version %d is newer than %d",
+			ErrInvalid,
+			ErrUnsupportedVersion,
+			a.Version,
+			Version,
+		)
+	}
+
+	s := data.Snapshot{
+		Users:       convertAll(a.Users, fromUser),
+		Trainings:   convertAll(a.Trainings, fromTraining),
+		Sets:        make([]data.TrainingSet, 0),
+		SetResults:  convertAll(a.SetResults, fromSetResult),
+		RaceResults: convertAll(a.RaceResults, fromRaceResult),
+		CssTests:    convertAll(a.CssTests, fromCssTest),
+		Attendance:  convertAll(a.Attendance, fromAttendance),
+	}
+	for _, t := range a.Trainings {
+		for _, ts := range t.Sets {
+			s.Sets = append(s.Sets, fromSet(ts, t.Id))
+		}
+	}
+
+	if contents := contentsOf(s); contents != a.Contents {
+		return data.Snapshot{}, Contents{}, fmt.Errorf(
+			"Read: %w: contains %+v, but its contents are %+v",
+			ErrInvalid,
+			contents,
+			a.Contents,
+		)
+	}
+	return s, a.Contents, nil
+}
+
+func convertAll[A, T any](all []A, convert func(A) T) []T {
+	converted := make([]T, len(all))
+	for i, a := range all {
+		converted[i] = convert(a)
+	}
+	return converted
+}
+
+func toUser(u data.User) user {
+	return user{u.Id, u.Name, u.CreatedAt, u.ModifiedAt}
+}
+
+func fromUser(u user) data.User {
+	return data.User{Id: u.Id, Name: u.Name, CreatedAt: u.CreatedAt, ModifiedAt: u.ModifiedAt}
+}
+
+func toTraining(t data.Training, sets []set) training {
+	if sets == nil {
+		sets = make([]set, 0)
+	}
+	return training{
+		Id:            t.Id,
+		Start:         t.Start,
+		DurationMin:   t.DurationMin,
+		TotalDistance: t.TotalDistance,
+		PoolLength:    t.PoolLength,
+		PoolUnit:      t.PoolUnit,
+		Sets:          sets,
+		CreatedAt:     t.CreatedAt,
+		ModifiedAt:    t.ModifiedAt,
+	}
+}
+
+func fromTraining(t training) data.Training {
+	return data.Training{
+		Id:            t.Id,
+		Start:         t.Start,
+		DurationMin:   t.DurationMin,
+		TotalDistance: t.TotalDistance,
+		PoolLength:    t.PoolLength,
+		PoolUnit:      t.PoolUnit,
+		CreatedAt:     t.CreatedAt,
+		ModifiedAt:    t.ModifiedAt,
+	}
+}
+
+func toSet(s data.TrainingSet) set {
+	return set{
+		Id:             s.Id,
+		SetOrder:       s.SetOrder,
+		Repeat:         s.Repeat,
+		DistanceMeters: s.DistanceMeters,
+		TotalDistance:  s.TotalDistance,
+		StartType:      s.StartType,
+		StartSeconds:   s.StartSeconds,
+		Description:    s.Description,
+		Equipment:      s.Equipment,
+		Group:          s.Group,
+	}
+}
+
+func fromSet(s set, trainingId uuid.UUID) data.TrainingSet {
+	return data.TrainingSet{
+		Id:             s.Id,
+		TrainingId:     trainingId,
+		SetOrder:       s.SetOrder,
+		Repeat:         s.Repeat,
+		DistanceMeters: s.DistanceMeters,
+		TotalDistance:  s.TotalDistance,
+		StartType:      s.StartType,
+		StartSeconds:   s.StartSeconds,
+		Description:    s.Description,
+		Equipment:      s.Equipment,
+		Group:          s.Group,
+	}
+}
+
+func toSetResult(r data.SetResult) setResult {
+	return setResult{r.SetId, r.UserId, r.TimesMs, r.CreatedAt, r.ModifiedAt}
+}
+
+func fromSetResult(r setResult) data.SetResult {
+	return data.SetResult{
+		SetId:      r.SetId,
+		UserId:     r.UserId,
+		TimesMs:    r.TimesMs,
+		CreatedAt:  r.CreatedAt,
+		ModifiedAt: r.ModifiedAt,
+	}
+}
+
+func toRaceResult(r data.RaceResult) raceResult {
+	return raceResult{
+		Id:             r.Id,
+		UserId:         r.UserId,
+		TrainingId:     r.TrainingId,
+		Distance:       r.Distance,
+		Stroke:         r.Stroke,
+		PoolLength:     r.PoolLength,
+		PoolUnit:       r.PoolUnit,
+		TimeMs:         r.TimeMs,
+		Date:           types.Date{Time: r.Date},
+		IsPersonalBest: r.IsPersonalBest,
+		CreatedAt:      r.CreatedAt,
+		ModifiedAt:     r.ModifiedAt,
+	}
+}
+
+// fromRaceResult keeps IsPersonalBest only for completeness, repositories
+// derive it on read.
+func fromRaceResult(r raceResult) data.RaceResult {
+	return data.RaceResult{
+		Id:             r.Id,
+		UserId:         r.UserId,
+		TrainingId:     r.TrainingId,
+		Distance:       r.Distance,
+		Stroke:         r.Stroke,
+		PoolLength:     r.PoolLength,
+		PoolUnit:       r.PoolUnit,
+		TimeMs:         r.TimeMs,
+		Date:           r.Date.Time,
+		IsPersonalBest: r.IsPersonalBest,
+		CreatedAt:      r.CreatedAt,
+		ModifiedAt:     r.ModifiedAt,
+	}
+}
+
+func toCssTest(c data.CssTest) cssTest {
+	return cssTest{c.Id, c.UserId, c.Time400Ms, c.Time200Ms, types.Date{Time: c.Date}, c.CreatedAt, c.ModifiedAt}
+}
+
+func fromCssTest(c cssTest) data.CssTest {
+	return data.CssTest{
+		Id:         c.Id,
+		UserId:     c.UserId,
+		Time400Ms:  c.Time400Ms,
+		Time200Ms:  c.Time200Ms,
+		Date:       c.Date.Time,
+		CreatedAt:  c.CreatedAt,
+		ModifiedAt: c.ModifiedAt,
+	}
+}
+
+func toAttendance(a data.Attendance) attendance {
+	return attendance{a.TrainingId, a.UserId, a.Status, a.MetersCompleted, a.CreatedAt, a.ModifiedAt}
+}
+
+func fromAttendance(a attendance) data.Attendance {
+	return data.Attendance{
+		TrainingId:      a.TrainingId,
+		UserId:          a.UserId,
+		Status:          a.Status,
+		MetersCompleted: a.MetersCompleted,
+		CreatedAt:       a.CreatedAt,
+		ModifiedAt:      a.ModifiedAt,
+	}
+}
@@ -0,0 +1,206 @@
+// Package cli is swimlogs-cli, a command-line client of the swimlogs API.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+const (
+	ServerEnvVar = "SWIMLOGS_SERVER"
+	OutputEnvVar = "SWIMLOGS_OUTPUT"
+	TZEnvVar     = "SWIMLOGS_TZ"
+	EditorEnvVar = "EDITOR"
+
+	DefaultServer = "http://localhost:42069"
+	DefaultEditor = "vi"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+const usage = `Usage: swimlogs-cli [flags] command [args]
+
+Commands:
+  details [-page N] [-size N]   list trainings, newest first
+  week                          list trainings of the current week
+  show ID                       show a training with its sets
+  create FILE                   create a training from a YAML or JSON file
+                                with fields of the API's NewTraining, sets
+                                are ordered as listed, "-" reads stdin
+  edit ID                       edit a training as YAML in $EDITOR
+  delete ID                     delete a training
+
+Flags:
+`
+
+var (
+	errUsage = errors.New("invalid usage")
+	// errNoChange is returned by edit when the edited training wasn't
+	// changed, nothing is sent to the server
+	errNoChange = errors.New("training not changed")
+)
+
+// Env is what the cli uses from its process.
+type Env struct {
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	LookupEnv func(string) (string, bool)
+	// HTTPClient sends requests to the server, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// OSEnv is Env of the running process.
+func OSEnv() Env {
+	return Env{
+		Stdin:     os.Stdin,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		LookupEnv: os.LookupEnv,
+	}
+}
+
+// cli is one run of swimlogs-cli.
+type cli struct {
+	env    Env
+	client apidef.ClientWithResponsesInterface
+	output string
+	// tz is the timezone of the current week and shown times, when empty
+	// the server decides the week and times are local
+	tz  string
+	loc *time.Location
+}
+
+// Run runs swimlogs-cli with args after the program name and returns exit
+// code.
+func Run(ctx context.Context, args []string, env Env) int {
+	c, command, err := newCli(args, env)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		fmt.Fprintf(env.Stderr, "swimlogs-cli: %s\n", err)
+		return 2
+	}
+
+	err = c.run(ctx, command[0], command[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(env.Stderr, "swimlogs-cli: %s, run swimlogs-cli -h for usage\n", err)
+		return 2
+	} else if errors.Is(err, errNoChange) {
+		fmt.Fprintln(env.Stderr, "swimlogs-cli: training not changed, nothing to save")
+		return 0
+	} else if err != nil {
+		fmt.Fprintf(env.Stderr, "swimlogs-cli: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func newCli(args []string, env Env) (*cli, []string, error) {
+	getenv := func(key, fallback string) string {
+		if v, ok := env.LookupEnv(key); ok && v != "" {
+			return v
+		}
+		return fallback
+	}
+
+	fs := flag.NewFlagSet("swimlogs-cli", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.Usage = func() {
+		fmt.Fprint(env.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	server := fs.String(
+		"server",
+		getenv(ServerEnvVar, DefaultServer),
+		"url of the swimlogs API, env "+ServerEnvVar,
+	)
+	output := fs.String(
+		"output",
+		getenv(OutputEnvVar, OutputTable),
+		"output format, table or json, env "+OutputEnvVar,
+	)
+	tz := fs.String(
+		"tz",
+		getenv(TZEnvVar, ""),
+		"IANA timezone of the current week and shown times, env "+TZEnvVar+" (default local)",
+	)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, nil, errUsage
+	}
+	if *output != OutputTable && *output != OutputJSON {
+		return nil, nil, fmt.Errorf("unknown output %q, must be %s or %s", *output, OutputTable, OutputJSON)
+	}
+
+	loc := time.Local
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var httpClient apidef.HttpRequestDoer = http.DefaultClient
+	if env.HTTPClient != nil {
+		httpClient = env.HTTPClient
+	}
+	client, err := apidef.NewClientWithResponses(
+		strings.TrimSuffix(*server, "/"),
+		apidef.WithHTTPClient(httpClient),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &cli{env: env, client: client, output: *output, tz: *tz, loc: loc}, fs.Args(), nil
+}
+
+func (c *cli) run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "details":
+		return c.details(ctx, args)
+	case "week":
+		return c.week(ctx, args)
+	case "show":
+		return c.show(ctx, args)
+	case "create":
+		return c.create(ctx, args)
+	case "edit":
+		return c.edit(ctx, args)
+	case "delete":
+		return c.delete(ctx, args)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+}
+
+// responseError describes a response with an unexpected status, with the
+// error detail from its body if the server sent one.
+func responseError(res *http.Response, body []byte) error {
+	var detail apidef.ErrorDetail
+	if err := json.Unmarshal(body, &detail); err == nil && detail.Title != "" {
+		return fmt.Errorf("%s: %s, %s", res.Status, detail.Title, detail.Detail)
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		return fmt.Errorf("%s: %s", res.Status, text)
+	}
+	return errors.New(res.Status)
+}
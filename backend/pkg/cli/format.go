@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+const timeFormat = "Mon 2006-01-02 15:04"
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("writeJSON: %w", err)
+	}
+	return nil
+}
+
+func (c *cli) writeDetails(details []apidef.TrainingDetail) error {
+	if len(details) == 0 {
+		_, err := fmt.Fprintln(c.env.Stdout, "no trainings")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(c.env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTART\tDURATION\tDISTANCE\tPOOL")
+	for _, d := range details {
+		fmt.Fprintf(
+			tw,
+			"%s\t%s\t%d min\t%s\t%s\n",
+			d.Id,
+			d.Start.In(c.loc).Format(timeFormat),
+			d.DurationMin,
+			distance(d.TotalDistance, d.TotalDistanceYards, d.Pool),
+			pool(d.Pool),
+		)
+	}
+	return tw.Flush()
+}
+
+// writeDetail writes result of a create or edit.
+func (c *cli) writeDetail(action string, d apidef.TrainingDetail) error {
+	if c.output == OutputJSON {
+		return writeJSON(c.env.Stdout, d)
+	}
+	_, err := fmt.Fprintf(
+		c.env.Stdout,
+		"%s training %s, %s, %d min, %s\n",
+		action,
+		d.Id,
+		d.Start.In(c.loc).Format(timeFormat),
+		d.DurationMin,
+		distance(d.TotalDistance, d.TotalDistanceYards, d.Pool),
+	)
+	return err
+}
+
+func (c *cli) writeTraining(t apidef.Training) error {
+	p := apidef.Pool{Length: 25, Unit: apidef.Meters}
+	if t.Pool != nil {
+		p = *t.Pool
+	}
+	yards := 0
+	if t.TotalDistanceYards != nil {
+		yards = *t.TotalDistanceYards
+	}
+
+	fmt.Fprintf(c.env.Stdout, "Training %s\n", t.Id)
+	fmt.Fprintf(c.env.Stdout, "Start     %s\n", t.Start.In(c.loc).Format(timeFormat))
+	fmt.Fprintf(c.env.Stdout, "Duration  %d min\n", t.DurationMin)
+	fmt.Fprintf(c.env.Stdout, "Distance  %s\n", distance(t.TotalDistance, yards, p))
+	fmt.Fprintf(c.env.Stdout, "Pool      %s\n\n", pool(p))
+
+	tw := tabwriter.NewWriter(c.env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSET\tTOTAL\tSTART\tEQUIPMENT\tGROUP\tDESCRIPTION")
+	for i, s := range t.Sets {
+		setYards, repeatYards := 0, 0
+		if s.TotalDistanceYards != nil {
+			setYards = *s.TotalDistanceYards
+		}
+		if s.DistanceYards != nil {
+			repeatYards = *s.DistanceYards
+		}
+		repeat := distance(s.DistanceMeters, repeatYards, p)
+		if s.Repeat > 1 {
+			repeat = fmt.Sprintf("%dx%s", s.Repeat, repeat)
+		}
+
+		fmt.Fprintf(
+			tw,
+			"%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			i+1,
+			repeat,
+			distance(s.TotalDistance, setYards, p),
+			start(s.StartType, s.StartSeconds),
+			equipment(s.Equipment),
+			orDash((*string)(s.Group)),
+			orDash(s.Description),
+		)
+	}
+	return tw.Flush()
+}
+
+// distance formats distance in unit of pool p.
+func distance(meters, yards int, p apidef.Pool) string {
+	if p.Unit == apidef.Yards {
+		return fmt.Sprintf("%dy", yards)
+	}
+	return fmt.Sprintf("%dm", meters)
+}
+
+func pool(p apidef.Pool) string {
+	return fmt.Sprintf("%d %s", p.Length, p.Unit)
+}
+
+func start(startType apidef.StartTypeEnum, seconds *int) string {
+	if startType == apidef.None || seconds == nil {
+		return "-"
+	}
+	d := time.Duration(*seconds) * time.Second
+	clock := fmt.Sprintf("%d:%02d", int(d.Minutes()), *seconds%60)
+	if startType == apidef.Interval {
+		return "@" + clock
+	}
+	return clock + " rest"
+}
+
+func equipment(e *[]apidef.EquipmentEnum) string {
+	if e == nil || len(*e) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(*e))
+	for _, item := range *e {
+		names = append(names, string(item))
+	}
+	return strings.Join(names, ", ")
+}
+
+func orDash(s *string) string {
+	if s == nil || *s == "" {
+		return "-"
+	}
+	return *s
+}
+
+// encodeYAML encodes v as block style YAML with fields named and ordered
+// as in its JSON.
+func encodeYAML(v any) ([]byte, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encodeYAML: %w", err)
+	}
+
+	// JSON is YAML, decoding it into a node keeps order of fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(j, &node); err != nil {
+		return nil, fmt.Errorf("encodeYAML: %w", err)
+	}
+	clearStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("encodeYAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// clearStyle makes node and its children use the default block style,
+// scalars are still quoted when needed to keep their type.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// decodeYAML decodes YAML, or JSON, content into v according to v's JSON
+// field names, unknown fields are an error.
+func decodeYAML(content []byte, v any) error {
+	var raw any
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return fmt.Errorf("decodeYAML: %w", err)
+	}
+	j, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("decodeYAML: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decodeYAML: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+func (c *cli) details(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("details", flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	page := fs.Int("page", 0, "which page to list, starts at 0")
+	size := fs.Int("size", 20, "how many trainings are on a page")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: details takes no arguments", errUsage)
+	}
+	if *page < 0 || *size < 1 {
+		return fmt.Errorf("%w: page must be at least 0 and size at least 1", errUsage)
+	}
+
+	res, err := c.client.TrainingDetailsWithResponse(
+		ctx,
+		&apidef.TrainingDetailsParams{Page: *page, PageSize: *size},
+	)
+	if err != nil {
+		return fmt.Errorf("details: %w", err)
+	} else if res.JSON200 == nil {
+		return fmt.Errorf("details: %w", responseError(res.HTTPResponse, res.Body))
+	}
+
+	if c.output == OutputJSON {
+		return writeJSON(c.env.Stdout, res.JSON200)
+	}
+	if err := c.writeDetails(res.JSON200.Details); err != nil {
+		return fmt.Errorf("details: %w", err)
+	}
+	if len(res.JSON200.Details) == 0 {
+		return nil
+	}
+	// pagination's page size is of the returned page, which can be shorter
+	p := res.JSON200.Pagination
+	pages := (p.Total + *size - 1) / *size
+	_, err = fmt.Fprintf(c.env.Stdout, "\npage %d of %d, %d trainings\n", p.Page+1, pages, p.Total)
+	return err
+}
+
+func (c *cli) week(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("%w: week takes no arguments", errUsage)
+	}
+
+	params := &apidef.TrainingDetailsCurrentWeekParams{}
+	if c.tz != "" {
+		params.Tz = &c.tz
+	}
+	res, err := c.client.TrainingDetailsCurrentWeekWithResponse(ctx, params)
+	if err != nil {
+		return fmt.Errorf("week: %w", err)
+	} else if res.JSON200 == nil {
+		return fmt.Errorf("week: %w", responseError(res.HTTPResponse, res.Body))
+	}
+
+	if c.output == OutputJSON {
+		return writeJSON(c.env.Stdout, res.JSON200)
+	}
+	if err := c.writeDetails(res.JSON200.Details); err != nil {
+		return fmt.Errorf("week: %w", err)
+	}
+	return nil
+}
+
+func (c *cli) show(ctx context.Context, args []string) error {
+	id, err := idArg("show", args)
+	if err != nil {
+		return err
+	}
+
+	t, err := c.training(ctx, id)
+	if err != nil {
+		return fmt.Errorf("show: %w", err)
+	}
+
+	if c.output == OutputJSON {
+		return writeJSON(c.env.Stdout, t)
+	}
+	if err := c.writeTraining(t); err != nil {
+		return fmt.Errorf("show: %w", err)
+	}
+	return nil
+}
+
+func (c *cli) create(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: create takes a file", errUsage)
+	}
+
+	var content []byte
+	var err error
+	if args[0] == "-" {
+		content, err = io.ReadAll(c.env.Stdin)
+	} else {
+		content, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	var training apidef.CreateTrainingRequest
+	if err := decodeYAML(content, &training); err != nil {
+		return fmt.Errorf("create: decoding %s: %w", args[0], err)
+	}
+	// sets are ordered as listed
+	for i := range training.Sets {
+		training.Sets[i].SetOrder = i
+	}
+
+	res, err := c.client.CreateTrainingWithResponse(ctx, training)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	} else if res.JSON201 == nil {
+		return fmt.Errorf("create: %w", responseError(res.HTTPResponse, res.Body))
+	}
+	return c.writeDetail("created", *res.JSON201)
+}
+
+// editHeader is at the top of the file opened in the editor
+const editHeader = `# Edit the training, then save and quit the editor. Sets are matched by id,
+# sets without an id are added and sets missing in the file are kept, set
+# order is taken from setOrder. Totals are recalculated by the server.
+# Saving the file unchanged cancels the edit.
+`
+
+func (c *cli) edit(ctx context.Context, args []string) error {
+	id, err := idArg("edit", args)
+	if err != nil {
+		return err
+	}
+
+	t, err := c.training(ctx, id)
+	if err != nil {
+		return fmt.Errorf("edit: %w", err)
+	}
+	original, err := encodeYAML(t)
+	if err != nil {
+		return fmt.Errorf("edit: %w", err)
+	}
+	original = append([]byte(editHeader), original...)
+
+	edited, err := c.editInEditor(original)
+	if err != nil {
+		return fmt.Errorf("edit: %w", err)
+	}
+	if bytes.Equal(original, edited) {
+		return errNoChange
+	}
+
+	var training apidef.EditTrainingRequest
+	if err := decodeYAML(edited, &training); err != nil {
+		return fmt.Errorf("edit: decoding edited training: %w", err)
+	}
+	training.Id = id
+	for i := range training.Sets {
+		if training.Sets[i].Id == uuid.Nil {
+			training.Sets[i].Id = uuid.New()
+		}
+	}
+
+	res, err := c.client.EditTrainingWithResponse(ctx, id, training)
+	if err != nil {
+		return fmt.Errorf("edit: %w", err)
+	} else if res.JSON200 == nil {
+		return fmt.Errorf("edit: %w", responseError(res.HTTPResponse, res.Body))
+	}
+	return c.writeDetail("edited", *res.JSON200)
+}
+
+// editInEditor lets the user edit content in $EDITOR and returns the
+// edited content.
+func (c *cli) editInEditor(content []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "swimlogs-cli")
+	if err != nil {
+		return nil, fmt.Errorf("editInEditor: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "training.yaml")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return nil, fmt.Errorf("editInEditor: %w", err)
+	}
+
+	editor := DefaultEditor
+	if e, ok := c.env.LookupEnv(EditorEnvVar); ok && strings.TrimSpace(e) != "" {
+		editor = e
+	}
+	// $EDITOR can contain arguments, like "code --wait"
+	command := append(strings.Fields(editor), path)
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = c.env.Stdin
+	cmd.Stdout = c.env.Stdout
+	cmd.Stderr = c.env.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editInEditor running %s: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("editInEditor: %w", err)
+	}
+	return edited, nil
+}
+
+func (c *cli) delete(ctx context.Context, args []string) error {
+	id, err := idArg("delete", args)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.DeleteTrainingWithResponse(ctx, id)
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	} else if res.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("delete: %w", responseError(res.HTTPResponse, res.Body))
+	}
+
+	if c.output == OutputJSON {
+		return writeJSON(c.env.Stdout, map[string]uuid.UUID{"deleted": id})
+	}
+	_, err = fmt.Fprintf(c.env.Stdout, "deleted training %s\n", id)
+	return err
+}
+
+func (c *cli) training(ctx context.Context, id uuid.UUID) (apidef.Training, error) {
+	res, err := c.client.TrainingWithResponse(ctx, id, &apidef.TrainingParams{})
+	if err != nil {
+		return apidef.Training{}, fmt.Errorf("training: %w", err)
+	} else if res.JSON200 == nil {
+		return apidef.Training{}, fmt.Errorf("training: %w", responseError(res.HTTPResponse, res.Body))
+	}
+	return *res.JSON200, nil
+}
+
+func idArg(command string, args []string) (uuid.UUID, error) {
+	if len(args) != 1 {
+		return uuid.Nil, fmt.Errorf("%w: %s takes a training id", errUsage, command)
+	}
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid training id %q", errUsage, args[0])
+	}
+	return id, nil
+}
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+	"github.com/Nesquiko/swimlogs/pkg/tracing"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+
+	redacted = "REDACTED"
+
+	minAdminTokenLen = 16
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Config is everything the server can be configured with. It is loaded by
+// Load from a yaml file, environment variables and flags, each overriding
+// the previous one.
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Log      Log      `yaml:"log"`
+	Database Database `yaml:"database"`
+	Limits   Limits   `yaml:"limits"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Cors     Cors     `yaml:"cors"`
+	Admin    Admin    `yaml:"admin"`
+
+	// TZ is the default timezone of day and week boundaries for requests
+	// which don't specify one
+	TZ string `yaml:"tz"`
+
+	// PrintConfig only prints the effective config and exits
+	PrintConfig bool `yaml:"-"`
+	// Args are the arguments left after flags
+	Args []string `yaml:"-"`
+}
+
+type Server struct {
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"readTimeout"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// DrainDelay is how long the server keeps serving after readiness turns
+	// to 503 on shutdown, so load balancers stop routing to it first
+	DrainDelay time.Duration `yaml:"drainDelay"`
+}
+
+type Log struct {
+	// Level is zerolog level, trace = -1, debug = 0, info = 1, warn = 2,
+	// error = 3, fatal = 4, panic = 5
+	Level int  `yaml:"level"`
+	JSON  bool `yaml:"json"`
+}
+
+type Database struct {
+	Driver string `yaml:"driver"`
+	// Path is the sqlite database file
+	Path string `yaml:"path"`
+
+	// URL is the postgres connection string, when set Host, Port, Name,
+	// User and Password are ignored
+	URL      string `yaml:"url"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Name     string `yaml:"name"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+
+	// Timeout limits all database queries made while handling one request,
+	// zero disables it
+	Timeout time.Duration `yaml:"timeout"`
+
+	// MigrationsDir is a directory with migrations of the driver, used
+	// instead of the ones bundled in the binary
+	MigrationsDir string `yaml:"migrationsDir"`
+	// SkipMigrations starts the server without migrating the database up,
+	// when migrations are run separately by the migrate command
+	SkipMigrations bool `yaml:"skipMigrations"`
+}
+
+// ConnectionString is the postgres connection string.
+func (db Database) ConnectionString() string {
+	if db.URL != "" {
+		return db.URL
+	}
+	return data.ConnectionString(db.User, db.Password, db.Host, db.Name, db.Port)
+}
+
+type Limit struct {
+	Rate         float64 `yaml:"rate"`
+	Burst        int     `yaml:"burst"`
+	MaxBodyBytes int64   `yaml:"maxBodyBytes"`
+}
+
+type Limits struct {
+	Default Limit `yaml:"default"`
+	// Operations are limits by OpenAPI operation id, capitalized as in
+	// server.OperationIds
+	Operations map[string]Limit `yaml:"operations"`
+	// TrustedProxies are IP addresses or CIDR ranges of reverse proxies
+	// allowed to set the client address with X-Real-Ip
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+// ServerLimits converts limits to the ones used by server. Limits must be
+// valid.
+func (l Limits) ServerLimits() server.Limits {
+	limits := server.Limits{
+		Default:    l.Default.serverLimit(),
+		Operations: make(map[string]server.Limit, len(l.Operations)),
+	}
+	for op, ol := range l.Operations {
+		limits.Operations[op] = ol.serverLimit()
+	}
+	for _, proxy := range l.TrustedProxies {
+		if prefix, err := parsePrefix(proxy); err == nil {
+			limits.TrustedProxies = append(limits.TrustedProxies, prefix)
+		}
+	}
+	return limits
+}
+
+// parsePrefix parses CIDR range, or an IP address as a range of only it.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
+
+func (l Limit) serverLimit() server.Limit {
+	return server.Limit{Rate: rate.Limit(l.Rate), Burst: l.Burst, MaxBytes: l.MaxBodyBytes}
+}
+
+func limit(l server.Limit) Limit {
+	return Limit{Rate: float64(l.Rate), Burst: l.Burst, MaxBodyBytes: l.MaxBytes}
+}
+
+type Cors struct {
+	// Origins allowed to make requests, * allows any origin
+	Origins          []string      `yaml:"origins"`
+	Methods          []string      `yaml:"methods"`
+	Headers          []string      `yaml:"headers"`
+	AllowCredentials bool          `yaml:"allowCredentials"`
+	MaxAge           time.Duration `yaml:"maxAge"`
+}
+
+// ServerCors converts c to the CORS options used by server.
+func (c Cors) ServerCors() server.Cors {
+	return server.Cors{
+		Origins:          slices.Clone(c.Origins),
+		Methods:          slices.Clone(c.Methods),
+		Headers:          slices.Clone(c.Headers),
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+}
+
+type Admin struct {
+	// Token authorizes requests to admin endpoints, and to metrics, as a
+	// bearer token, when empty they are disabled
+	Token string `yaml:"token"`
+}
+
+type Tracing struct {
+	// Exporter is none, otlp or stdout
+	Exporter string `yaml:"exporter"`
+	// Endpoint is url of the OTLP http collector
+	Endpoint string `yaml:"endpoint"`
+}
+
+func Default() Config {
+	operations := make(map[string]Limit, len(server.DefaultLimits.Operations))
+	for op, l := range server.DefaultLimits.Operations {
+		operations[op] = limit(l)
+	}
+
+	return Config{
+		Server: Server{
+			Port:            "42069",
+			ReadTimeout:     5 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		Log: Log{Level: 1},
+		Database: Database{
+			Driver:  DriverPostgres,
+			Path:    "swimlogs.db",
+			Timeout: 5 * time.Second,
+		},
+		Limits: Limits{
+			Default:    limit(server.DefaultLimits.Default),
+			Operations: operations,
+		},
+		Tracing: Tracing{Exporter: tracing.ExporterNone},
+		Cors: Cors{
+			Methods:          slices.Clone(server.DefaultCors.Methods),
+			Headers:          slices.Clone(server.DefaultCors.Headers),
+			AllowCredentials: server.DefaultCors.AllowCredentials,
+			MaxAge:           server.DefaultCors.MaxAge,
+		},
+	}
+}
+
+// Validate checks that the server can start with c.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		invalid("port %q must be a number between 1 and 65535", c.Server.Port)
+	}
+	for name, d := range map[string]time.Duration{
+		"read timeout":     c.Server.ReadTimeout,
+		"write timeout":    c.Server.WriteTimeout,
+		"idle timeout":     c.Server.IdleTimeout,
+		"shutdown timeout": c.Server.ShutdownTimeout,
+		"drain delay":      c.Server.DrainDelay,
+		"database timeout": c.Database.Timeout,
+		"cors max age":     c.Cors.MaxAge,
+	} {
+		if d < 0 {
+			invalid("%s %s must not be negative", name, d)
+		}
+	}
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.Database.URL != "" {
+			u, err := url.Parse(c.Database.URL)
+			if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+				invalid("database url must be a postgres:// url")
+			}
+		} else if c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "" {
+			invalid("database url or host, name and user are required with postgres driver")
+		}
+	case DriverSqlite:
+		if c.Database.Path == "" {
+			invalid("database path is required with sqlite driver")
+		}
+	default:
+		invalid("unknown database driver %q", c.Database.Driver)
+	}
+
+	if _, err := time.LoadLocation(c.TZ); err != nil {
+		invalid("unknown timezone %q", c.TZ)
+	}
+
+	for _, origin := range c.Cors.Origins {
+		if origin == server.AnyOrigin {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			invalid("cors origin %q must be * or scheme and host, like https://www.swimlogs.com", origin)
+		}
+	}
+	if len(c.Cors.Methods) == 0 {
+		invalid("cors methods must not be empty")
+	}
+
+	operations, err := server.OperationIds()
+	if err != nil {
+		invalid("can't read operations of the API: %s", err)
+	}
+	for op, l := range c.Limits.Operations {
+		if !slices.Contains(operations, op) {
+			i := slices.IndexFunc(operations, func(id string) bool { return strings.EqualFold(id, op) })
+			if i != -1 {
+				invalid("unknown operation %q in limits, did you mean %q", op, operations[i])
+			} else {
+				invalid("unknown operation %q in limits, must be an operation id like CreateUser", op)
+			}
+		}
+		validateLimit(invalid, "limit of "+op, l)
+	}
+	validateLimit(invalid, "default limit", c.Limits.Default)
+	for _, proxy := range c.Limits.TrustedProxies {
+		if _, err := parsePrefix(proxy); err != nil {
+			invalid("trusted proxy %q must be an IP address or CIDR range", proxy)
+		}
+	}
+
+	if c.Admin.Token != "" && len(c.Admin.Token) < minAdminTokenLen {
+		invalid("admin token must have at least %d characters", minAdminTokenLen)
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOtlp:
+		if c.Tracing.Endpoint != "" {
+			if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Host == "" {
+				invalid("tracing endpoint %q must be an url", c.Tracing.Endpoint)
+			}
+		}
+	default:
+		invalid("unknown trace exporter %q", c.Tracing.Exporter)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateLimit(invalid func(string, ...any), name string, l Limit) {
+	if l.Rate < 0 || l.Burst < 0 {
+		invalid("%s must not have negative rate or burst", name)
+	}
+	if l.MaxBodyBytes <= 0 {
+		invalid("%s must have positive max body bytes", name)
+	}
+}
+
+// Redacted returns c with secrets replaced, safe to print.
+func (c Config) Redacted() Config {
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
+	}
+	if c.Admin.Token != "" {
+		c.Admin.Token = redacted
+	}
+	if u, err := url.Parse(c.Database.URL); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			c.Database.URL = u.String()
+		}
+	}
+	return c
+}
+
+// Yaml encodes c in the format of the config file.
+func (c Config) Yaml() ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("Yaml: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func loadFile(c *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("loadFile: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	// empty file is a valid config without any settings
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("loadFile %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ConfigEnvVar = "SWIMLOGS_CONFIG"
+
+	AppHostEnvVar       = "APP_HOST"
+	AppPortEnvVar       = "APP_PORT"
+	AppDebugLevelEnvVar = "APP_DEBUG_LEVEL"
+	AppJsonLogsEnvVar   = "APP_JSON_LOGS"
+
+	AppReadTimeoutEnvVar     = "APP_READ_TIMEOUT"
+	AppWriteTimeoutEnvVar    = "APP_WRITE_TIMEOUT"
+	AppIdleTimeoutEnvVar     = "APP_IDLE_TIMEOUT"
+	AppShutdownTimeoutEnvVar = "APP_SHUTDOWN_TIMEOUT"
+	AppDrainDelayEnvVar      = "APP_DRAIN_DELAY"
+
+	DbDriverEnvVar = "DATABASE_DRIVER"
+	DbPathEnvVar   = "DATABASE_PATH"
+
+	DbUrlEnvVar  = "DATABASE_URL"
+	DbHostEnvVar = "DATABASE_HOST"
+	DbPortEnvVar = "DATABASE_PORT"
+	DbNameEnvVar = "DATABASE_NAME"
+	DbUserEnvVar = "DATABASE_USER"
+	DbPassEnvVar = "DATABASE_PASSWORD"
+
+	DbTimeoutEnvVar = "DATABASE_TIMEOUT"
+
+	MigrationsDirEnvVar  = "MIGRATIONS_DIR"
+	SkipMigrationsEnvVar = "SKIP_MIGRATIONS"
+
+	TZEnvVar = "TZ"
+
+	FEOriginEnvVar             = "FE_ORIGIN"
+	CorsMethodsEnvVar          = "CORS_METHODS"
+	CorsHeadersEnvVar          = "CORS_HEADERS"
+	CorsAllowCredentialsEnvVar = "CORS_ALLOW_CREDENTIALS"
+	CorsMaxAgeEnvVar           = "CORS_MAX_AGE"
+
+	RateLimitEnvVar      = "RATE_LIMIT"
+	RateBurstEnvVar      = "RATE_BURST"
+	MaxBodyBytesEnvVar   = "MAX_BODY_BYTES"
+	TrustedProxiesEnvVar = "TRUSTED_PROXIES"
+
+	AdminTokenEnvVar = "ADMIN_TOKEN"
+
+	TraceExporterEnvVar = "TRACE_EXPORTER"
+	TraceEndpointEnvVar = "TRACE_ENDPOINT"
+)
+
+// setting is one config value which can be set by a flag and an environment
+// variable, field returns pointer to the value in config.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	field func(c *Config) any
+}
+
+var settings = []setting{
+	{"host", AppHostEnvVar, "application host", func(c *Config) any { return &c.Server.Host }},
+	{"port", AppPortEnvVar, "application port", func(c *Config) any { return &c.Server.Port }},
+	{
+		"debug-level",
+		AppDebugLevelEnvVar,
+		"application debug level (trace = -1, debug = 0, info = 1, warn = 2, error = 3, fatal = 4, panic = 5)",
+		func(c *Config) any { return &c.Log.Level },
+	},
+	{"json-logs", AppJsonLogsEnvVar, "whether to log in json format", func(c *Config) any { return &c.Log.JSON }},
+
+	{
+		"read-timeout",
+		AppReadTimeoutEnvVar,
+		"maximum duration for reading the entire request, including the body",
+		func(c *Config) any { return &c.Server.ReadTimeout },
+	},
+	{
+		"write-timeout",
+		AppWriteTimeoutEnvVar,
+		"maximum duration before timing out writes of the response",
+		func(c *Config) any { return &c.Server.WriteTimeout },
+	},
+	{
+		"idle-timeout",
+		AppIdleTimeoutEnvVar,
+		"maximum amount of time to wait for the next request when keep-alives are enabled",
+		func(c *Config) any { return &c.Server.IdleTimeout },
+	},
+	{
+		"shutdown-timeout",
+		AppShutdownTimeoutEnvVar,
+		"maximum amount of time to wait for in-flight requests to finish on shutdown",
+		func(c *Config) any { return &c.Server.ShutdownTimeout },
+	},
+	{
+		"drain-delay",
+		AppDrainDelayEnvVar,
+		"amount of time to keep serving after readiness reports shutting down",
+		func(c *Config) any { return &c.Server.DrainDelay },
+	},
+
+	{"db-driver", DbDriverEnvVar, "database driver, postgres or sqlite", func(c *Config) any { return &c.Database.Driver }},
+	{
+		"db-path",
+		DbPathEnvVar,
+		"path to the sqlite database file, used only with sqlite driver",
+		func(c *Config) any { return &c.Database.Path },
+	},
+	{
+		"db-url",
+		DbUrlEnvVar,
+		"postgres connection string, overrides db host, port, name, user and pass",
+		func(c *Config) any { return &c.Database.URL },
+	},
+	{"db-host", DbHostEnvVar, "db host", func(c *Config) any { return &c.Database.Host }},
+	{"db-port", DbPortEnvVar, "db port", func(c *Config) any { return &c.Database.Port }},
+	{"db-user", DbUserEnvVar, "user connecting to db", func(c *Config) any { return &c.Database.User }},
+	{"db-pass", DbPassEnvVar, "password for connecting to db", func(c *Config) any { return &c.Database.Password }},
+	{"db-name", DbNameEnvVar, "to which db to connnect", func(c *Config) any { return &c.Database.Name }},
+	{
+		"db-timeout",
+		DbTimeoutEnvVar,
+		"maximum duration of all database queries made while handling one request, 0 disables it",
+		func(c *Config) any { return &c.Database.Timeout },
+	},
+	{
+		"migrations-dir",
+		MigrationsDirEnvVar,
+		"directory with migrations of the db driver to use instead of the bundled ones",
+		func(c *Config) any { return &c.Database.MigrationsDir },
+	},
+	{
+		"skip-migrations",
+		SkipMigrationsEnvVar,
+		"start the server without migrating the database up",
+		func(c *Config) any { return &c.Database.SkipMigrations },
+	},
+
+	{"tz", TZEnvVar, "default timezone of day and week boundaries for requests without one", func(c *Config) any { return &c.TZ }},
+
+	{
+		"fe-origin",
+		FEOriginEnvVar,
+		"comma separated frontend origins allowed by CORS, * allows any origin",
+		func(c *Config) any { return &c.Cors.Origins },
+	},
+	{
+		"cors-methods",
+		CorsMethodsEnvVar,
+		"comma separated methods allowed by CORS",
+		func(c *Config) any { return &c.Cors.Methods },
+	},
+	{
+		"cors-headers",
+		CorsHeadersEnvVar,
+		"comma separated request headers allowed by CORS",
+		func(c *Config) any { return &c.Cors.Headers },
+	},
+	{
+		"cors-allow-credentials",
+		CorsAllowCredentialsEnvVar,
+		"whether CORS requests can include credentials",
+		func(c *Config) any { return &c.Cors.AllowCredentials },
+	},
+	{
+		"cors-max-age",
+		CorsMaxAgeEnvVar,
+		"for how long can browsers cache CORS preflight responses",
+		func(c *Config) any { return &c.Cors.MaxAge },
+	},
+
+	{
+		"rate-limit",
+		RateLimitEnvVar,
+		"requests per second one client can make to an operation without its own limit",
+		func(c *Config) any { return &c.Limits.Default.Rate },
+	},
+	{
+		"rate-burst",
+		RateBurstEnvVar,
+		"requests one client can make at once to an operation without its own limit",
+		func(c *Config) any { return &c.Limits.Default.Burst },
+	},
+	{
+		"max-body-bytes",
+		MaxBodyBytesEnvVar,
+		"maximum request body size of an operation without its own limit",
+		func(c *Config) any { return &c.Limits.Default.MaxBodyBytes },
+	},
+	{
+		"trusted-proxies",
+		TrustedProxiesEnvVar,
+		"comma separated IP addresses or CIDR ranges of proxies trusted to set X-Real-Ip",
+		func(c *Config) any { return &c.Limits.TrustedProxies },
+	},
+
+	{
+		"admin-token",
+		AdminTokenEnvVar,
+		"bearer token of admin endpoints, like backup, restore and metrics, empty disables them",
+		func(c *Config) any { return &c.Admin.Token },
+	},
+
+	{
+		"trace-exporter",
+		TraceExporterEnvVar,
+		"where to export traces, none, otlp or stdout",
+		func(c *Config) any { return &c.Tracing.Exporter },
+	},
+	{
+		"trace-endpoint",
+		TraceEndpointEnvVar,
+		"url of the OTLP http collector, like http://localhost:4318, used only with otlp exporter",
+		func(c *Config) any { return &c.Tracing.Endpoint },
+	},
+}
+
+// Load loads config from defaults, overridden by the yaml file from -config
+// flag or SWIMLOGS_CONFIG, overridden by environment variables, overridden
+// by flags in args. Loaded config is validated, unless it is only printed.
+// Arguments after flags are kept in Config.Args.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	return LoadCommand("swimlogs", args, lookupEnv)
+}
+
+// LoadCommand is Load for a command of the swimlogs binary, name is shown
+// in its usage.
+func LoadCommand(name string, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	return LoadCommandFlags(name, args, lookupEnv, nil)
+}
+
+// LoadCommandFlags is LoadCommand for a command with its own flags, which
+// commandFlags defines on the flag set parsing args.
+func LoadCommandFlags(
+	name string,
+	args []string,
+	lookupEnv func(string) (string, bool),
+	commandFlags func(fs *flag.FlagSet),
+) (Config, error) {
+	c := Default()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if commandFlags != nil {
+		commandFlags(fs)
+	}
+	configPath := fs.String("config", "", "path to yaml config file, env "+ConfigEnvVar)
+	printConfig := fs.Bool("print-config", false, "print effective config with redacted secrets and exit")
+
+	flagValues := make(map[string]*flagValue, len(settings))
+	for _, s := range settings {
+		_, isBool := s.field(&c).(*bool)
+		flagValues[s.flag] = &flagValue{value: s.get(&c), isBool: isBool}
+		fs.Var(flagValues[s.flag], s.flag, fmt.Sprintf("%s, env %s", s.usage, s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, fmt.Errorf("Load: %w", err)
+	}
+
+	path := *configPath
+	if path == "" {
+		path, _ = lookupEnv(ConfigEnvVar)
+	}
+	if path != "" {
+		if err := loadFile(&c, path); err != nil {
+			return Config{}, fmt.Errorf("Load: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if v, ok := lookupEnv(s.env); ok && v != "" {
+			if err := s.set(&c, v); err != nil {
+				errs = append(errs, fmt.Errorf("%w: env %s: %w", ErrInvalidConfig, s.env, err))
+			}
+		}
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for _, s := range settings {
+		if explicit[s.flag] {
+			if err := s.set(&c, flagValues[s.flag].value); err != nil {
+				errs = append(errs, fmt.Errorf("%w: flag -%s: %w", ErrInvalidConfig, s.flag, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, fmt.Errorf("Load: %w", err)
+	}
+
+	c.PrintConfig = *printConfig
+	c.Args = fs.Args()
+	if c.PrintConfig {
+		return c, nil
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, fmt.Errorf("Load: %w", err)
+	}
+	return c, nil
+}
+
+// flagValue holds raw value of a setting's flag, which is parsed by the
+// setting only if the flag was set.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (s setting) set(c *Config, v string) error {
+	var err error
+	switch f := s.field(c).(type) {
+	case *string:
+		*f = v
+	case *int:
+		*f, err = strconv.Atoi(v)
+	case *int64:
+		*f, err = strconv.ParseInt(v, 10, 64)
+	case *float64:
+		*f, err = strconv.ParseFloat(v, 64)
+	case *bool:
+		*f, err = strconv.ParseBool(v)
+	case *time.Duration:
+		*f, err = time.ParseDuration(v)
+	case *[]string:
+		*f = nil
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*f = append(*f, item)
+			}
+		}
+	default:
+		panic(fmt.Sprintf("setting %s has unsupported type %T", s.flag, f))
+	}
+	return err
+}
+
+func (s setting) get(c *Config) string {
+	switch f := s.field(c).(type) {
+	case *string:
+		return *f
+	case *int:
+		return strconv.Itoa(*f)
+	case *int64:
+		return strconv.FormatInt(*f, 10)
+	case *float64:
+		return strconv.FormatFloat(*f, 'g', -1, 64)
+	case *bool:
+		return strconv.FormatBool(*f)
+	case *time.Duration:
+		return f.String()
+	case *[]string:
+		return strings.Join(*f, ",")
+	default:
+		panic(fmt.Sprintf("setting %s has unsupported type %T", s.flag, f))
+	}
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Attendance struct {
+	TrainingId      uuid.UUID
+	UserId          uuid.UUID
+	Status          string
+	MetersCompleted *int
+
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+type AttendanceSummary struct {
+	UserId            uuid.UUID
+	Name              string
+	Present           int
+	Partial           int
+	Absent            int
+	Excused           int
+	CompletedDistance int
+}
+
+var upsertAttendance = `
+insert into attendance (training_id, user_id, status, meters_completed, created_at, modified_at)
+values ($1, $2, $3, $4, now(), now())
+on conflict (training_id, user_id) do update
+set status           = excluded.status,
+    meters_completed = excluded.meters_completed,
+    modified_at      = now()
+`
+
+// UpsertAttendance marks attendance of all users in one transaction.
+func (pool *PostgresDbPool) UpsertAttendance(ctx context.Context, attendance []Attendance) error {
+	return Tx(ctx, pool, func(tx pgx.Tx) error {
+		for i, a := range attendance {
+			_, err := tx.Exec(
+				ctx,
+				upsertAttendance,
+				a.TrainingId,
+				a.UserId,
+				a.Status,
+				a.MetersCompleted,
+			)
+			if err != nil {
+				return fmt.Errorf("UpsertAttendance record %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+var selectAttendance = `
+select a.training_id, a.user_id, a.status, a.meters_completed, a.created_at, a.modified_at
+from attendance a join users u on a.user_id = u.id
+where a.training_id = $1
+order by u.name, u.id
+`
+
+func (pool *PostgresDbPool) Attendance(ctx context.Context, trainingId uuid.UUID) ([]Attendance, error) {
+	attendance := make([]Attendance, 0)
+
+	rows, err := pool.Query(ctx, selectAttendance, trainingId)
+	if err != nil {
+		return nil, fmt.Errorf("Attendance query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Attendance
+		err := rows.Scan(
+			&a.TrainingId,
+			&a.UserId,
+			&a.Status,
+			&a.MetersCompleted,
+			&a.CreatedAt,
+			&a.ModifiedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Attendance scanning row: %w", err)
+		}
+		attendance = append(attendance, a)
+	}
+
+	return attendance, nil
+}
+
+var selectTrainingsCountInRange = `
+select count(*)
+from trainings t
+where t.start >= $1 and t.start < $2
+`
+
+var selectAttendanceSummaries = `
+select u.id, u.name,
+    count(*) filter (where a.status = 'present'),
+    count(*) filter (where a.status = 'partial'),
+    count(*) filter (where a.status = 'absent'),
+    count(*) filter (where a.status = 'excused'),
+    coalesce(sum(
+        case a.status
+            when 'present' then t.total_distance
+            when 'partial' then a.meters_completed
+            else 0
+        end
+    ), 0)
+from users u
+left join (attendance a join trainings t on a.training_id = t.id)
+    on a.user_id = u.id and t.start >= $1 and t.start < $2
+group by u.id, u.name
+order by u.name, u.id
+`
+
+// AttendanceSummaries returns how many trainings started from start until
+// end and attendance summary of every user on them.
+func (pool *PostgresDbPool) AttendanceSummaries(
+	ctx context.Context,
+	start, end time.Time,
+) (int, []AttendanceSummary, error) {
+	var trainings int
+	err := pool.QueryRow(ctx, selectTrainingsCountInRange, start, end).
+		Scan(&trainings)
+	if err != nil {
+		return 0, nil, fmt.Errorf("AttendanceSummaries trainings count query error: %w", err)
+	}
+
+	summaries := make([]AttendanceSummary, 0)
+	rows, err := pool.Query(ctx, selectAttendanceSummaries, start, end)
+	if err != nil {
+		return 0, nil, fmt.Errorf("AttendanceSummaries query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s AttendanceSummary
+		err := rows.Scan(
+			&s.UserId,
+			&s.Name,
+			&s.Present,
+			&s.Partial,
+			&s.Absent,
+			&s.Excused,
+			&s.CompletedDistance,
+		)
+		if err != nil {
+			return 0, nil, fmt.Errorf("AttendanceSummaries scanning row: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	return trainings, summaries, nil
+}
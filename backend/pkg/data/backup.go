@@ -0,0 +1,420 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Snapshot is everything stored in a repository, one slice per table.
+// Trainings don't have their sets, those are in Sets.
+type Snapshot struct {
+	Users       []User
+	Trainings   []Training
+	Sets        []TrainingSet
+	SetResults  []SetResult
+	RaceResults []RaceResult
+	CssTests    []CssTest
+	Attendance  []Attendance
+}
+
+// backupTables are all tables in a snapshot, ordered so that a row is
+// deleted before rows it references.
+var backupTables = []string{
+	"attendance",
+	"css_tests",
+	"race_results",
+	"set_results",
+	"sets",
+	"trainings",
+	"users",
+}
+
+var (
+	selectAllUsers = `
+select id, name, created_at, modified_at
+from users
+order by created_at, id
+`
+	selectAllTrainings = `
+select id, start, duration_min, total_distance, pool_length, pool_unit, created_at, modified_at
+from trainings
+order by start, created_at, id
+`
+	selectAllSets = `
+select id, training_id, set_order, repeat, distance_meters, description,
+    start_type, start_seconds, total_distance, equipment, "group"
+from sets
+order by training_id, set_order, id
+`
+	selectAllSetResults = `
+select set_id, user_id, times_ms, created_at, modified_at
+from set_results
+order by set_id, user_id
+`
+	selectAllRaceResults = `
+select id, user_id, training_id, distance, stroke, pool_length, pool_unit,
+    time_ms, date,
+    coalesce(time_ms < min(time_ms) over (
+        partition by user_id, distance, stroke, pool_length, pool_unit
+        order by date, created_at, id
+        rows between unbounded preceding and 1 preceding
+    ), true) as is_personal_best,
+    created_at, modified_at
+from race_results
+order by user_id, date, created_at, id
+`
+	selectAllCssTests = `
+select id, user_id, time_400_ms, time_200_ms, date, created_at, modified_at
+from css_tests
+order by user_id, date, created_at, id
+`
+	selectAllAttendance = `
+select training_id, user_id, status, meters_completed, created_at, modified_at
+from attendance
+order by training_id, user_id
+`
+)
+
+// Snapshot reads all tables in one read only transaction, so the snapshot
+// is consistent even while other requests write.
+func (pool *PostgresDbPool) Snapshot(ctx context.Context) (Snapshot, error) {
+	var s Snapshot
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	s.Users, err = selectAll(ctx, tx, selectAllUsers, func(row pgx.CollectableRow, u *User) error {
+		return row.Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot users: %w", err)
+	}
+
+	s.Trainings, err = selectAll(ctx, tx, selectAllTrainings, func(row pgx.CollectableRow, t *Training) error {
+		return row.Scan(
+			&t.Id,
+			&t.Start,
+			&t.DurationMin,
+			&t.TotalDistance,
+			&t.PoolLength,
+			&t.PoolUnit,
+			&t.CreatedAt,
+			&t.ModifiedAt,
+		)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot trainings: %w", err)
+	}
+
+	s.Sets, err = selectAll(ctx, tx, selectAllSets, func(row pgx.CollectableRow, s *TrainingSet) error {
+		return row.Scan(
+			&s.Id,
+			&s.TrainingId,
+			&s.SetOrder,
+			&s.Repeat,
+			&s.DistanceMeters,
+			&s.Description,
+			&s.StartType,
+			&s.StartSeconds,
+			&s.TotalDistance,
+			&s.Equipment,
+			&s.Group,
+		)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot sets: %w", err)
+	}
+
+	s.SetResults, err = selectAll(ctx, tx, selectAllSetResults, func(row pgx.CollectableRow, r *SetResult) error {
+		return row.Scan(&r.SetId, &r.UserId, &r.TimesMs, &r.CreatedAt, &r.ModifiedAt)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot set results: %w", err)
+	}
+
+	s.RaceResults, err = selectAll(ctx, tx, selectAllRaceResults, func(row pgx.CollectableRow, r *RaceResult) error {
+		return row.Scan(
+			&r.Id,
+			&r.UserId,
+			&r.TrainingId,
+			&r.Distance,
+			&r.Stroke,
+			&r.PoolLength,
+			&r.PoolUnit,
+			&r.TimeMs,
+			&r.Date,
+			&r.IsPersonalBest,
+			&r.CreatedAt,
+			&r.ModifiedAt,
+		)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot race results: %w", err)
+	}
+
+	s.CssTests, err = selectAll(ctx, tx, selectAllCssTests, func(row pgx.CollectableRow, c *CssTest) error {
+		return row.Scan(&c.Id, &c.UserId, &c.Time400Ms, &c.Time200Ms, &c.Date, &c.CreatedAt, &c.ModifiedAt)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot css tests: %w", err)
+	}
+
+	s.Attendance, err = selectAll(ctx, tx, selectAllAttendance, func(row pgx.CollectableRow, a *Attendance) error {
+		return row.Scan(&a.TrainingId, &a.UserId, &a.Status, &a.MetersCompleted, &a.CreatedAt, &a.ModifiedAt)
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("Snapshot attendance: %w", err)
+	}
+
+	return s, nil
+}
+
+func selectAll[T any](
+	ctx context.Context,
+	tx pgx.Tx,
+	sql string,
+	scan func(pgx.CollectableRow, *T) error,
+) ([]T, error) {
+	rows, err := tx.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("selectAll query error: %w", err)
+	}
+	all, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (T, error) {
+		var v T
+		err := scan(row, &v)
+		return v, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("selectAll scanning row: %w", err)
+	}
+	return all, nil
+}
+
+var (
+	restoreUser = `
+insert into users (id, name, created_at, modified_at)
+values ($1, $2, $3, $4)
+on conflict (id) do update
+set name        = excluded.name,
+    created_at  = excluded.created_at,
+    modified_at = excluded.modified_at
+`
+	restoreTraining = `
+insert into trainings (id, start, duration_min, total_distance, pool_length, pool_unit,
+    created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+on conflict (id) do update
+set start          = excluded.start,
+    duration_min   = excluded.duration_min,
+    total_distance = excluded.total_distance,
+    pool_length    = excluded.pool_length,
+    pool_unit      = excluded.pool_unit,
+    created_at     = excluded.created_at,
+    modified_at    = excluded.modified_at
+`
+	deleteRestoredSets = `
+delete from sets
+where training_id = $1 and id <> all($2)
+`
+	restoreSet = `
+insert into sets (id, training_id, set_order, repeat, distance_meters,
+    description, start_type, start_seconds, total_distance, equipment, "group")
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+on conflict (id) do update
+set training_id     = excluded.training_id,
+    set_order       = excluded.set_order,
+    repeat          = excluded.repeat,
+    distance_meters = excluded.distance_meters,
+    description     = excluded.description,
+    start_type      = excluded.start_type,
+    start_seconds   = excluded.start_seconds,
+    total_distance  = excluded.total_distance,
+    equipment       = excluded.equipment,
+    "group"         = excluded."group"
+`
+	restoreSetResult = `
+insert into set_results (set_id, user_id, times_ms, created_at, modified_at)
+values ($1, $2, $3, $4, $5)
+on conflict (set_id, user_id) do update
+set times_ms    = excluded.times_ms,
+    created_at  = excluded.created_at,
+    modified_at = excluded.modified_at
+`
+	restoreRaceResult = `
+insert into race_results (id, user_id, training_id, distance, stroke, pool_length, pool_unit,
+    time_ms, date, created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+on conflict (id) do update
+set user_id     = excluded.user_id,
+    training_id = excluded.training_id,
+    distance    = excluded.distance,
+    stroke      = excluded.stroke,
+    pool_length = excluded.pool_length,
+    pool_unit   = excluded.pool_unit,
+    time_ms     = excluded.time_ms,
+    date        = excluded.date,
+    created_at  = excluded.created_at,
+    modified_at = excluded.modified_at
+`
+	restoreCssTest = `
+insert into css_tests (id, user_id, time_400_ms, time_200_ms, date, created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (id) do update
+set user_id     = excluded.user_id,
+    time_400_ms = excluded.time_400_ms,
+    time_200_ms = excluded.time_200_ms,
+    date        = excluded.date,
+    created_at  = excluded.created_at,
+    modified_at = excluded.modified_at
+`
+	restoreAttendance = `
+insert into attendance (training_id, user_id, status, meters_completed, created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6)
+on conflict (training_id, user_id) do update
+set status           = excluded.status,
+    meters_completed = excluded.meters_completed,
+    created_at       = excluded.created_at,
+    modified_at      = excluded.modified_at
+`
+)
+
+// Restore imports s in one transaction. With replace everything stored is
+// deleted first, otherwise rows of s overwrite stored rows with the same
+// key and other stored rows are kept. Sets of a restored training missing
+// from s are deleted, edits give added sets new ids, so upserting would
+// keep sets the training didn't have.
+func (pool *PostgresDbPool) Restore(ctx context.Context, s Snapshot, replace bool) error {
+	setIds := setIdsByTraining(s)
+	return Tx(ctx, pool, func(tx pgx.Tx) error {
+		if replace {
+			for _, table := range backupTables {
+				if _, err := tx.Exec(ctx, "delete from "+table); err != nil {
+					return fmt.Errorf("Restore deleting %s: %w", table, err)
+				}
+			}
+		}
+
+		for i, u := range s.Users {
+			_, err := tx.Exec(ctx, restoreUser, u.Id, u.Name, u.CreatedAt, u.ModifiedAt)
+			if err != nil {
+				return fmt.Errorf("Restore user %d: %w", i, err)
+			}
+		}
+		for i, t := range s.Trainings {
+			_, err := tx.Exec(
+				ctx,
+				restoreTraining,
+				t.Id,
+				t.Start,
+				t.DurationMin,
+				t.TotalDistance,
+				t.PoolLength,
+				t.PoolUnit,
+				t.CreatedAt,
+				t.ModifiedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("Restore training %d: %w", i, err)
+			}
+			if _, err := tx.Exec(ctx, deleteRestoredSets, t.Id, setIds[t.Id]); err != nil {
+				return fmt.Errorf("Restore training %d deleting sets: %w", i, err)
+			}
+		}
+		for i, set := range s.Sets {
+			_, err := tx.Exec(
+				ctx,
+				restoreSet,
+				set.Id,
+				set.TrainingId,
+				set.SetOrder,
+				set.Repeat,
+				set.DistanceMeters,
+				set.Description,
+				set.StartType,
+				set.StartSeconds,
+				set.TotalDistance,
+				set.Equipment,
+				set.Group,
+			)
+			if err != nil {
+				return fmt.Errorf("Restore set %d: %w", i, err)
+			}
+		}
+		for i, r := range s.SetResults {
+			_, err := tx.Exec(ctx, restoreSetResult, r.SetId, r.UserId, r.TimesMs, r.CreatedAt, r.ModifiedAt)
+			if err != nil {
+				return fmt.Errorf("Restore set result %d: %w", i, err)
+			}
+		}
+		for i, r := range s.RaceResults {
+			_, err := tx.Exec(
+				ctx,
+				restoreRaceResult,
+				r.Id,
+				r.UserId,
+				r.TrainingId,
+				r.Distance,
+				r.Stroke,
+				r.PoolLength,
+				r.PoolUnit,
+				r.TimeMs,
+				r.Date,
+				r.CreatedAt,
+				r.ModifiedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("Restore race result %d: %w", i, err)
+			}
+		}
+		for i, c := range s.CssTests {
+			_, err := tx.Exec(
+				ctx,
+				restoreCssTest,
+				c.Id,
+				c.UserId,
+				c.Time400Ms,
+				c.Time200Ms,
+				c.Date,
+				c.CreatedAt,
+				c.ModifiedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("Restore css test %d: %w", i, err)
+			}
+		}
+		for i, a := range s.Attendance {
+			_, err := tx.Exec(
+				ctx,
+				restoreAttendance,
+				a.TrainingId,
+				a.UserId,
+				a.Status,
+				a.MetersCompleted,
+				a.CreatedAt,
+				a.ModifiedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("Restore attendance %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// setIdsByTraining returns ids of sets in s of every training in s, never
+// nil.
+func setIdsByTraining(s Snapshot) map[uuid.UUID][]uuid.UUID {
+	ids := make(map[uuid.UUID][]uuid.UUID, len(s.Trainings))
+	for _, t := range s.Trainings {
+		ids[t.Id] = make([]uuid.UUID, 0)
+	}
+	for _, set := range s.Sets {
+		ids[set.TrainingId] = append(ids[set.TrainingId], set.Id)
+	}
+	return ids
+}
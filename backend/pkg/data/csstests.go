@@ -0,0 +1,100 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type CssTest struct {
+	Id        uuid.UUID
+	UserId    uuid.UUID
+	Time400Ms int
+	Time200Ms int
+	Date      time.Time
+
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+var insertCssTest = `
+insert into css_tests (id, user_id, time_400_ms, time_200_ms, date, created_at, modified_at)
+values ($1, $2, $3, $4, $5, now(), now())
+returning id, user_id, time_400_ms, time_200_ms, date, created_at, modified_at
+`
+
+func (pool *PostgresDbPool) PersistCssTest(ctx context.Context, c CssTest) (CssTest, error) {
+	err := pool.QueryRow(
+		ctx,
+		insertCssTest,
+		c.Id,
+		c.UserId,
+		c.Time400Ms,
+		c.Time200Ms,
+		c.Date,
+	).Scan(&c.Id, &c.UserId, &c.Time400Ms, &c.Time200Ms, &c.Date, &c.CreatedAt, &c.ModifiedAt)
+	if err != nil {
+		return CssTest{}, fmt.Errorf("PersistCssTest: %w", err)
+	}
+	return c, nil
+}
+
+var selectCssTests = `
+select c.id, c.user_id, c.time_400_ms, c.time_200_ms, c.date, c.created_at, c.modified_at
+from css_tests c
+where c.user_id = $1
+order by c.date desc, c.created_at desc
+`
+
+func (pool *PostgresDbPool) CssTests(ctx context.Context, userId uuid.UUID) ([]CssTest, error) {
+	tests := make([]CssTest, 0)
+
+	rows, err := pool.Query(ctx, selectCssTests, userId)
+	if err != nil {
+		return nil, fmt.Errorf("CssTests query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c CssTest
+		err := rows.Scan(
+			&c.Id,
+			&c.UserId,
+			&c.Time400Ms,
+			&c.Time200Ms,
+			&c.Date,
+			&c.CreatedAt,
+			&c.ModifiedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("CssTests scanning row: %w", err)
+		}
+		tests = append(tests, c)
+	}
+
+	return tests, nil
+}
+
+var selectLatestCssTest = `
+select c.id, c.user_id, c.time_400_ms, c.time_200_ms, c.date, c.created_at, c.modified_at
+from css_tests c
+where c.user_id = $1
+order by c.date desc, c.created_at desc
+limit 1
+`
+
+func (pool *PostgresDbPool) LatestCssTest(ctx context.Context, userId uuid.UUID) (CssTest, error) {
+	var c CssTest
+	err := pool.QueryRow(ctx, selectLatestCssTest, userId).
+		Scan(&c.Id, &c.UserId, &c.Time400Ms, &c.Time200Ms, &c.Date, &c.CreatedAt, &c.ModifiedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return CssTest{}, fmt.Errorf("LatestCssTest user has no test: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return CssTest{}, fmt.Errorf("LatestCssTest query error: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,605 @@
+// Package datatest has the contract every data.Repository implementation
+// is tested against.
+package datatest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+// repositoryContract is run against every data.Repository implementation,
+// each test gets an empty repository.
+var repositoryContract = []struct {
+	name string
+	test func(t *testing.T, repo data.Repository)
+}{
+	{"training round trip", testRepositoryTrainingRoundTrip},
+	{"not found", testRepositoryNotFound},
+	{"persist new trainings", testRepositoryPersistNewTrainings},
+	{"training details page", testRepositoryTrainingDetailsPage},
+	{"training details in range", testRepositoryTrainingDetailsInRange},
+	{"edit training upserts sets", testRepositoryEditTraining},
+	{"delete training cascades", testRepositoryDeleteTraining},
+	{"users ordered by name", testRepositoryUsers},
+	{"set result upsert", testRepositorySetResults},
+	{"edit drops stale set results", testRepositoryEditStaleSetResults},
+	{"race results", testRepositoryRaceResults},
+	{"css tests", testRepositoryCssTests},
+	{"attendance", testRepositoryAttendance},
+	{"snapshot and restore", testRepositorySnapshotRestore},
+	{"merge restore replaces sets", testRepositoryRestoreMergeSets},
+}
+
+// RunContract runs the contract against repositories made by newRepo, it
+// must return an empty repository for every test.
+func RunContract(t *testing.T, newRepo func(t *testing.T) data.Repository) {
+	for _, c := range repositoryContract {
+		t.Run(c.name, func(t *testing.T) { c.test(t, newRepo(t)) })
+	}
+}
+
+func testRepositoryTrainingRoundTrip(t *testing.T, repo data.Repository) {
+	start := time.Date(2024, time.January, 8, 18, 0, 0, 0, time.UTC)
+	training := newDataTraining(start, 100, 200)
+	slices.Reverse(training.Sets)
+	persistDataTraining(t, repo, training)
+
+	got, err := repo.Training(context.Background(), training.Id)
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	assert.Equal(training.Id, got.Id)
+	assert.WithinDuration(start, got.Start, 0)
+	assert.Equal(60, got.DurationMin)
+	assert.Equal(300, got.TotalDistance)
+	assert.Equal(25, got.PoolLength)
+	assert.Equal("meters", got.PoolUnit)
+	require.Len(t, got.Sets, 2)
+	assert.Equal(0, got.Sets[0].SetOrder)
+	assert.Equal(100, got.Sets[0].DistanceMeters)
+	assert.Equal(1, got.Sets[1].SetOrder)
+	assert.Equal(200, got.Sets[1].DistanceMeters)
+
+	set, err := repo.Set(context.Background(), got.Sets[1].Id)
+	require.NoError(t, err)
+	assert.Equal(got.Sets[1], set)
+}
+
+func testRepositoryPersistNewTrainings(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 8, 18, 0, 0, 0, time.UTC)
+	stored := persistDataTraining(t, repo, newDataTraining(start, 100))
+	fresh := newDataTraining(start.AddDate(0, 0, 1), 200)
+
+	persisted, err := repo.PersistNewTrainings(ctx, []data.Training{stored, fresh})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{fresh.Id}, trainingIds(persisted), "stored trainings are skipped")
+
+	invalid := newDataTraining(start.AddDate(0, 0, 3), 400)
+	invalid.Sets[0].TrainingId = uuid.New()
+	other := newDataTraining(start.AddDate(0, 0, 2), 300)
+	_, err = repo.PersistNewTrainings(ctx, []data.Training{other, invalid})
+	assert.Error(t, err, "set of unknown training")
+	_, err = repo.Training(ctx, other.Id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound, "nothing is persisted when a training is invalid")
+}
+
+func testRepositoryNotFound(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := repo.Training(ctx, id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+	_, err = repo.Set(ctx, id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+	err = repo.DeleteTraining(ctx, id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+	_, err = repo.EditTraining(ctx, id, newDataTraining(time.Now(), 100))
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+	_, err = repo.User(ctx, id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+	_, err = repo.LatestCssTest(ctx, id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+}
+
+func testRepositoryTrainingDetailsPage(t *testing.T, repo data.Repository) {
+	day := time.Date(2024, time.February, 1, 18, 0, 0, 0, time.UTC)
+	first := persistDataTraining(t, repo, newDataTraining(day, 100))
+	third := persistDataTraining(t, repo, newDataTraining(day.AddDate(0, 0, 2), 100))
+	second := persistDataTraining(t, repo, newDataTraining(day.AddDate(0, 0, 1), 100))
+
+	tests := []struct {
+		page      int
+		wantIds   []uuid.UUID
+		wantTotal int
+	}{
+		{page: 0, wantIds: []uuid.UUID{third.Id, second.Id}, wantTotal: 3},
+		{page: 1, wantIds: []uuid.UUID{first.Id}, wantTotal: 3},
+		{page: 2, wantIds: []uuid.UUID{}, wantTotal: 0},
+	}
+
+	for _, test := range tests {
+		details, total, err := repo.TrainingDetails(context.Background(), test.page, 2)
+		require.NoError(t, err)
+		assert.Equal(t, test.wantIds, trainingIds(details), "page %d", test.page)
+		assert.Equal(t, test.wantTotal, total, "page %d", test.page)
+	}
+}
+
+func testRepositoryTrainingDetailsInRange(t *testing.T, repo data.Repository) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, time.March, d, hour, 0, 0, 0, time.UTC)
+	}
+	persistDataTraining(t, repo, newDataTraining(day(1, 10), 100))
+	late := persistDataTraining(t, repo, newDataTraining(day(2, 8), 100))
+	early := persistDataTraining(t, repo, newDataTraining(day(2, 7), 100))
+	persistDataTraining(t, repo, newDataTraining(day(4, 10), 100))
+	persistDataTraining(t, repo, newDataTraining(day(3, 0), 100))
+
+	details, err := repo.TrainingDetailsInRange(context.Background(), day(2, 0), day(3, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{early.Id, late.Id}, trainingIds(details), "end is excluded")
+
+	bratislava, err := time.LoadLocation("Europe/Bratislava")
+	require.NoError(t, err)
+	details, err = repo.TrainingDetailsInRange(
+		context.Background(),
+		time.Date(2024, time.March, 2, 8, 30, 0, 0, bratislava),
+		time.Date(2024, time.March, 2, 9, 30, 0, 0, bratislava),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{late.Id}, trainingIds(details), "range is compared as instants")
+}
+
+func testRepositoryEditTraining(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	start := time.Date(2024, time.April, 2, 18, 0, 0, 0, time.UTC)
+	training := persistDataTraining(t, repo, newDataTraining(start, 100, 200))
+
+	edit := newDataTraining(start.Add(time.Hour), 300, 50)
+	edit.Id = training.Id
+	edit.Sets[0].Id = training.Sets[0].Id
+	edit.Sets[0].TrainingId = training.Id
+	edit.Sets[1].TrainingId = training.Id
+	edit.Sets[1].SetOrder = 2
+	newSetId := edit.Sets[1].Id
+
+	edited, err := repo.EditTraining(ctx, training.Id, edit)
+	require.NoError(t, err)
+	assert.Equal(t, training.Id, edited.Id)
+	require.Len(t, edited.Sets, 2)
+	assert.NotEqual(t, newSetId, edited.Sets[1].Id, "new sets get a new id")
+
+	got, err := repo.Training(ctx, training.Id)
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	assert.WithinDuration(start.Add(time.Hour), got.Start, 0)
+	require.Len(t, got.Sets, 3)
+	assert.Equal(training.Sets[0].Id, got.Sets[0].Id)
+	assert.Equal(300, got.Sets[0].DistanceMeters)
+	assert.Equal(training.Sets[1].Id, got.Sets[1].Id, "sets missing in edit are kept")
+	assert.Equal(200, got.Sets[1].DistanceMeters)
+	assert.Equal(edited.Sets[1].Id, got.Sets[2].Id)
+	assert.Equal(50, got.Sets[2].DistanceMeters)
+}
+
+func testRepositoryDeleteTraining(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	training := persistDataTraining(t, repo, newDataTraining(time.Now(), 100))
+	user := persistDataUser(t, repo, "swimmer")
+
+	_, err := repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   training.Sets[0].Id,
+		UserId:  user.Id,
+		TimesMs: []int{80_000},
+	})
+	require.NoError(t, err)
+	err = repo.UpsertAttendance(ctx, []data.Attendance{
+		{TrainingId: training.Id, UserId: user.Id, Status: "present"},
+	})
+	require.NoError(t, err)
+	race := newDataRaceResult(user.Id, "freestyle", 100, 80_000, time.Now())
+	_, err = repo.PersistRaceResult(ctx, race)
+	require.NoError(t, err)
+	race = newDataRaceResult(user.Id, "freestyle", 100, 70_000, time.Now())
+	race.TrainingId = &training.Id
+	_, err = repo.PersistRaceResult(ctx, race)
+	require.NoError(t, err)
+
+	err = repo.DeleteTraining(ctx, training.Id)
+	require.NoError(t, err)
+
+	_, err = repo.Training(ctx, training.Id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+	_, err = repo.Set(ctx, training.Sets[0].Id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound)
+
+	results, err := repo.SetResults(ctx, training.Sets[0].Id)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	attendance, err := repo.Attendance(ctx, training.Id)
+	require.NoError(t, err)
+	assert.Empty(t, attendance)
+	races, err := repo.RaceResults(ctx, user.Id)
+	require.NoError(t, err)
+	require.Len(t, races, 2, "race results outlive their training")
+	assert.Nil(t, races[0].TrainingId)
+	assert.Nil(t, races[1].TrainingId)
+}
+
+func testRepositoryUsers(t *testing.T, repo data.Repository) {
+	b := persistDataUser(t, repo, "b")
+	a := persistDataUser(t, repo, "a")
+	c := persistDataUser(t, repo, "c")
+
+	users, err := repo.Users(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, a.Id, users[0].Id)
+	assert.Equal(t, b.Id, users[1].Id)
+	assert.Equal(t, c.Id, users[2].Id)
+}
+
+func testRepositorySetResults(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	training := persistDataTraining(t, repo, newDataTraining(time.Now(), 100))
+	user := persistDataUser(t, repo, "swimmer")
+	setId := training.Sets[0].Id
+
+	first, err := repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   setId,
+		UserId:  user.Id,
+		TimesMs: []int{80_000},
+	})
+	require.NoError(t, err)
+	second, err := repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   setId,
+		UserId:  user.Id,
+		TimesMs: []int{75_000},
+	})
+	require.NoError(t, err)
+	assert.WithinDuration(t, first.CreatedAt, second.CreatedAt, 0)
+
+	results, err := repo.SetResults(ctx, setId)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []int{75_000}, results[0].TimesMs)
+
+	_, err = repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   setId,
+		UserId:  uuid.New(),
+		TimesMs: []int{75_000},
+	})
+	assert.Error(t, err, "unknown user")
+	assert.NotErrorIs(t, err, data.ErrRowsNotFound)
+}
+
+func testRepositoryEditStaleSetResults(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	training := persistDataTraining(t, repo, newDataTraining(time.Now(), 100, 200))
+	user := persistDataUser(t, repo, "swimmer")
+	for _, s := range training.Sets {
+		_, err := repo.UpsertSetResult(ctx, data.SetResult{
+			SetId:   s.Id,
+			UserId:  user.Id,
+			TimesMs: []int{80_000},
+		})
+		require.NoError(t, err)
+	}
+
+	description := "easy"
+	training.Sets[0].Repeat = 2
+	training.Sets[0].TotalDistance = 200
+	training.Sets[1].Description = &description
+	_, err := repo.EditTraining(ctx, training.Id, training)
+	require.NoError(t, err)
+
+	results, err := repo.SetResults(ctx, training.Sets[0].Id)
+	require.NoError(t, err)
+	assert.Empty(t, results, "times of 1 repetition don't fit a set of 2")
+
+	results, err = repo.SetResults(ctx, training.Sets[1].Id)
+	require.NoError(t, err)
+	assert.Len(t, results, 1, "results of a set with the same repeat are kept")
+}
+
+func testRepositoryRaceResults(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	user := persistDataUser(t, repo, "swimmer")
+	day := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	races := []data.RaceResult{
+		newDataRaceResult(user.Id, "freestyle", 100, 65_000, day.AddDate(0, 1, 0)),
+		newDataRaceResult(user.Id, "backstroke", 50, 40_000, day),
+		newDataRaceResult(user.Id, "freestyle", 100, 70_000, day),
+		newDataRaceResult(user.Id, "freestyle", 50, 30_000, day),
+	}
+	for _, r := range races {
+		_, err := repo.PersistRaceResult(ctx, r)
+		require.NoError(t, err)
+	}
+
+	results, err := repo.RaceResults(ctx, user.Id)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	// strokes are ordered as declared, not alphabetically
+	assert.Equal(t, races[3].Id, results[0].Id)
+	assert.Equal(t, races[2].Id, results[1].Id)
+	assert.Equal(t, races[0].Id, results[2].Id)
+	assert.Equal(t, races[1].Id, results[3].Id)
+	for _, r := range results {
+		assert.True(t, r.IsPersonalBest, "faster than every earlier result of its event")
+	}
+
+	backDated, err := repo.PersistRaceResult(
+		ctx,
+		newDataRaceResult(user.Id, "freestyle", 100, 60_000, day.AddDate(0, 0, -1)),
+	)
+	require.NoError(t, err)
+	assert.True(t, backDated.IsPersonalBest)
+	later, err := repo.PersistRaceResult(ctx, newDataRaceResult(user.Id, "freestyle", 100, 60_000, day))
+	require.NoError(t, err)
+	assert.False(t, later.IsPersonalBest, "equal time isn't a new best")
+
+	results, err = repo.RaceResults(ctx, user.Id)
+	require.NoError(t, err)
+	best := map[uuid.UUID]bool{}
+	for _, r := range results {
+		best[r.Id] = r.IsPersonalBest
+	}
+	assert.True(t, best[backDated.Id])
+	assert.False(t, best[races[2].Id], "back-dated faster result comes before it")
+	assert.False(t, best[races[0].Id])
+	assert.False(t, best[later.Id])
+	assert.True(t, best[races[3].Id], "other events aren't affected")
+
+	snapshot, err := repo.Snapshot(ctx)
+	require.NoError(t, err)
+	for _, r := range snapshot.RaceResults {
+		assert.Equal(t, best[r.Id], r.IsPersonalBest, "snapshot derives the same flag")
+	}
+}
+
+func testRepositoryCssTests(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	user := persistDataUser(t, repo, "swimmer")
+	day := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, date := range []time.Time{day, day.AddDate(0, 0, 9).Add(15 * time.Hour)} {
+		_, err := repo.PersistCssTest(ctx, data.CssTest{
+			Id:        uuid.New(),
+			UserId:    user.Id,
+			Time400Ms: 360_000,
+			Time200Ms: 170_000,
+			Date:      date,
+		})
+		require.NoError(t, err)
+	}
+
+	tests, err := repo.CssTests(ctx, user.Id)
+	require.NoError(t, err)
+	require.Len(t, tests, 2)
+	assert.WithinDuration(t, day.AddDate(0, 0, 9), tests[0].Date, 0, "dates are truncated")
+	assert.WithinDuration(t, day, tests[1].Date, 0)
+
+	latest, err := repo.LatestCssTest(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Equal(t, tests[0].Id, latest.Id)
+}
+
+func testRepositoryAttendance(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	b := persistDataUser(t, repo, "b")
+	a := persistDataUser(t, repo, "a")
+	day := time.Date(2024, time.July, 5, 18, 0, 0, 0, time.UTC)
+	training := persistDataTraining(t, repo, newDataTraining(day, 400))
+	outOfRange := persistDataTraining(t, repo, newDataTraining(day.AddDate(0, 1, 0), 400))
+	metersCompleted := 150
+
+	err := repo.UpsertAttendance(ctx, []data.Attendance{
+		{TrainingId: training.Id, UserId: b.Id, Status: "present"},
+		{TrainingId: training.Id, UserId: a.Id, Status: "partial", MetersCompleted: &metersCompleted},
+		{TrainingId: outOfRange.Id, UserId: a.Id, Status: "present"},
+	})
+	require.NoError(t, err)
+
+	err = repo.UpsertAttendance(ctx, []data.Attendance{
+		{TrainingId: training.Id, UserId: b.Id, Status: "excused"},
+		{TrainingId: training.Id, UserId: uuid.New(), Status: "present"},
+	})
+	assert.Error(t, err, "nothing is stored when a record is invalid")
+
+	err = repo.UpsertAttendance(ctx, []data.Attendance{
+		{TrainingId: training.Id, UserId: a.Id, Status: "absent"},
+	})
+	require.NoError(t, err)
+
+	attendance, err := repo.Attendance(ctx, training.Id)
+	require.NoError(t, err)
+	require.Len(t, attendance, 2)
+	assert.Equal(t, a.Id, attendance[0].UserId)
+	assert.Equal(t, "absent", attendance[0].Status)
+	assert.Nil(t, attendance[0].MetersCompleted)
+	assert.Equal(t, b.Id, attendance[1].UserId)
+	assert.Equal(t, "present", attendance[1].Status)
+
+	trainings, summaries, err := repo.AttendanceSummaries(
+		ctx,
+		day.AddDate(0, 0, -4),
+		day.AddDate(0, 0, 5),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, trainings)
+	assert.Equal(t, []data.AttendanceSummary{
+		{UserId: a.Id, Name: "a", Absent: 1},
+		{UserId: b.Id, Name: "b", Present: 1, CompletedDistance: 400},
+	}, summaries)
+}
+
+func testRepositorySnapshotRestore(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	user := persistDataUser(t, repo, "swimmer")
+	day := time.Date(2024, time.August, 5, 18, 0, 0, 0, time.UTC)
+	training := persistDataTraining(t, repo, newDataTraining(day, 100, 200))
+
+	_, err := repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   training.Sets[0].Id,
+		UserId:  user.Id,
+		TimesMs: []int{80_000},
+	})
+	require.NoError(t, err)
+	_, err = repo.PersistRaceResult(ctx, newDataRaceResult(user.Id, "freestyle", 100, 70_000, day))
+	require.NoError(t, err)
+	_, err = repo.PersistCssTest(ctx, data.CssTest{
+		Id:        uuid.New(),
+		UserId:    user.Id,
+		Time400Ms: 360_000,
+		Time200Ms: 170_000,
+		Date:      day,
+	})
+	require.NoError(t, err)
+	err = repo.UpsertAttendance(ctx, []data.Attendance{
+		{TrainingId: training.Id, UserId: user.Id, Status: "present"},
+	})
+	require.NoError(t, err)
+
+	snapshot, err := repo.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Users, 1)
+	assert.Len(t, snapshot.Trainings, 1)
+	assert.Empty(t, snapshot.Trainings[0].Sets, "sets are separate")
+	assert.Len(t, snapshot.Sets, 2)
+	assert.Len(t, snapshot.SetResults, 1)
+	assert.Len(t, snapshot.RaceResults, 1)
+	assert.Len(t, snapshot.CssTests, 1)
+	assert.Len(t, snapshot.Attendance, 1)
+
+	err = repo.DeleteTraining(ctx, training.Id)
+	require.NoError(t, err)
+	extra := persistDataUser(t, repo, "extra")
+
+	require.NoError(t, repo.Restore(ctx, snapshot, false))
+	restored, err := repo.Training(ctx, training.Id)
+	require.NoError(t, err)
+	assert.Len(t, restored.Sets, 2)
+	users, err := repo.Users(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 2, "merge keeps stored rows")
+
+	require.NoError(t, repo.Restore(ctx, snapshot, true))
+	replaced, err := repo.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, replaced)
+	_, err = repo.User(ctx, extra.Id)
+	assert.ErrorIs(t, err, data.ErrRowsNotFound, "replace deletes stored rows")
+
+	invalid := snapshot
+	invalid.Attendance = []data.Attendance{
+		{TrainingId: training.Id, UserId: uuid.New(), Status: "present"},
+	}
+	err = repo.Restore(ctx, invalid, true)
+	assert.Error(t, err, "unknown user")
+	unchanged, err := repo.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, unchanged, "nothing is changed when a row is invalid")
+}
+
+func testRepositoryRestoreMergeSets(t *testing.T, repo data.Repository) {
+	ctx := context.Background()
+	user := persistDataUser(t, repo, "swimmer")
+	start := time.Date(2024, time.August, 12, 18, 0, 0, 0, time.UTC)
+	training := persistDataTraining(t, repo, newDataTraining(start, 100, 200))
+
+	snapshot, err := repo.Snapshot(ctx)
+	require.NoError(t, err)
+
+	added := newDataTraining(start, 50).Sets[0]
+	added.TrainingId = training.Id
+	added.SetOrder = 2
+	edit := training
+	edit.Sets = append(slices.Clone(training.Sets), added)
+	_, err = repo.EditTraining(ctx, training.Id, edit)
+	require.NoError(t, err)
+	result, err := repo.UpsertSetResult(ctx, data.SetResult{
+		SetId:   training.Sets[0].Id,
+		UserId:  user.Id,
+		TimesMs: []int{80_000},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Restore(ctx, snapshot, false))
+	restored, err := repo.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.Sets, restored.Sets, "sets added after the snapshot are deleted")
+	assert.Equal(t, []data.SetResult{result}, restored.SetResults, "results of kept sets are kept")
+}
+
+func newDataTraining(start time.Time, setDistances ...int) data.Training {
+	t := data.Training{
+		Id:          uuid.New(),
+		Start:       start,
+		DurationMin: 60,
+		PoolLength:  25,
+		PoolUnit:    "meters",
+	}
+	for i, distance := range setDistances {
+		t.Sets = append(t.Sets, data.TrainingSet{
+			Id:             uuid.New(),
+			TrainingId:     t.Id,
+			SetOrder:       i,
+			TotalDistance:  distance,
+			Repeat:         1,
+			DistanceMeters: distance,
+			StartType:      "None",
+		})
+		t.TotalDistance += distance
+	}
+	return t
+}
+
+func newDataRaceResult(
+	userId uuid.UUID,
+	stroke string,
+	distance, timeMs int,
+	date time.Time,
+) data.RaceResult {
+	return data.RaceResult{
+		Id:         uuid.New(),
+		UserId:     userId,
+		Distance:   distance,
+		Stroke:     stroke,
+		PoolLength: 25,
+		PoolUnit:   "meters",
+		TimeMs:     timeMs,
+		Date:       date,
+	}
+}
+
+func persistDataTraining(t *testing.T, repo data.Repository, training data.Training) data.Training {
+	training, err := repo.PersistTraining(context.Background(), training)
+	require.NoError(t, err)
+	return training
+}
+
+func persistDataUser(t *testing.T, repo data.Repository, name string) data.User {
+	u, err := repo.PersistUser(context.Background(), data.User{Id: uuid.New(), Name: name})
+	require.NoError(t, err)
+	return u
+}
+
+func trainingIds(trainings []data.Training) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(trainings))
+	for _, t := range trainings {
+		ids = append(ids, t.Id)
+	}
+	return ids
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,12 +14,12 @@ import (
 var ErrRowsNotFound = errors.New("didn't find row")
 
 type PostgresDbPool struct {
-	conStr        string
-	migrationsDir string
+	conStr     string
+	migrations fs.FS
 	*pgxpool.Pool
 }
 
-func NewPostgresPool(conStr, migrationsDir string) (*PostgresDbPool, error) {
+func NewPostgresPool(conStr string, migrations fs.FS) (*PostgresDbPool, error) {
 	dbConfig, err := pgxpool.ParseConfig(conStr)
 	if err != nil {
 		return nil, fmt.Errorf("NewPostgresPool parse config: %w", err)
@@ -27,37 +28,38 @@ func NewPostgresPool(conStr, migrationsDir string) (*PostgresDbPool, error) {
 		pgxUUID.Register(conn.TypeMap())
 		return nil
 	}
+	dbConfig.ConnConfig.Tracer = pgxTracer{}
 
 	dbPool, err := pgxpool.NewWithConfig(context.Background(), dbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("NewPostgresPool new: %w", err)
 	}
 
-	return &PostgresDbPool{conStr, migrationsDir, dbPool}, nil
+	return &PostgresDbPool{conStr, migrations, dbPool}, nil
 }
 
 func (psql *PostgresDbPool) Close() {
 	psql.Pool.Close()
 }
 
-func Sql(pool *PostgresDbPool, sql string, args ...any) error {
-	_, err := pool.Exec(context.Background(), sql, args...)
+func Sql(ctx context.Context, pool *PostgresDbPool, sql string, args ...any) error {
+	_, err := pool.Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("Sql: %w", err)
 	}
 	return nil
 }
 
-func SqlWithResult(pool *PostgresDbPool, sql string, args, dest []any) error {
-	err := pool.QueryRow(context.Background(), sql, args...).Scan(dest...)
+func SqlWithResult(ctx context.Context, pool *PostgresDbPool, sql string, args, dest []any) error {
+	err := pool.QueryRow(ctx, sql, args...).Scan(dest...)
 	if err != nil {
 		return fmt.Errorf("SqlWithResult: %w", err)
 	}
 	return nil
 }
 
-func Tx(pool *PostgresDbPool, f func(pgx.Tx) error) error {
-	err := pgx.BeginFunc(context.Background(), pool.Pool, f)
+func Tx(ctx context.Context, pool *PostgresDbPool, f func(pgx.Tx) error) error {
+	err := pgx.BeginFunc(ctx, pool.Pool, f)
 	if err != nil {
 		return fmt.Errorf("Tx: %w", err)
 	}
@@ -65,20 +67,20 @@ func Tx(pool *PostgresDbPool, f func(pgx.Tx) error) error {
 	return nil
 }
 
-func TxWithResult[R any](pool *PostgresDbPool, f func(pgx.Tx) (R, error)) (R, error) {
+func TxWithResult[R any](ctx context.Context, pool *PostgresDbPool, f func(pgx.Tx) (R, error)) (R, error) {
 	var res R
-	tx, err := pool.Begin(context.Background())
+	tx, err := pool.Begin(ctx)
 	if err != nil {
 		return res, fmt.Errorf("TxWithResult init: %w", err)
 	}
-	defer tx.Rollback(context.Background())
+	defer tx.Rollback(ctx)
 
 	res, err = f(tx)
 	if err != nil {
 		return res, fmt.Errorf("TxWithResult: %w", err)
 	}
 
-	err = tx.Commit(context.Background())
+	err = tx.Commit(ctx)
 	if err != nil {
 		return res, fmt.Errorf("TxWithResult commit: %w", err)
 	}
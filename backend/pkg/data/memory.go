@@ -0,0 +1,809 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errDuplicateKey = errors.New("duplicate key value violates unique constraint")
+	errForeignKey   = errors.New("violates foreign key constraint")
+)
+
+// Same order as values of the stroke and pool_unit enums in migrations,
+// postgres sorts enums by it.
+var (
+	strokeEnumOrder = []string{
+		"freestyle",
+		"backstroke",
+		"breaststroke",
+		"butterfly",
+		"medley",
+		"surface",
+		"bifins",
+		"apnea",
+	}
+	poolUnitEnumOrder = []string{"meters", "yards"}
+)
+
+// MemoryRepository is an in-memory Repository with the same semantics as
+// PostgresDbPool. It is meant for tests and local development, nothing is
+// persisted.
+type MemoryRepository struct {
+	mu sync.RWMutex
+
+	// all slices are kept in insertion order, which breaks ties in sorting
+	trainings   []Training
+	sets        []TrainingSet
+	users       []User
+	setResults  []SetResult
+	raceResults []RaceResult
+	cssTests    []CssTest
+	attendance  []Attendance
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+func (m *MemoryRepository) PersistTraining(ctx context.Context, t Training) (Training, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.persistTraining(t)
+}
+
+func (m *MemoryRepository) PersistNewTrainings(ctx context.Context, ts []Training) ([]Training, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// trainings and sets are only appended, so cutting them back rolls back
+	trainings, sets := len(m.trainings), len(m.sets)
+	persisted := make([]Training, 0, len(ts))
+	for i, t := range ts {
+		if m.trainingIndex(t.Id) != -1 {
+			continue
+		}
+		t, err := m.persistTraining(t)
+		if err != nil {
+			m.trainings, m.sets = m.trainings[:trainings], m.sets[:sets]
+			return nil, fmt.Errorf("PersistNewTrainings training %d: %w", i, err)
+		}
+		persisted = append(persisted, t)
+	}
+	return persisted, nil
+}
+
+func (m *MemoryRepository) persistTraining(t Training) (Training, error) {
+	if m.trainingIndex(t.Id) != -1 {
+		return Training{}, fmt.Errorf("PersistTraining training %s: %w", t.Id, errDuplicateKey)
+	}
+	for i, s := range t.Sets {
+		if m.setIndex(s.Id) != -1 {
+			return Training{}, fmt.Errorf("PersistTraining set %d: %w", i, errDuplicateKey)
+		} else if s.TrainingId != t.Id && m.trainingIndex(s.TrainingId) == -1 {
+			return Training{}, fmt.Errorf("PersistTraining set %d: %w", i, errForeignKey)
+		}
+	}
+
+	now := timestamp()
+	t.Start = t.Start.Truncate(time.Microsecond)
+	t.CreatedAt, t.ModifiedAt = now, now
+	sets := cloneSets(t.Sets)
+	t.Sets = nil
+	m.trainings = append(m.trainings, t)
+	m.sets = append(m.sets, cloneSets(sets)...)
+
+	t.Sets = sets
+	return t, nil
+}
+
+func (m *MemoryRepository) DeleteTraining(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.trainingIndex(id) == -1 {
+		return fmt.Errorf("DeleteTraining training doesnt exist: %w", ErrRowsNotFound)
+	}
+
+	m.trainings = slices.DeleteFunc(m.trainings, func(t Training) bool { return t.Id == id })
+	deletedSets := make(map[uuid.UUID]bool)
+	m.sets = slices.DeleteFunc(m.sets, func(s TrainingSet) bool {
+		deletedSets[s.Id] = s.TrainingId == id
+		return s.TrainingId == id
+	})
+	m.setResults = slices.DeleteFunc(m.setResults, func(r SetResult) bool {
+		return deletedSets[r.SetId]
+	})
+	m.attendance = slices.DeleteFunc(m.attendance, func(a Attendance) bool {
+		return a.TrainingId == id
+	})
+	for i, r := range m.raceResults {
+		if r.TrainingId != nil && *r.TrainingId == id {
+			m.raceResults[i].TrainingId = nil
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryRepository) TrainingDetails(
+	ctx context.Context,
+	page, pageSize int,
+) ([]Training, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	all := slices.Clone(m.trainings)
+	sort.SliceStable(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.After(b.Start)
+		} else if a.DurationMin != b.DurationMin {
+			return a.DurationMin < b.DurationMin
+		} else if a.TotalDistance != b.TotalDistance {
+			return a.TotalDistance < b.TotalDistance
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	from := min(page*pageSize, len(all))
+	to := min(from+pageSize, len(all))
+	tds := append(make([]Training, 0), all[from:to]...)
+
+	// the total is counted only over returned rows, as in postgres
+	count := 0
+	if len(tds) != 0 {
+		count = len(all)
+	}
+	return tds, count, nil
+}
+
+func (m *MemoryRepository) TrainingDetailsInRange(
+	ctx context.Context,
+	start, end time.Time,
+) ([]Training, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tds := make([]Training, 0)
+	for _, t := range m.trainings {
+		if trainingInRange(t, start, end) {
+			tds = append(tds, t)
+		}
+	}
+	sort.SliceStable(tds, func(i, j int) bool {
+		a, b := tds[i], tds[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		} else if a.DurationMin != b.DurationMin {
+			return a.DurationMin < b.DurationMin
+		} else if a.TotalDistance != b.TotalDistance {
+			return a.TotalDistance < b.TotalDistance
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	return tds, nil
+}
+
+func (m *MemoryRepository) Training(ctx context.Context, id uuid.UUID) (Training, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.trainingIndex(id)
+	if i == -1 {
+		return Training{}, fmt.Errorf("Training id doesnt exist: %w", ErrRowsNotFound)
+	}
+	t := m.trainings[i]
+
+	for _, s := range m.sets {
+		if s.TrainingId == id {
+			t.Sets = append(t.Sets, cloneSet(s))
+		}
+	}
+	// postgres selects training joined with its sets, so a training without
+	// sets isn't found either
+	if len(t.Sets) == 0 {
+		return Training{}, fmt.Errorf("Training id doesnt exist: %w", ErrRowsNotFound)
+	}
+	sort.SliceStable(t.Sets, func(i, j int) bool {
+		return t.Sets[i].SetOrder < t.Sets[j].SetOrder
+	})
+
+	return t, nil
+}
+
+func (m *MemoryRepository) EditTraining(
+	ctx context.Context,
+	id uuid.UUID,
+	t Training,
+) (Training, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.trainingIndex(id)
+	if i == -1 {
+		return Training{}, fmt.Errorf("editTraining not found: %w", ErrRowsNotFound)
+	}
+	for j, s := range t.Sets {
+		if m.setIndex(s.Id) == -1 && m.trainingIndex(s.TrainingId) == -1 {
+			return Training{}, fmt.Errorf("EditTraining set %d: %w", j, errForeignKey)
+		}
+	}
+
+	stored := &m.trainings[i]
+	stored.Start = t.Start.Truncate(time.Microsecond)
+	stored.DurationMin = t.DurationMin
+	stored.TotalDistance = t.TotalDistance
+	stored.PoolLength = t.PoolLength
+	stored.PoolUnit = t.PoolUnit
+	stored.ModifiedAt = timestamp()
+
+	sets := cloneSets(t.Sets)
+	for j, s := range sets {
+		// sets not in the edited training are kept, unknown sets are
+		// inserted under a new id and known ones are updated
+		k := m.setIndex(s.Id)
+		if k == -1 {
+			s.Id = uuid.New()
+			m.sets = append(m.sets, cloneSet(s))
+			sets[j] = s
+			continue
+		}
+
+		s.TrainingId = m.sets[k].TrainingId
+		m.sets[k] = cloneSet(s)
+		sets[j] = s
+		m.setResults = slices.DeleteFunc(m.setResults, func(r SetResult) bool {
+			return r.SetId == s.Id && len(r.TimesMs) != s.Repeat
+		})
+	}
+
+	t = *stored
+	t.Sets = sets
+	return t, nil
+}
+
+func (m *MemoryRepository) Set(ctx context.Context, id uuid.UUID) (TrainingSet, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.setIndex(id)
+	if i == -1 {
+		return TrainingSet{}, fmt.Errorf("Set id doesnt exist: %w", ErrRowsNotFound)
+	}
+	return cloneSet(m.sets[i]), nil
+}
+
+func (m *MemoryRepository) PersistUser(ctx context.Context, u User) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.userIndex(u.Id) != -1 {
+		return User{}, fmt.Errorf("PersistUser: %w", errDuplicateKey)
+	}
+
+	now := timestamp()
+	u.CreatedAt, u.ModifiedAt = now, now
+	m.users = append(m.users, u)
+	return u, nil
+}
+
+func (m *MemoryRepository) User(ctx context.Context, id uuid.UUID) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.userIndex(id)
+	if i == -1 {
+		return User{}, fmt.Errorf("User id doesnt exist: %w", ErrRowsNotFound)
+	}
+	return m.users[i], nil
+}
+
+func (m *MemoryRepository) Users(ctx context.Context) ([]User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := append(make([]User, 0), m.users...)
+	sort.SliceStable(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return users, nil
+}
+
+func (m *MemoryRepository) UpsertSetResult(ctx context.Context, r SetResult) (SetResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.setIndex(r.SetId) == -1 || m.userIndex(r.UserId) == -1 {
+		return SetResult{}, fmt.Errorf("UpsertSetResult: %w", errForeignKey)
+	}
+
+	now := timestamp()
+	r.TimesMs = slices.Clone(r.TimesMs)
+	for i, stored := range m.setResults {
+		if stored.SetId == r.SetId && stored.UserId == r.UserId {
+			r.CreatedAt, r.ModifiedAt = stored.CreatedAt, now
+			m.setResults[i] = r
+			return cloneSetResult(r), nil
+		}
+	}
+
+	r.CreatedAt, r.ModifiedAt = now, now
+	m.setResults = append(m.setResults, r)
+	return cloneSetResult(r), nil
+}
+
+func (m *MemoryRepository) SetResults(ctx context.Context, setId uuid.UUID) ([]SetResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]SetResult, 0)
+	for _, r := range m.setResults {
+		if r.SetId == setId {
+			results = append(results, cloneSetResult(r))
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].CreatedAt.Before(results[j].CreatedAt)
+	})
+
+	return results, nil
+}
+
+func (m *MemoryRepository) PersistRaceResult(ctx context.Context, r RaceResult) (RaceResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.ContainsFunc(m.raceResults, func(s RaceResult) bool { return s.Id == r.Id }) {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", errDuplicateKey)
+	} else if m.userIndex(r.UserId) == -1 {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", errForeignKey)
+	} else if r.TrainingId != nil && m.trainingIndex(*r.TrainingId) == -1 {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", errForeignKey)
+	}
+
+	now := timestamp()
+	r.TrainingId = clonePtr(r.TrainingId)
+	r.Date = date(r.Date)
+	r.CreatedAt, r.ModifiedAt = now, now
+	m.raceResults = append(m.raceResults, r)
+	r.IsPersonalBest = m.personalBest(r)
+	return cloneRaceResult(r), nil
+}
+
+// personalBest reports whether r is faster than every result of its user
+// in the same event swum before it.
+func (m *MemoryRepository) personalBest(r RaceResult) bool {
+	for _, s := range m.raceResults {
+		sameEvent := s.UserId == r.UserId &&
+			s.Distance == r.Distance &&
+			s.Stroke == r.Stroke &&
+			s.PoolLength == r.PoolLength &&
+			s.PoolUnit == r.PoolUnit
+		if sameEvent && raceResultBefore(s, r) && s.TimeMs <= r.TimeMs {
+			return false
+		}
+	}
+	return true
+}
+
+func raceResultBefore(a, b RaceResult) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.Before(b.Date)
+	} else if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return bytes.Compare(a.Id[:], b.Id[:]) < 0
+}
+
+func (m *MemoryRepository) RaceResults(ctx context.Context, userId uuid.UUID) ([]RaceResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]RaceResult, 0)
+	for _, r := range m.raceResults {
+		if r.UserId == userId {
+			r.IsPersonalBest = m.personalBest(r)
+			results = append(results, cloneRaceResult(r))
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Stroke != b.Stroke {
+			return slices.Index(strokeEnumOrder, a.Stroke) < slices.Index(strokeEnumOrder, b.Stroke)
+		} else if a.PoolUnit != b.PoolUnit {
+			return slices.Index(poolUnitEnumOrder, a.PoolUnit) <
+				slices.Index(poolUnitEnumOrder, b.PoolUnit)
+		} else if a.PoolLength != b.PoolLength {
+			return a.PoolLength < b.PoolLength
+		} else if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		} else if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	return results, nil
+}
+
+func (m *MemoryRepository) PersistCssTest(ctx context.Context, c CssTest) (CssTest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.ContainsFunc(m.cssTests, func(s CssTest) bool { return s.Id == c.Id }) {
+		return CssTest{}, fmt.Errorf("PersistCssTest: %w", errDuplicateKey)
+	} else if m.userIndex(c.UserId) == -1 {
+		return CssTest{}, fmt.Errorf("PersistCssTest: %w", errForeignKey)
+	}
+
+	now := timestamp()
+	c.Date = date(c.Date)
+	c.CreatedAt, c.ModifiedAt = now, now
+	m.cssTests = append(m.cssTests, c)
+	return c, nil
+}
+
+func (m *MemoryRepository) CssTests(ctx context.Context, userId uuid.UUID) ([]CssTest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.userCssTests(userId), nil
+}
+
+func (m *MemoryRepository) LatestCssTest(ctx context.Context, userId uuid.UUID) (CssTest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tests := m.userCssTests(userId)
+	if len(tests) == 0 {
+		return CssTest{}, fmt.Errorf("LatestCssTest user has no test: %w", ErrRowsNotFound)
+	}
+	return tests[0], nil
+}
+
+// UpsertAttendance marks attendance of all users, if any record is invalid
+// none are stored.
+func (m *MemoryRepository) UpsertAttendance(ctx context.Context, attendance []Attendance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, a := range attendance {
+		if m.trainingIndex(a.TrainingId) == -1 || m.userIndex(a.UserId) == -1 {
+			return fmt.Errorf("UpsertAttendance record %d: %w", i, errForeignKey)
+		}
+	}
+
+	now := timestamp()
+	for _, a := range attendance {
+		a.MetersCompleted = clonePtr(a.MetersCompleted)
+		i := slices.IndexFunc(m.attendance, func(s Attendance) bool {
+			return s.TrainingId == a.TrainingId && s.UserId == a.UserId
+		})
+		if i == -1 {
+			a.CreatedAt, a.ModifiedAt = now, now
+			m.attendance = append(m.attendance, a)
+			continue
+		}
+		a.CreatedAt, a.ModifiedAt = m.attendance[i].CreatedAt, now
+		m.attendance[i] = a
+	}
+
+	return nil
+}
+
+func (m *MemoryRepository) Attendance(ctx context.Context, trainingId uuid.UUID) ([]Attendance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	attendance := make([]Attendance, 0)
+	for _, u := range m.usersByNameAndId() {
+		for _, a := range m.attendance {
+			if a.TrainingId == trainingId && a.UserId == u.Id {
+				a.MetersCompleted = clonePtr(a.MetersCompleted)
+				attendance = append(attendance, a)
+			}
+		}
+	}
+
+	return attendance, nil
+}
+
+// AttendanceSummaries returns how many trainings started from start until
+// end and attendance summary of every user on them.
+func (m *MemoryRepository) AttendanceSummaries(
+	ctx context.Context,
+	start, end time.Time,
+) (int, []AttendanceSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inRange := make(map[uuid.UUID]Training)
+	for _, t := range m.trainings {
+		if trainingInRange(t, start, end) {
+			inRange[t.Id] = t
+		}
+	}
+
+	summaries := make([]AttendanceSummary, 0)
+	for _, u := range m.usersByNameAndId() {
+		s := AttendanceSummary{UserId: u.Id, Name: u.Name}
+		for _, a := range m.attendance {
+			t, ok := inRange[a.TrainingId]
+			if a.UserId != u.Id || !ok {
+				continue
+			}
+
+			switch a.Status {
+			case "present":
+				s.Present++
+				s.CompletedDistance += t.TotalDistance
+			case "partial":
+				s.Partial++
+				if a.MetersCompleted != nil {
+					s.CompletedDistance += *a.MetersCompleted
+				}
+			case "absent":
+				s.Absent++
+			case "excused":
+				s.Excused++
+			}
+		}
+		summaries = append(summaries, s)
+	}
+
+	return len(inRange), summaries, nil
+}
+
+func (m *MemoryRepository) trainingIndex(id uuid.UUID) int {
+	return slices.IndexFunc(m.trainings, func(t Training) bool { return t.Id == id })
+}
+
+func (m *MemoryRepository) setIndex(id uuid.UUID) int {
+	return slices.IndexFunc(m.sets, func(s TrainingSet) bool { return s.Id == id })
+}
+
+func (m *MemoryRepository) userIndex(id uuid.UUID) int {
+	return slices.IndexFunc(m.users, func(u User) bool { return u.Id == id })
+}
+
+// usersByNameAndId returns users in the order of attendance queries.
+func (m *MemoryRepository) usersByNameAndId() []User {
+	users := slices.Clone(m.users)
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return bytes.Compare(a.Id[:], b.Id[:]) < 0
+	})
+	return users
+}
+
+func (m *MemoryRepository) userCssTests(userId uuid.UUID) []CssTest {
+	tests := make([]CssTest, 0)
+	for _, c := range m.cssTests {
+		if c.UserId == userId {
+			tests = append(tests, c)
+		}
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		a, b := tests[i], tests[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+	return tests
+}
+
+// trainingInRange mirrors `t.start >= $1 and t.start < $2`.
+func trainingInRange(t Training, start, end time.Time) bool {
+	return !t.Start.Before(start) && t.Start.Before(end)
+}
+
+func cloneSets(sets []TrainingSet) []TrainingSet {
+	if sets == nil {
+		return nil
+	}
+	clones := make([]TrainingSet, len(sets))
+	for i, s := range sets {
+		clones[i] = cloneSet(s)
+	}
+	return clones
+}
+
+func cloneSet(s TrainingSet) TrainingSet {
+	s.Description = clonePtr(s.Description)
+	s.StartSeconds = clonePtr(s.StartSeconds)
+	s.Group = clonePtr(s.Group)
+	if s.Equipment != nil {
+		equipment := slices.Clone(*s.Equipment)
+		s.Equipment = &equipment
+	}
+	return s
+}
+
+func cloneSetResult(r SetResult) SetResult {
+	r.TimesMs = slices.Clone(r.TimesMs)
+	return r
+}
+
+func cloneRaceResult(r RaceResult) RaceResult {
+	r.TrainingId = clonePtr(r.TrainingId)
+	return r
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// Snapshot returns copies of all stored rows in insertion order.
+func (m *MemoryRepository) Snapshot(ctx context.Context) (Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.snapshot(), nil
+}
+
+func (m *MemoryRepository) snapshot() Snapshot {
+	s := Snapshot{
+		Users:       slices.Clone(m.users),
+		Trainings:   slices.Clone(m.trainings),
+		Sets:        cloneSets(m.sets),
+		SetResults:  make([]SetResult, len(m.setResults)),
+		RaceResults: make([]RaceResult, len(m.raceResults)),
+		CssTests:    slices.Clone(m.cssTests),
+		Attendance:  make([]Attendance, len(m.attendance)),
+	}
+	for i, r := range m.setResults {
+		s.SetResults[i] = cloneSetResult(r)
+	}
+	for i, r := range m.raceResults {
+		r.IsPersonalBest = m.personalBest(r)
+		s.RaceResults[i] = cloneRaceResult(r)
+	}
+	for i, a := range m.attendance {
+		a.MetersCompleted = clonePtr(a.MetersCompleted)
+		s.Attendance[i] = a
+	}
+	if s.Sets == nil {
+		s.Sets = make([]TrainingSet, 0)
+	}
+	return s
+}
+
+// Restore imports s all at once, nothing is changed if any row of s
+// references a missing row. With replace everything stored is deleted
+// first, otherwise rows of s overwrite stored rows with the same key and
+// other stored rows are kept. Sets of a restored training missing from s
+// are deleted with their results.
+func (m *MemoryRepository) Restore(ctx context.Context, s Snapshot, replace bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setIds := setIdsByTraining(s)
+	restored := &MemoryRepository{}
+	if !replace {
+		current := m.snapshot()
+		restored.users = current.Users
+		restored.trainings = current.Trainings
+		restored.sets = current.Sets
+		restored.setResults = current.SetResults
+		restored.raceResults = current.RaceResults
+		restored.cssTests = current.CssTests
+		restored.attendance = current.Attendance
+	}
+
+	for _, u := range s.Users {
+		restored.users = upsertRow(restored.users, u, func(a User) bool { return a.Id == u.Id })
+	}
+	for _, t := range s.Trainings {
+		t.Sets = nil
+		restored.trainings = upsertRow(restored.trainings, t, func(a Training) bool { return a.Id == t.Id })
+
+		deleted := map[uuid.UUID]bool{}
+		restored.sets = slices.DeleteFunc(restored.sets, func(set TrainingSet) bool {
+			deleted[set.Id] = set.TrainingId == t.Id && !slices.Contains(setIds[t.Id], set.Id)
+			return deleted[set.Id]
+		})
+		restored.setResults = slices.DeleteFunc(restored.setResults, func(r SetResult) bool {
+			return deleted[r.SetId]
+		})
+	}
+	for _, set := range s.Sets {
+		restored.sets = upsertRow(restored.sets, cloneSet(set), func(a TrainingSet) bool { return a.Id == set.Id })
+	}
+	for _, r := range s.SetResults {
+		restored.setResults = upsertRow(restored.setResults, cloneSetResult(r), func(a SetResult) bool {
+			return a.SetId == r.SetId && a.UserId == r.UserId
+		})
+	}
+	for _, r := range s.RaceResults {
+		r.Date = date(r.Date)
+		restored.raceResults = upsertRow(restored.raceResults, cloneRaceResult(r), func(a RaceResult) bool {
+			return a.Id == r.Id
+		})
+	}
+	for _, c := range s.CssTests {
+		c.Date = date(c.Date)
+		restored.cssTests = upsertRow(restored.cssTests, c, func(a CssTest) bool { return a.Id == c.Id })
+	}
+	for _, a := range s.Attendance {
+		a.MetersCompleted = clonePtr(a.MetersCompleted)
+		restored.attendance = upsertRow(restored.attendance, a, func(b Attendance) bool {
+			return b.TrainingId == a.TrainingId && b.UserId == a.UserId
+		})
+	}
+
+	if err := restored.checkReferences(); err != nil {
+		return fmt.Errorf("Restore: %w", err)
+	}
+
+	m.users = restored.users
+	m.trainings = restored.trainings
+	m.sets = restored.sets
+	m.setResults = restored.setResults
+	m.raceResults = restored.raceResults
+	m.cssTests = restored.cssTests
+	m.attendance = restored.attendance
+	return nil
+}
+
+// checkReferences checks that every row references existing rows, as
+// foreign keys do in postgres.
+func (m *MemoryRepository) checkReferences() error {
+	for _, s := range m.sets {
+		if m.trainingIndex(s.TrainingId) == -1 {
+			return fmt.Errorf("set %s training: %w", s.Id, errForeignKey)
+		}
+	}
+	for _, r := range m.setResults {
+		if m.setIndex(r.SetId) == -1 || m.userIndex(r.UserId) == -1 {
+			return fmt.Errorf("set result of set %s: %w", r.SetId, errForeignKey)
+		}
+	}
+	for _, r := range m.raceResults {
+		if m.userIndex(r.UserId) == -1 || (r.TrainingId != nil && m.trainingIndex(*r.TrainingId) == -1) {
+			return fmt.Errorf("race result %s: %w", r.Id, errForeignKey)
+		}
+	}
+	for _, c := range m.cssTests {
+		if m.userIndex(c.UserId) == -1 {
+			return fmt.Errorf("css test %s user: %w", c.Id, errForeignKey)
+		}
+	}
+	for _, a := range m.attendance {
+		if m.trainingIndex(a.TrainingId) == -1 || m.userIndex(a.UserId) == -1 {
+			return fmt.Errorf("attendance of training %s: %w", a.TrainingId, errForeignKey)
+		}
+	}
+	return nil
+}
+
+// upsertRow replaces the first row of all which is the same as v, or
+// appends v if there is none.
+func upsertRow[T any](all []T, v T, same func(T) bool) []T {
+	if i := slices.IndexFunc(all, same); i != -1 {
+		all[i] = v
+		return all
+	}
+	return append(all, v)
+}
@@ -0,0 +1,14 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/data/datatest"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	datatest.RunContract(t, func(t *testing.T) data.Repository {
+		return data.NewMemoryRepository()
+	})
+}
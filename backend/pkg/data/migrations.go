@@ -1,40 +1,173 @@
 package data
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog/log"
 )
 
-const MigrationsLocationFormat = "file://%s"
+// MigrationStatus is the schema version of a database compared to its
+// migrations.
+type MigrationStatus struct {
+	// Version is the applied schema version, 0 if no migration was applied
+	Version uint
+	Dirty   bool
+	Latest  uint
+	// Available are versions of all migrations, in ascending order
+	Available []uint
+}
 
-func (pool *PostgresDbPool) MigrateUp(showLogs bool) error {
-	migrationsFileUrl := fmt.Sprintf(MigrationsLocationFormat, pool.migrationsDir)
-	m, err := migrate.New(migrationsFileUrl, pool.conStr)
+func (s MigrationStatus) UpToDate() bool {
+	return !s.Dirty && s.Version == s.Latest
+}
+
+// Pending are versions of migrations newer than the applied one.
+func (s MigrationStatus) Pending() []uint {
+	var pending []uint
+	for _, v := range s.Available {
+		if v > s.Version {
+			pending = append(pending, v)
+		}
+	}
+	return pending
+}
+
+var selectMigrationsTableExists = `
+select to_regclass('schema_migrations') is not null
+`
+
+var selectMigrationVersion = `
+select version, dirty from schema_migrations limit 1
+`
+
+func (pool *PostgresDbPool) Migrations(ctx context.Context) (MigrationStatus, error) {
+	status, err := availableMigrations(pool.migrations)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("Migrations: %w", err)
+	}
+
+	var exists bool
+	if err := pool.QueryRow(ctx, selectMigrationsTableExists).Scan(&exists); err != nil {
+		return MigrationStatus{}, fmt.Errorf("Migrations: %w", err)
+	} else if !exists {
+		return status, nil
+	}
+
+	var version int64
+	err = pool.QueryRow(ctx, selectMigrationVersion).Scan(&version, &status.Dirty)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return MigrationStatus{}, fmt.Errorf("Migrations: %w", err)
+	}
+	status.Version = uint(max(version, 0))
+
+	return status, nil
+}
+
+// availableMigrations returns status with versions of all migrations found
+// in migrations, and nothing applied.
+func availableMigrations(migrations fs.FS) (MigrationStatus, error) {
+	src, err := migrationsSource(migrations)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("availableMigrations: %w", err)
+	}
+	defer src.Close()
+
+	var status MigrationStatus
+	version, err := src.First()
+	for err == nil {
+		status.Available = append(status.Available, version)
+		status.Latest = version
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return MigrationStatus{}, fmt.Errorf("availableMigrations: %w", err)
+	}
+	return status, nil
+}
+
+// migrationsSource opens golang-migrate source of migrations, which are
+// files named like 000001_trainings.up.sql in the root of migrations.
+func migrationsSource(migrations fs.FS) (source.Driver, error) {
+	src, err := iofs.New(migrations, ".")
 	if err != nil {
-		return fmt.Errorf("MigrateUp init: %w", err)
+		return nil, fmt.Errorf("migrationsSource: %w", err)
+	}
+	return src, nil
+}
+
+// Migrator manages schema of a database with its migrations. Migrations which would leave the schema unchanged
+// aren't an error.
+type Migrator interface {
+	// MigrateUp applies all pending migrations.
+	MigrateUp(showLogs bool) error
+	// MigrateDown reverts last steps applied migrations.
+	MigrateDown(steps uint, showLogs bool) error
+	// MigrateTo applies or reverts migrations until schema is at version.
+	MigrateTo(version uint, showLogs bool) error
+	// ForceMigration sets schema version without running any migration and
+	// clears the dirty flag, -1 means no migration is applied. It is used
+	// after manually fixing a failed migration.
+	ForceMigration(version int) error
+}
+
+// migrationRunner opens golang-migrate instance for a database, close
+// releases what the instance opened.
+type migrationRunner interface {
+	migrate(showLogs bool) (m *migrate.Migrate, close func(), err error)
+}
+
+func (pool *PostgresDbPool) migrate(showLogs bool) (*migrate.Migrate, func(), error) {
+	src, err := migrationsSource(pool.migrations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("migrate: %w", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, pool.conStr)
+	if err != nil {
+		src.Close()
+		return nil, nil, fmt.Errorf("migrate init: %w", err)
 	}
 	m.Log = zerologLogger{showLogs: showLogs, verbose: true}
 
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("MigrateUp: %w", err)
+	close := func() {
+		if srcErr, dbErr := m.Close(); srcErr != nil || dbErr != nil {
+			log.Error().Err(errors.Join(srcErr, dbErr)).Msg("failed to close migrations")
+		}
 	}
+	return m, close, nil
+}
 
-	return nil
+func (pool *PostgresDbPool) MigrateUp(showLogs bool) error {
+	return migrateUp(pool, showLogs)
+}
+
+func (pool *PostgresDbPool) MigrateDown(steps uint, showLogs bool) error {
+	return migrateDown(pool, steps, showLogs)
+}
+
+func (pool *PostgresDbPool) MigrateTo(version uint, showLogs bool) error {
+	return migrateTo(pool, version, showLogs)
+}
+
+func (pool *PostgresDbPool) ForceMigration(version int) error {
+	return forceMigration(pool, version)
 }
 
 func (pool *PostgresDbPool) CleanMigrateUp(showLogs bool) error {
-	migrationsFileUrl := fmt.Sprintf(MigrationsLocationFormat, pool.migrationsDir)
-	m, err := migrate.New(migrationsFileUrl, pool.conStr)
+	m, close, err := pool.migrate(showLogs)
 	if err != nil {
-		return fmt.Errorf("CleanMigrateUp init: %w", err)
+		return fmt.Errorf("CleanMigrateUp: %w", err)
 	}
-	m.Log = zerologLogger{showLogs: showLogs, verbose: true}
+	defer close()
 
 	err = m.Down()
 	if err != nil && err != migrate.ErrNoChange {
@@ -51,6 +184,65 @@ func (pool *PostgresDbPool) CleanMigrateUp(showLogs bool) error {
 	return nil
 }
 
+func migrateUp(db migrationRunner, showLogs bool) error {
+	m, close, err := db.migrate(showLogs)
+	if err != nil {
+		return fmt.Errorf("MigrateUp: %w", err)
+	}
+	defer close()
+
+	err = m.Up()
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("MigrateUp: %w", err)
+	}
+	return nil
+}
+
+func migrateDown(db migrationRunner, steps uint, showLogs bool) error {
+	if steps == 0 {
+		return nil
+	}
+
+	m, close, err := db.migrate(showLogs)
+	if err != nil {
+		return fmt.Errorf("MigrateDown: %w", err)
+	}
+	defer close()
+
+	err = m.Steps(-int(steps))
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("MigrateDown: %w", err)
+	}
+	return nil
+}
+
+func migrateTo(db migrationRunner, version uint, showLogs bool) error {
+	m, close, err := db.migrate(showLogs)
+	if err != nil {
+		return fmt.Errorf("MigrateTo: %w", err)
+	}
+	defer close()
+
+	err = m.Migrate(version)
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("MigrateTo: %w", err)
+	}
+	return nil
+}
+
+func forceMigration(db migrationRunner, version int) error {
+	m, close, err := db.migrate(false)
+	if err != nil {
+		return fmt.Errorf("ForceMigration: %w", err)
+	}
+	defer close()
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("ForceMigration: %w", err)
+	}
+	return nil
+}
+
 type zerologLogger struct {
 	showLogs bool
 	verbose  bool
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RaceResult is a time trial of a user. IsPersonalBest is derived on read
+// and ignored on write.
+type RaceResult struct {
+	Id             uuid.UUID
+	UserId         uuid.UUID
+	TrainingId     *uuid.UUID
+	Distance       int
+	Stroke         string
+	PoolLength     int
+	PoolUnit       string
+	TimeMs         int
+	Date           time.Time
+	IsPersonalBest bool
+
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+var insertRaceResult = `
+insert into race_results (id, user_id, training_id, distance, stroke, pool_length, pool_unit,
+    time_ms, date, created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, now(), now())
+returning id
+`
+
+// PersistRaceResult inserts r and returns it as read back, with its
+// personal best flag.
+func (pool *PostgresDbPool) PersistRaceResult(ctx context.Context, r RaceResult) (RaceResult, error) {
+	err := pool.QueryRow(
+		ctx,
+		insertRaceResult,
+		r.Id,
+		r.UserId,
+		r.TrainingId,
+		r.Distance,
+		r.Stroke,
+		r.PoolLength,
+		r.PoolUnit,
+		r.TimeMs,
+		r.Date,
+	).Scan(&r.Id)
+	if err != nil {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", err)
+	}
+
+	r, err = scanRaceResult(pool.QueryRow(ctx, selectRaceResult, r.UserId, r.Id))
+	if err != nil {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", err)
+	}
+	return r, nil
+}
+
+// raceResultsOfUser are results of user $1, a result is a personal best if
+// it is faster than every result of the user in the same event swum before
+// it. Derived on read, so back-dated results are accounted for.
+var raceResultsOfUser = `
+select r.id, r.user_id, r.training_id, r.distance, r.stroke, r.pool_length, r.pool_unit,
+    r.time_ms, r.date,
+    coalesce(r.time_ms < min(r.time_ms) over earlier, true) as is_personal_best,
+    r.created_at, r.modified_at
+from race_results r
+where r.user_id = $1
+window earlier as (
+    partition by r.distance, r.stroke, r.pool_length, r.pool_unit
+    order by r.date, r.created_at, r.id
+    rows between unbounded preceding and 1 preceding
+)
+`
+
+var selectRaceResult = `
+select r.id, r.user_id, r.training_id, r.distance, r.stroke, r.pool_length, r.pool_unit,
+    r.time_ms, r.date, r.is_personal_best, r.created_at, r.modified_at
+from (` + raceResultsOfUser + `) r
+where r.id = $2
+`
+
+var selectRaceResults = `
+select r.id, r.user_id, r.training_id, r.distance, r.stroke, r.pool_length, r.pool_unit,
+    r.time_ms, r.date, r.is_personal_best, r.created_at, r.modified_at
+from (` + raceResultsOfUser + `) r
+order by r.stroke, r.pool_unit, r.pool_length, r.distance, r.date, r.created_at
+`
+
+func (pool *PostgresDbPool) RaceResults(ctx context.Context, userId uuid.UUID) ([]RaceResult, error) {
+	results := make([]RaceResult, 0)
+
+	rows, err := pool.Query(ctx, selectRaceResults, userId)
+	if err != nil {
+		return nil, fmt.Errorf("RaceResults query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r, err := scanRaceResult(rows)
+		if err != nil {
+			return nil, fmt.Errorf("RaceResults scanning row: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+func scanRaceResult(row pgx.Row) (RaceResult, error) {
+	var r RaceResult
+	err := row.Scan(
+		&r.Id,
+		&r.UserId,
+		&r.TrainingId,
+		&r.Distance,
+		&r.Stroke,
+		&r.PoolLength,
+		&r.PoolUnit,
+		&r.TimeMs,
+		&r.Date,
+		&r.IsPersonalBest,
+		&r.CreatedAt,
+		&r.ModifiedAt,
+	)
+	return r, err
+}
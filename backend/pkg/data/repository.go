@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository is everything the app needs to store, it is implemented by
+// PostgresDbPool, SqliteDb and by the in-memory MemoryRepository.
+type Repository interface {
+	TrainingRepository
+	UserRepository
+	SetResultRepository
+	RaceResultRepository
+	CssTestRepository
+	AttendanceRepository
+	BackupRepository
+}
+
+// Database is a Repository whose schema is managed by migrations.
+type Database interface {
+	Repository
+	HealthChecker
+	Migrator
+	Close()
+}
+
+// HealthChecker reports whether a database is able to serve requests.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+	Migrations(ctx context.Context) (MigrationStatus, error)
+}
+
+var (
+	_ Database   = (*PostgresDbPool)(nil)
+	_ Database   = (*SqliteDb)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+)
+
+type TrainingRepository interface {
+	PersistTraining(ctx context.Context, t Training) (Training, error)
+	// PersistNewTrainings persists trainings in one transaction, skipping
+	// ones whose ids are already stored, and returns the persisted ones
+	PersistNewTrainings(ctx context.Context, ts []Training) ([]Training, error)
+	DeleteTraining(ctx context.Context, id uuid.UUID) error
+	TrainingDetails(ctx context.Context, page, pageSize int) ([]Training, int, error)
+	// TrainingDetailsInRange returns trainings which started from start
+	// until end, excluding end
+	TrainingDetailsInRange(ctx context.Context, start, end time.Time) ([]Training, error)
+	Training(ctx context.Context, id uuid.UUID) (Training, error)
+	// EditTraining updates the training and its known sets and inserts the
+	// unknown ones. Results of a set whose repeat no longer matches their
+	// times are deleted.
+	EditTraining(ctx context.Context, id uuid.UUID, t Training) (Training, error)
+	Set(ctx context.Context, id uuid.UUID) (TrainingSet, error)
+}
+
+type UserRepository interface {
+	PersistUser(ctx context.Context, u User) (User, error)
+	User(ctx context.Context, id uuid.UUID) (User, error)
+	Users(ctx context.Context) ([]User, error)
+}
+
+type SetResultRepository interface {
+	UpsertSetResult(ctx context.Context, r SetResult) (SetResult, error)
+	SetResults(ctx context.Context, setId uuid.UUID) ([]SetResult, error)
+}
+
+type RaceResultRepository interface {
+	PersistRaceResult(ctx context.Context, r RaceResult) (RaceResult, error)
+	RaceResults(ctx context.Context, userId uuid.UUID) ([]RaceResult, error)
+}
+
+type CssTestRepository interface {
+	PersistCssTest(ctx context.Context, c CssTest) (CssTest, error)
+	CssTests(ctx context.Context, userId uuid.UUID) ([]CssTest, error)
+	LatestCssTest(ctx context.Context, userId uuid.UUID) (CssTest, error)
+}
+
+type AttendanceRepository interface {
+	UpsertAttendance(ctx context.Context, attendance []Attendance) error
+	Attendance(ctx context.Context, trainingId uuid.UUID) ([]Attendance, error)
+	// AttendanceSummaries summarizes trainings which started from start until
+	// end, excluding end
+	AttendanceSummaries(ctx context.Context, start, end time.Time) (int, []AttendanceSummary, error)
+}
+
+type BackupRepository interface {
+	// Snapshot returns everything stored, consistent at one point in time.
+	Snapshot(ctx context.Context) (Snapshot, error)
+	// Restore imports s all at once. With replace everything stored is
+	// deleted first, otherwise rows of s overwrite stored rows with the same
+	// key and other stored rows are kept.
+	Restore(ctx context.Context, s Snapshot, replace bool) error
+}
@@ -0,0 +1,96 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type SetResult struct {
+	SetId   uuid.UUID
+	UserId  uuid.UUID
+	TimesMs []int
+
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+var selectSet = `
+select s.id, s.training_id, s.set_order, s.repeat, s.distance_meters, s.description,
+    s.start_type, s.start_seconds, s.total_distance, s.equipment, s.group
+from sets s
+where s.id = $1
+`
+
+func (pool *PostgresDbPool) Set(ctx context.Context, id uuid.UUID) (TrainingSet, error) {
+	var s TrainingSet
+	err := pool.QueryRow(ctx, selectSet, id).Scan(
+		&s.Id,
+		&s.TrainingId,
+		&s.SetOrder,
+		&s.Repeat,
+		&s.DistanceMeters,
+		&s.Description,
+		&s.StartType,
+		&s.StartSeconds,
+		&s.TotalDistance,
+		&s.Equipment,
+		&s.Group,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TrainingSet{}, fmt.Errorf("Set id doesnt exist: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return TrainingSet{}, fmt.Errorf("Set query error: %w", err)
+	}
+	return s, nil
+}
+
+var upsertSetResult = `
+insert into set_results (set_id, user_id, times_ms, created_at, modified_at)
+values ($1, $2, $3, now(), now())
+on conflict (set_id, user_id) do update
+set times_ms    = excluded.times_ms,
+    modified_at = now()
+returning set_id, user_id, times_ms, created_at, modified_at
+`
+
+func (pool *PostgresDbPool) UpsertSetResult(ctx context.Context, r SetResult) (SetResult, error) {
+	err := pool.QueryRow(ctx, upsertSetResult, r.SetId, r.UserId, r.TimesMs).
+		Scan(&r.SetId, &r.UserId, &r.TimesMs, &r.CreatedAt, &r.ModifiedAt)
+	if err != nil {
+		return SetResult{}, fmt.Errorf("UpsertSetResult: %w", err)
+	}
+	return r, nil
+}
+
+var selectSetResults = `
+select r.set_id, r.user_id, r.times_ms, r.created_at, r.modified_at
+from set_results r
+where r.set_id = $1
+order by r.created_at
+`
+
+func (pool *PostgresDbPool) SetResults(ctx context.Context, setId uuid.UUID) ([]SetResult, error) {
+	results := make([]SetResult, 0)
+
+	rows, err := pool.Query(ctx, selectSetResults, setId)
+	if err != nil {
+		return nil, fmt.Errorf("SetResults query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r SetResult
+		err := rows.Scan(&r.SetId, &r.UserId, &r.TimesMs, &r.CreatedAt, &r.ModifiedAt)
+		if err != nil {
+			return nil, fmt.Errorf("SetResults scanning row: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
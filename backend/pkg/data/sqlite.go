@@ -0,0 +1,193 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/rs/zerolog/log"
+)
+
+// SqliteDb is a Repository stored in a single sqlite file, for self-hosting
+// without a postgres server. It uses the schema from the sqlite directory of
+// migrations, where enums are text columns with check constraints and
+// arrays are json.
+type SqliteDb struct {
+	migrations fs.FS
+	*sql.DB
+}
+
+func NewSqliteDb(path string, migrations fs.FS) (*SqliteDb, error) {
+	dsn := fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(wal)&_time_format=sqlite",
+		path,
+	)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("NewSqliteDb open: %w", err)
+	}
+
+	// sqlite has only one writer at a time, one connection serializes all
+	// queries instead of failing them with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("NewSqliteDb ping: %w", err)
+	}
+
+	return &SqliteDb{migrations, db}, nil
+}
+
+func (db *SqliteDb) Close() {
+	if err := db.DB.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close sqlite db")
+	}
+}
+
+func (db *SqliteDb) Ping(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+var sqliteSelectMigrationsTableExists = `
+select exists (select 1 from sqlite_master where type = 'table' and name = 'schema_migrations')
+`
+
+func (db *SqliteDb) Migrations(ctx context.Context) (MigrationStatus, error) {
+	status, err := availableMigrations(db.migrations)
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("Migrations: %w", err)
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, sqliteSelectMigrationsTableExists).Scan(&exists); err != nil {
+		return MigrationStatus{}, fmt.Errorf("Migrations: %w", err)
+	} else if !exists {
+		return status, nil
+	}
+
+	var version int64
+	err = db.QueryRowContext(ctx, selectMigrationVersion).Scan(&version, &status.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return MigrationStatus{}, fmt.Errorf("Migrations: %w", err)
+	}
+	status.Version = uint(max(version, 0))
+
+	return status, nil
+}
+
+func (db *SqliteDb) migrate(showLogs bool) (*migrate.Migrate, func(), error) {
+	driver, err := sqlite.WithInstance(db.DB, &sqlite.Config{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("migrate driver: %w", err)
+	}
+
+	src, err := migrationsSource(db.migrations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("migrate: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	if err != nil {
+		src.Close()
+		return nil, nil, fmt.Errorf("migrate init: %w", err)
+	}
+	m.Log = zerologLogger{showLogs: showLogs, verbose: true}
+
+	// closing m would close the shared sql.DB too
+	close := func() {
+		if err := src.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close migrations")
+		}
+	}
+	return m, close, nil
+}
+
+func (db *SqliteDb) MigrateUp(showLogs bool) error {
+	return migrateUp(db, showLogs)
+}
+
+func (db *SqliteDb) MigrateDown(steps uint, showLogs bool) error {
+	return migrateDown(db, steps, showLogs)
+}
+
+func (db *SqliteDb) MigrateTo(version uint, showLogs bool) error {
+	return migrateTo(db, version, showLogs)
+}
+
+func (db *SqliteDb) ForceMigration(version int) error {
+	return forceMigration(db, version)
+}
+
+func sqliteTx(ctx context.Context, db *SqliteDb, f func(*sql.Tx) error) error {
+	_, err := sqliteTxWithResult(ctx, db, func(tx *sql.Tx) (struct{}, error) {
+		return struct{}{}, f(tx)
+	})
+	return err
+}
+
+func sqliteTxWithResult[R any](
+	ctx context.Context,
+	db *SqliteDb,
+	f func(*sql.Tx) (R, error),
+) (R, error) {
+	var res R
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return res, fmt.Errorf("sqliteTxWithResult init: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err = f(tx)
+	if err != nil {
+		return res, fmt.Errorf("sqliteTxWithResult: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return res, fmt.Errorf("sqliteTxWithResult commit: %w", err)
+	}
+
+	return res, nil
+}
+
+// sqliteTime is how timestamps are stored, always in UTC so that they can
+// be compared as text.
+func sqliteTime(t time.Time) time.Time {
+	return t.UTC()
+}
+
+// sqliteDate is how dates are stored and compared.
+func sqliteDate(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func toSqliteJson[T any](v *T) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("toSqliteJson: %w", err)
+	}
+	// nil slices are stored as null, as pgx does with arrays
+	if string(b) == "null" {
+		return nil, nil
+	}
+	s := string(b)
+	return &s, nil
+}
+
+func fromSqliteJson[T any](s *string) (*T, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var v T
+	if err := json.Unmarshal([]byte(*s), &v); err != nil {
+		return nil, fmt.Errorf("fromSqliteJson: %w", err)
+	}
+	return &v, nil
+}
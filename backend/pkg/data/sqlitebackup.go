@@ -0,0 +1,271 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Snapshot reads all tables in one transaction, sqlite has only one writer
+// so the snapshot is consistent.
+func (db *SqliteDb) Snapshot(ctx context.Context) (Snapshot, error) {
+	return sqliteTxWithResult(ctx, db, func(tx *sql.Tx) (Snapshot, error) {
+		var s Snapshot
+		var err error
+
+		s.Users, err = sqliteSelectAll(ctx, tx, selectAllUsers, func(rows *sql.Rows, u *User) error {
+			err := rows.Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+			*u = sqliteLocalUser(*u)
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot users: %w", err)
+		}
+
+		s.Trainings, err = sqliteSelectAll(ctx, tx, selectAllTrainings, func(rows *sql.Rows, t *Training) error {
+			err := rows.Scan(sqliteTrainingDest(t)...)
+			*t = sqliteLocalTraining(*t)
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot trainings: %w", err)
+		}
+
+		s.Sets, err = sqliteSelectAll(ctx, tx, selectAllSets, func(rows *sql.Rows, set *TrainingSet) error {
+			var equipment *string
+			if err := rows.Scan(sqliteSetDest(set, &equipment)...); err != nil {
+				return err
+			}
+			eq, err := fromSqliteJson[[]string](equipment)
+			set.Equipment = eq
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot sets: %w", err)
+		}
+
+		s.SetResults, err = sqliteSelectAll(ctx, tx, selectAllSetResults, func(rows *sql.Rows, r *SetResult) error {
+			var timesMs *string
+			if err := rows.Scan(&r.SetId, &r.UserId, &timesMs, &r.CreatedAt, &r.ModifiedAt); err != nil {
+				return err
+			}
+			res, err := sqliteSetResult(*r, timesMs)
+			*r = res
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot set results: %w", err)
+		}
+
+		s.RaceResults, err = sqliteSelectAll(ctx, tx, selectAllRaceResults, func(rows *sql.Rows, r *RaceResult) error {
+			err := rows.Scan(sqliteRaceResultDest(r)...)
+			*r = sqliteLocalRaceResult(*r)
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot race results: %w", err)
+		}
+
+		s.CssTests, err = sqliteSelectAll(ctx, tx, selectAllCssTests, func(rows *sql.Rows, c *CssTest) error {
+			err := rows.Scan(sqliteCssTestDest(c)...)
+			*c = sqliteLocalCssTest(*c)
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot css tests: %w", err)
+		}
+
+		s.Attendance, err = sqliteSelectAll(ctx, tx, selectAllAttendance, func(rows *sql.Rows, a *Attendance) error {
+			err := rows.Scan(&a.TrainingId, &a.UserId, &a.Status, &a.MetersCompleted, &a.CreatedAt, &a.ModifiedAt)
+			a.CreatedAt = a.CreatedAt.Local()
+			a.ModifiedAt = a.ModifiedAt.Local()
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("Snapshot attendance: %w", err)
+		}
+
+		return s, nil
+	})
+}
+
+func sqliteSelectAll[T any](
+	ctx context.Context,
+	tx *sql.Tx,
+	query string,
+	scan func(*sql.Rows, *T) error,
+) ([]T, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("sqliteSelectAll query error: %w", err)
+	}
+	defer rows.Close()
+
+	all := make([]T, 0)
+	for rows.Next() {
+		var v T
+		if err := scan(rows, &v); err != nil {
+			return nil, fmt.Errorf("sqliteSelectAll scanning row: %w", err)
+		}
+		all = append(all, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqliteSelectAll rows: %w", err)
+	}
+	return all, nil
+}
+
+var sqliteDeleteRestoredSets = `
+delete from sets
+where training_id = $1 and id not in (select value from json_each($2))
+`
+
+// Restore imports s in one transaction. With replace everything stored is
+// deleted first, otherwise rows of s overwrite stored rows with the same
+// key and other stored rows are kept. Sets of a restored training missing
+// from s are deleted.
+func (db *SqliteDb) Restore(ctx context.Context, s Snapshot, replace bool) error {
+	setIds := setIdsByTraining(s)
+	return sqliteTx(ctx, db, func(tx *sql.Tx) error {
+		if replace {
+			for _, table := range backupTables {
+				if _, err := tx.ExecContext(ctx, "delete from "+table); err != nil {
+					return fmt.Errorf("Restore deleting %s: %w", table, err)
+				}
+			}
+		}
+
+		for i, u := range s.Users {
+			_, err := tx.ExecContext(
+				ctx,
+				restoreUser,
+				u.Id,
+				u.Name,
+				sqliteTime(u.CreatedAt),
+				sqliteTime(u.ModifiedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("Restore user %d: %w", i, err)
+			}
+		}
+		for i, t := range s.Trainings {
+			_, err := tx.ExecContext(
+				ctx,
+				restoreTraining,
+				t.Id,
+				sqliteTime(t.Start),
+				t.DurationMin,
+				t.TotalDistance,
+				t.PoolLength,
+				t.PoolUnit,
+				sqliteTime(t.CreatedAt),
+				sqliteTime(t.ModifiedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("Restore training %d: %w", i, err)
+			}
+			ids := setIds[t.Id]
+			kept, err := toSqliteJson(&ids)
+			if err != nil {
+				return fmt.Errorf("Restore training %d set ids: %w", i, err)
+			}
+			if _, err := tx.ExecContext(ctx, sqliteDeleteRestoredSets, t.Id, kept); err != nil {
+				return fmt.Errorf("Restore training %d deleting sets: %w", i, err)
+			}
+		}
+		for i, set := range s.Sets {
+			equipment, err := toSqliteJson(set.Equipment)
+			if err != nil {
+				return fmt.Errorf("Restore set %d equipment: %w", i, err)
+			}
+			_, err = tx.ExecContext(
+				ctx,
+				restoreSet,
+				set.Id,
+				set.TrainingId,
+				set.SetOrder,
+				set.Repeat,
+				set.DistanceMeters,
+				set.Description,
+				set.StartType,
+				set.StartSeconds,
+				set.TotalDistance,
+				equipment,
+				set.Group,
+			)
+			if err != nil {
+				return fmt.Errorf("Restore set %d: %w", i, err)
+			}
+		}
+		for i, r := range s.SetResults {
+			timesMs, err := toSqliteJson(&r.TimesMs)
+			if err != nil {
+				return fmt.Errorf("Restore set result %d times: %w", i, err)
+			}
+			_, err = tx.ExecContext(
+				ctx,
+				restoreSetResult,
+				r.SetId,
+				r.UserId,
+				timesMs,
+				sqliteTime(r.CreatedAt),
+				sqliteTime(r.ModifiedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("Restore set result %d: %w", i, err)
+			}
+		}
+		for i, r := range s.RaceResults {
+			_, err := tx.ExecContext(
+				ctx,
+				restoreRaceResult,
+				r.Id,
+				r.UserId,
+				r.TrainingId,
+				r.Distance,
+				r.Stroke,
+				r.PoolLength,
+				r.PoolUnit,
+				r.TimeMs,
+				sqliteDate(r.Date),
+				sqliteTime(r.CreatedAt),
+				sqliteTime(r.ModifiedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("Restore race result %d: %w", i, err)
+			}
+		}
+		for i, c := range s.CssTests {
+			_, err := tx.ExecContext(
+				ctx,
+				restoreCssTest,
+				c.Id,
+				c.UserId,
+				c.Time400Ms,
+				c.Time200Ms,
+				sqliteDate(c.Date),
+				sqliteTime(c.CreatedAt),
+				sqliteTime(c.ModifiedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("Restore css test %d: %w", i, err)
+			}
+		}
+		for i, a := range s.Attendance {
+			_, err := tx.ExecContext(
+				ctx,
+				restoreAttendance,
+				a.TrainingId,
+				a.UserId,
+				a.Status,
+				a.MetersCompleted,
+				sqliteTime(a.CreatedAt),
+				sqliteTime(a.ModifiedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("Restore attendance %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
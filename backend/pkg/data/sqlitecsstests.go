@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var sqliteInsertCssTest = `
+insert into css_tests (id, user_id, time_400_ms, time_200_ms, date, created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $6)
+returning id, user_id, time_400_ms, time_200_ms, date, created_at, modified_at
+`
+
+func (db *SqliteDb) PersistCssTest(ctx context.Context, c CssTest) (CssTest, error) {
+	err := db.QueryRowContext(
+		ctx,
+		sqliteInsertCssTest,
+		c.Id,
+		c.UserId,
+		c.Time400Ms,
+		c.Time200Ms,
+		sqliteDate(c.Date),
+		sqliteTime(timestamp()),
+	).Scan(sqliteCssTestDest(&c)...)
+	if err != nil {
+		return CssTest{}, fmt.Errorf("PersistCssTest: %w", err)
+	}
+	return sqliteLocalCssTest(c), nil
+}
+
+var sqliteSelectCssTests = `
+select c.id, c.user_id, c.time_400_ms, c.time_200_ms, c.date, c.created_at, c.modified_at
+from css_tests c
+where c.user_id = $1
+order by c.date desc, c.created_at desc
+`
+
+func (db *SqliteDb) CssTests(ctx context.Context, userId uuid.UUID) ([]CssTest, error) {
+	tests := make([]CssTest, 0)
+
+	rows, err := db.QueryContext(ctx, sqliteSelectCssTests, userId)
+	if err != nil {
+		return nil, fmt.Errorf("CssTests query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c CssTest
+		err := rows.Scan(sqliteCssTestDest(&c)...)
+		if err != nil {
+			return nil, fmt.Errorf("CssTests scanning row: %w", err)
+		}
+		tests = append(tests, sqliteLocalCssTest(c))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CssTests rows: %w", err)
+	}
+
+	return tests, nil
+}
+
+var sqliteSelectLatestCssTest = `
+select c.id, c.user_id, c.time_400_ms, c.time_200_ms, c.date, c.created_at, c.modified_at
+from css_tests c
+where c.user_id = $1
+order by c.date desc, c.created_at desc
+limit 1
+`
+
+func (db *SqliteDb) LatestCssTest(ctx context.Context, userId uuid.UUID) (CssTest, error) {
+	var c CssTest
+	err := db.QueryRowContext(ctx, sqliteSelectLatestCssTest, userId).Scan(sqliteCssTestDest(&c)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CssTest{}, fmt.Errorf("LatestCssTest user has no test: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return CssTest{}, fmt.Errorf("LatestCssTest query error: %w", err)
+	}
+	return sqliteLocalCssTest(c), nil
+}
+
+func sqliteCssTestDest(c *CssTest) []any {
+	return []any{&c.Id, &c.UserId, &c.Time400Ms, &c.Time200Ms, &c.Date, &c.CreatedAt, &c.ModifiedAt}
+}
+
+func sqliteLocalCssTest(c CssTest) CssTest {
+	c.CreatedAt = c.CreatedAt.Local()
+	c.ModifiedAt = c.ModifiedAt.Local()
+	return c
+}
@@ -0,0 +1,134 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var sqliteInsertRaceResult = `
+insert into race_results (id, user_id, training_id, distance, stroke, pool_length, pool_unit,
+    time_ms, date, created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+`
+
+// PersistRaceResult inserts r and returns it as read back, with its
+// personal best flag.
+func (db *SqliteDb) PersistRaceResult(ctx context.Context, r RaceResult) (RaceResult, error) {
+	_, err := db.ExecContext(
+		ctx,
+		sqliteInsertRaceResult,
+		r.Id,
+		r.UserId,
+		r.TrainingId,
+		r.Distance,
+		r.Stroke,
+		r.PoolLength,
+		r.PoolUnit,
+		r.TimeMs,
+		sqliteDate(r.Date),
+		sqliteTime(timestamp()),
+	)
+	if err != nil {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", err)
+	}
+
+	err = db.QueryRowContext(ctx, sqliteSelectRaceResult, r.UserId, r.Id).Scan(sqliteRaceResultDest(&r)...)
+	if err != nil {
+		return RaceResult{}, fmt.Errorf("PersistRaceResult: %w", err)
+	}
+	return sqliteLocalRaceResult(r), nil
+}
+
+// comparison is 0 or 1 in sqlite, which scans into bool
+var sqliteRaceResultsOfUser = `
+select r.id, r.user_id, r.training_id, r.distance, r.stroke, r.pool_length, r.pool_unit,
+    r.time_ms, r.date,
+    coalesce(r.time_ms < min(r.time_ms) over earlier, true) as is_personal_best,
+    r.created_at, r.modified_at
+from race_results r
+where r.user_id = $1
+window earlier as (
+    partition by r.distance, r.stroke, r.pool_length, r.pool_unit
+    order by r.date, r.created_at, r.id
+    rows between unbounded preceding and 1 preceding
+)
+`
+
+var sqliteSelectRaceResult = `
+select r.id, r.user_id, r.training_id, r.distance, r.stroke, r.pool_length, r.pool_unit,
+    r.time_ms, r.date, r.is_personal_best, r.created_at, r.modified_at
+from (` + sqliteRaceResultsOfUser + `) r
+where r.id = $2
+`
+
+// enums are ordered by their declaration in postgres, not alphabetically
+var sqliteSelectRaceResults = `
+select r.id, r.user_id, r.training_id, r.distance, r.stroke, r.pool_length, r.pool_unit,
+    r.time_ms, r.date, r.is_personal_best, r.created_at, r.modified_at
+from (` + sqliteRaceResultsOfUser + `) r
+order by
+    case r.stroke
+        when 'freestyle' then 0
+        when 'backstroke' then 1
+        when 'breaststroke' then 2
+        when 'butterfly' then 3
+        when 'medley' then 4
+        when 'surface' then 5
+        when 'bifins' then 6
+        when 'apnea' then 7
+    end,
+    case r.pool_unit
+        when 'meters' then 0
+        when 'yards' then 1
+    end,
+    r.pool_length, r.distance, r.date, r.created_at
+`
+
+func (db *SqliteDb) RaceResults(ctx context.Context, userId uuid.UUID) ([]RaceResult, error) {
+	results := make([]RaceResult, 0)
+
+	rows, err := db.QueryContext(ctx, sqliteSelectRaceResults, userId)
+	if err != nil {
+		return nil, fmt.Errorf("RaceResults query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r RaceResult
+		err := rows.Scan(sqliteRaceResultDest(&r)...)
+		if err != nil {
+			return nil, fmt.Errorf("RaceResults scanning row: %w", err)
+		}
+		results = append(results, sqliteLocalRaceResult(r))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RaceResults rows: %w", err)
+	}
+
+	return results, nil
+}
+
+func sqliteRaceResultDest(r *RaceResult) []any {
+	return []any{
+		&r.Id,
+		&r.UserId,
+		&r.TrainingId,
+		&r.Distance,
+		&r.Stroke,
+		&r.PoolLength,
+		&r.PoolUnit,
+		&r.TimeMs,
+		&r.Date,
+		&r.IsPersonalBest,
+		&r.CreatedAt,
+		&r.ModifiedAt,
+	}
+}
+
+func sqliteLocalRaceResult(r RaceResult) RaceResult {
+	r.CreatedAt = r.CreatedAt.Local()
+	r.ModifiedAt = r.ModifiedAt.Local()
+	return r
+}
@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var sqliteUpsertSetResult = `
+insert into set_results (set_id, user_id, times_ms, created_at, modified_at)
+values ($1, $2, $3, $4, $4)
+on conflict (set_id, user_id) do update
+set times_ms    = excluded.times_ms,
+    modified_at = excluded.modified_at
+returning set_id, user_id, times_ms, created_at, modified_at
+`
+
+func (db *SqliteDb) UpsertSetResult(ctx context.Context, r SetResult) (SetResult, error) {
+	timesMs, err := toSqliteJson(&r.TimesMs)
+	if err != nil {
+		return SetResult{}, fmt.Errorf("UpsertSetResult times: %w", err)
+	}
+
+	err = db.QueryRowContext(
+		ctx,
+		sqliteUpsertSetResult,
+		r.SetId,
+		r.UserId,
+		timesMs,
+		sqliteTime(timestamp()),
+	).Scan(&r.SetId, &r.UserId, &timesMs, &r.CreatedAt, &r.ModifiedAt)
+	if err != nil {
+		return SetResult{}, fmt.Errorf("UpsertSetResult: %w", err)
+	}
+	return sqliteSetResult(r, timesMs)
+}
+
+var sqliteSelectSetResults = `
+select r.set_id, r.user_id, r.times_ms, r.created_at, r.modified_at
+from set_results r
+where r.set_id = $1
+order by r.created_at
+`
+
+func (db *SqliteDb) SetResults(ctx context.Context, setId uuid.UUID) ([]SetResult, error) {
+	results := make([]SetResult, 0)
+
+	rows, err := db.QueryContext(ctx, sqliteSelectSetResults, setId)
+	if err != nil {
+		return nil, fmt.Errorf("SetResults query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r SetResult
+		var timesMs *string
+		err := rows.Scan(&r.SetId, &r.UserId, &timesMs, &r.CreatedAt, &r.ModifiedAt)
+		if err != nil {
+			return nil, fmt.Errorf("SetResults scanning row: %w", err)
+		}
+		r, err = sqliteSetResult(r, timesMs)
+		if err != nil {
+			return nil, fmt.Errorf("SetResults: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("SetResults rows: %w", err)
+	}
+
+	return results, nil
+}
+
+func sqliteSetResult(r SetResult, timesMs *string) (SetResult, error) {
+	times, err := fromSqliteJson[[]int](timesMs)
+	if err != nil {
+		return SetResult{}, fmt.Errorf("sqliteSetResult times: %w", err)
+	}
+	r.TimesMs = *times
+	r.CreatedAt = r.CreatedAt.Local()
+	r.ModifiedAt = r.ModifiedAt.Local()
+	return r, nil
+}
@@ -0,0 +1,419 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (db *SqliteDb) PersistTraining(ctx context.Context, t Training) (Training, error) {
+	return sqliteTxWithResult(ctx, db, func(tx *sql.Tx) (Training, error) {
+		t, err := db.persistTraining(ctx, tx, t)
+		if err != nil {
+			return t, fmt.Errorf("PersistTraining tx: %w", err)
+		}
+		return t, nil
+	})
+}
+
+func (db *SqliteDb) PersistNewTrainings(ctx context.Context, ts []Training) ([]Training, error) {
+	return sqliteTxWithResult(ctx, db, func(tx *sql.Tx) ([]Training, error) {
+		persisted := make([]Training, 0, len(ts))
+		for i, t := range ts {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, trainingExists, t.Id).Scan(&exists); err != nil {
+				return nil, fmt.Errorf("PersistNewTrainings training %d exists: %w", i, err)
+			} else if exists {
+				continue
+			}
+
+			t, err := db.persistTraining(ctx, tx, t)
+			if err != nil {
+				return nil, fmt.Errorf("PersistNewTrainings training %d: %w", i, err)
+			}
+			persisted = append(persisted, t)
+		}
+		return persisted, nil
+	})
+}
+
+func (db *SqliteDb) DeleteTraining(ctx context.Context, id uuid.UUID) error {
+	return sqliteTx(ctx, db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "delete from trainings where id = $1", id)
+		if err != nil {
+			return fmt.Errorf("DeleteTraining: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("DeleteTraining rows affected: %w", err)
+		} else if affected == 0 {
+			return fmt.Errorf("DeleteTraining training doesnt exist: %w", ErrRowsNotFound)
+		}
+		return nil
+	})
+}
+
+var sqliteSelectTrainingDetailsPage = `
+select t.id, t.start, t.duration_min, t.total_distance, t.pool_length, t.pool_unit,
+    t.created_at, t.modified_at, count(*) over ()
+from trainings t
+order by t.start desc, t.duration_min, t.total_distance, t.created_at
+limit $1 offset $2
+`
+
+func (db *SqliteDb) TrainingDetails(ctx context.Context, page, pageSize int) ([]Training, int, error) {
+	tds := make([]Training, 0)
+
+	rows, err := db.QueryContext(ctx, sqliteSelectTrainingDetailsPage, pageSize, page*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("TrainingDetails query error: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var t Training
+		err := rows.Scan(append(sqliteTrainingDest(&t), &count)...)
+		if err != nil {
+			return nil, 0, fmt.Errorf("TrainingDetails scanning row: %w", err)
+		}
+		tds = append(tds, sqliteLocalTraining(t))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("TrainingDetails rows: %w", err)
+	}
+
+	return tds, count, nil
+}
+
+var sqliteSelectTrainingDetailsInRange = `
+select t.id, t.start, t.duration_min, t.total_distance, t.pool_length, t.pool_unit,
+    t.created_at, t.modified_at
+from trainings t
+where julianday(t.start) >= julianday($1) and julianday(t.start) < julianday($2)
+order by t.start, t.duration_min, t.total_distance, t.created_at
+`
+
+func (db *SqliteDb) TrainingDetailsInRange(ctx context.Context, start, end time.Time) ([]Training, error) {
+	tds := make([]Training, 0)
+
+	rows, err := db.QueryContext(
+		ctx,
+		sqliteSelectTrainingDetailsInRange,
+		sqliteTime(start),
+		sqliteTime(end),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"TrainingDetailsInRange from %s to %s query error: %w",
+			start,
+			end,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Training
+		err := rows.Scan(sqliteTrainingDest(&t)...)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"TrainingDetailsInRange from %s to %s scanning error: %w",
+				start,
+				end,
+				err,
+			)
+		}
+		tds = append(tds, sqliteLocalTraining(t))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("TrainingDetailsInRange rows: %w", err)
+	}
+
+	return tds, nil
+}
+
+var sqliteSelectTraining = `
+select
+    t.id, t.start, t.duration_min, t.total_distance, t.pool_length, t.pool_unit,
+    t.created_at, t.modified_at,
+    s.id, s.training_id, s.set_order, s.repeat, s.distance_meters, s.description,
+    s.start_type, s.start_seconds, s.total_distance, s.equipment, s."group"
+from trainings t join sets s on t.id = s.training_id
+where t.id = $1
+order by s.set_order
+`
+
+func (db *SqliteDb) Training(ctx context.Context, id uuid.UUID) (Training, error) {
+	t := Training{}
+	rows, err := db.QueryContext(ctx, sqliteSelectTraining, id)
+	if err != nil {
+		return Training{}, fmt.Errorf("Training query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s TrainingSet
+		var equipment *string
+		err := rows.Scan(append(sqliteTrainingDest(&t), sqliteSetDest(&s, &equipment)...)...)
+		if err != nil {
+			return Training{}, fmt.Errorf("Training scanning error: %w", err)
+		}
+		s.Equipment, err = fromSqliteJson[[]string](equipment)
+		if err != nil {
+			return Training{}, fmt.Errorf("Training equipment: %w", err)
+		}
+		t.Sets = append(t.Sets, s)
+	}
+	if err := rows.Err(); err != nil {
+		return Training{}, fmt.Errorf("Training rows: %w", err)
+	}
+
+	if len(t.Sets) == 0 {
+		return Training{}, fmt.Errorf("Training id doesnt exist: %w", ErrRowsNotFound)
+	}
+
+	return sqliteLocalTraining(t), nil
+}
+
+func (db *SqliteDb) EditTraining(ctx context.Context, id uuid.UUID, t Training) (Training, error) {
+	return sqliteTxWithResult(ctx, db, func(tx *sql.Tx) (Training, error) {
+		t, err := db.editTraining(ctx, tx, id, t)
+		if err != nil {
+			return t, fmt.Errorf("EditTraining tx: %w", err)
+		}
+		return t, nil
+	})
+}
+
+var sqliteSelectSet = `
+select s.id, s.training_id, s.set_order, s.repeat, s.distance_meters, s.description,
+    s.start_type, s.start_seconds, s.total_distance, s.equipment, s."group"
+from sets s
+where s.id = $1
+`
+
+func (db *SqliteDb) Set(ctx context.Context, id uuid.UUID) (TrainingSet, error) {
+	var s TrainingSet
+	var equipment *string
+	err := db.QueryRowContext(ctx, sqliteSelectSet, id).Scan(sqliteSetDest(&s, &equipment)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return TrainingSet{}, fmt.Errorf("Set id doesnt exist: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return TrainingSet{}, fmt.Errorf("Set query error: %w", err)
+	}
+
+	s.Equipment, err = fromSqliteJson[[]string](equipment)
+	if err != nil {
+		return TrainingSet{}, fmt.Errorf("Set equipment: %w", err)
+	}
+	return s, nil
+}
+
+var sqliteInsertTraining = `
+insert into trainings (id, start, duration_min, total_distance, pool_length, pool_unit,
+    created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, $7, $7)
+returning id, start, duration_min, total_distance, pool_length, pool_unit, created_at, modified_at
+`
+
+func (db *SqliteDb) persistTraining(ctx context.Context, tx *sql.Tx, t Training) (Training, error) {
+	err := tx.QueryRowContext(
+		ctx,
+		sqliteInsertTraining,
+		t.Id,
+		sqliteTime(t.Start),
+		t.DurationMin,
+		t.TotalDistance,
+		t.PoolLength,
+		t.PoolUnit,
+		sqliteTime(timestamp()),
+	).Scan(sqliteTrainingDest(&t)...)
+	if err != nil {
+		return Training{}, fmt.Errorf("persistTraining persisting training: %w", err)
+	}
+
+	for i, s := range t.Sets {
+		ts, err := db.persistSet(ctx, tx, s)
+		if err != nil {
+			return Training{}, fmt.Errorf("persistTraining set %d: %w", i, err)
+		}
+		t.Sets[i] = ts
+	}
+
+	return sqliteLocalTraining(t), nil
+}
+
+var sqliteInsertSet = `
+insert into sets (id, training_id, set_order, repeat, distance_meters,
+    description, start_type, start_seconds, total_distance, equipment, "group")
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+`
+
+func (db *SqliteDb) persistSet(ctx context.Context, tx *sql.Tx, s TrainingSet) (TrainingSet, error) {
+	equipment, err := toSqliteJson(s.Equipment)
+	if err != nil {
+		return TrainingSet{}, fmt.Errorf("persistSet equipment: %w", err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		sqliteInsertSet,
+		s.Id,
+		s.TrainingId,
+		s.SetOrder,
+		s.Repeat,
+		s.DistanceMeters,
+		s.Description,
+		s.StartType,
+		s.StartSeconds,
+		s.TotalDistance,
+		equipment,
+		s.Group,
+	)
+	if err != nil {
+		return TrainingSet{}, fmt.Errorf("persistSet: %w", err)
+	}
+
+	return s, nil
+}
+
+var sqliteUpdateTraining = `
+update trainings
+set start          = $2,
+    duration_min   = $3,
+    total_distance = $4,
+    pool_length    = $5,
+    pool_unit      = $6,
+    modified_at    = $7
+where id = $1
+returning id, start, duration_min, total_distance, pool_length, pool_unit, created_at, modified_at
+`
+
+func (db *SqliteDb) editTraining(
+	ctx context.Context,
+	tx *sql.Tx,
+	id uuid.UUID,
+	t Training,
+) (Training, error) {
+	err := tx.QueryRowContext(
+		ctx,
+		sqliteUpdateTraining,
+		id,
+		sqliteTime(t.Start),
+		t.DurationMin,
+		t.TotalDistance,
+		t.PoolLength,
+		t.PoolUnit,
+		sqliteTime(timestamp()),
+	).Scan(sqliteTrainingDest(&t)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Training{}, fmt.Errorf("editTraining not found: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return Training{}, fmt.Errorf("editTraining update training query error: %w", err)
+	}
+
+	for i, s := range t.Sets {
+		ts, err := db.editSet(ctx, tx, s)
+		if err != nil {
+			return Training{}, fmt.Errorf("editTraining set %d: %w", i, err)
+		}
+		t.Sets[i] = ts
+	}
+
+	return sqliteLocalTraining(t), nil
+}
+
+var sqliteUpdateSet = `
+update sets
+set set_order       = $2,
+    repeat          = $3,
+    distance_meters = $4,
+    description     = $5,
+    start_type      = $6,
+    start_seconds   = $7,
+    total_distance  = $8,
+    equipment       = $9,
+    "group"         = $10
+where id = $1
+returning training_id
+`
+
+func (db *SqliteDb) editSet(ctx context.Context, tx *sql.Tx, s TrainingSet) (TrainingSet, error) {
+	equipment, err := toSqliteJson(s.Equipment)
+	if err != nil {
+		return TrainingSet{}, fmt.Errorf("editSet equipment: %w", err)
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		sqliteUpdateSet,
+		s.Id,
+		s.SetOrder,
+		s.Repeat,
+		s.DistanceMeters,
+		s.Description,
+		s.StartType,
+		s.StartSeconds,
+		s.TotalDistance,
+		equipment,
+		s.Group,
+	).Scan(&s.TrainingId)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.Id = uuid.New()
+		return db.persistSet(ctx, tx, s)
+	} else if err != nil {
+		return TrainingSet{}, fmt.Errorf("editSet query error: %w, id: %s", err, s.Id)
+	}
+
+	_, err = tx.ExecContext(ctx, sqliteDeleteStaleSetResults, s.Id, s.Repeat)
+	if err != nil {
+		return TrainingSet{}, fmt.Errorf("editSet delete stale results: %w, id: %s", err, s.Id)
+	}
+
+	return s, nil
+}
+
+var sqliteDeleteStaleSetResults = "delete from set_results where set_id = $1 and json_array_length(times_ms) <> $2"
+
+func sqliteTrainingDest(t *Training) []any {
+	return []any{
+		&t.Id,
+		&t.Start,
+		&t.DurationMin,
+		&t.TotalDistance,
+		&t.PoolLength,
+		&t.PoolUnit,
+		&t.CreatedAt,
+		&t.ModifiedAt,
+	}
+}
+
+func sqliteSetDest(s *TrainingSet, equipment **string) []any {
+	return []any{
+		&s.Id,
+		&s.TrainingId,
+		&s.SetOrder,
+		&s.Repeat,
+		&s.DistanceMeters,
+		&s.Description,
+		&s.StartType,
+		&s.StartSeconds,
+		&s.TotalDistance,
+		equipment,
+		&s.Group,
+	}
+}
+
+// sqliteLocalTraining converts timestamps stored in UTC to local time, the
+// same as postgres returns them.
+func sqliteLocalTraining(t Training) Training {
+	t.Start = t.Start.Local()
+	t.CreatedAt = t.CreatedAt.Local()
+	t.ModifiedAt = t.ModifiedAt.Local()
+	return t
+}
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var sqliteInsertUser = `
+insert into users (id, name, created_at, modified_at)
+values ($1, $2, $3, $3)
+returning id, name, created_at, modified_at
+`
+
+func (db *SqliteDb) PersistUser(ctx context.Context, u User) (User, error) {
+	err := db.QueryRowContext(ctx, sqliteInsertUser, u.Id, u.Name, sqliteTime(timestamp())).
+		Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+	if err != nil {
+		return User{}, fmt.Errorf("PersistUser: %w", err)
+	}
+	return sqliteLocalUser(u), nil
+}
+
+var sqliteSelectUser = `
+select u.id, u.name, u.created_at, u.modified_at
+from users u
+where u.id = $1
+`
+
+func (db *SqliteDb) User(ctx context.Context, id uuid.UUID) (User, error) {
+	var u User
+	err := db.QueryRowContext(ctx, sqliteSelectUser, id).
+		Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, fmt.Errorf("User id doesnt exist: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return User{}, fmt.Errorf("User query error: %w", err)
+	}
+	return sqliteLocalUser(u), nil
+}
+
+var sqliteSelectUsers = `
+select u.id, u.name, u.created_at, u.modified_at
+from users u
+order by u.name, u.created_at
+`
+
+func (db *SqliteDb) Users(ctx context.Context) ([]User, error) {
+	users := make([]User, 0)
+
+	rows, err := db.QueryContext(ctx, sqliteSelectUsers)
+	if err != nil {
+		return nil, fmt.Errorf("Users query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u User
+		err := rows.Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+		if err != nil {
+			return nil, fmt.Errorf("Users scanning row: %w", err)
+		}
+		users = append(users, sqliteLocalUser(u))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Users rows: %w", err)
+	}
+
+	return users, nil
+}
+
+func sqliteLocalUser(u User) User {
+	u.CreatedAt = u.CreatedAt.Local()
+	u.ModifiedAt = u.ModifiedAt.Local()
+	return u
+}
@@ -0,0 +1,15 @@
+package data
+
+import "time"
+
+// date truncates t to its calendar date, as postgres does when storing
+// time into a date column.
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// timestamp returns current time with the precision of postgres timestamps.
+func timestamp() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
@@ -15,6 +15,8 @@ type Training struct {
 	Start         time.Time
 	DurationMin   int
 	TotalDistance int
+	PoolLength    int
+	PoolUnit      string
 	Sets          []TrainingSet
 
 	CreatedAt  time.Time
@@ -35,9 +37,9 @@ type TrainingSet struct {
 	Group          *string
 }
 
-func (pool *PostgresDbPool) PersistTraining(t Training) (Training, error) {
-	return TxWithResult(pool, func(tx pgx.Tx) (Training, error) {
-		t, err := pool.persistTraining(t, tx)
+func (pool *PostgresDbPool) PersistTraining(ctx context.Context, t Training) (Training, error) {
+	return TxWithResult(ctx, pool, func(tx pgx.Tx) (Training, error) {
+		t, err := pool.persistTraining(ctx, t, tx)
 		if err != nil {
 			return t, fmt.Errorf("PersistTraining tx: %w", err)
 		}
@@ -45,9 +47,32 @@ func (pool *PostgresDbPool) PersistTraining(t Training) (Training, error) {
 	})
 }
 
-func (pool *PostgresDbPool) DeleteTraining(id uuid.UUID) error {
-	return Tx(pool, func(tx pgx.Tx) error {
-		ct, err := tx.Exec(context.Background(), "delete from trainings where id = $1", id)
+var trainingExists = "select exists(select 1 from trainings where id = $1)"
+
+func (pool *PostgresDbPool) PersistNewTrainings(ctx context.Context, ts []Training) ([]Training, error) {
+	return TxWithResult(ctx, pool, func(tx pgx.Tx) ([]Training, error) {
+		persisted := make([]Training, 0, len(ts))
+		for i, t := range ts {
+			var exists bool
+			if err := tx.QueryRow(ctx, trainingExists, t.Id).Scan(&exists); err != nil {
+				return nil, fmt.Errorf("PersistNewTrainings training %d exists: %w", i, err)
+			} else if exists {
+				continue
+			}
+
+			t, err := pool.persistTraining(ctx, t, tx)
+			if err != nil {
+				return nil, fmt.Errorf("PersistNewTrainings training %d: %w", i, err)
+			}
+			persisted = append(persisted, t)
+		}
+		return persisted, nil
+	})
+}
+
+func (pool *PostgresDbPool) DeleteTraining(ctx context.Context, id uuid.UUID) error {
+	return Tx(ctx, pool, func(tx pgx.Tx) error {
+		ct, err := tx.Exec(ctx, "delete from trainings where id = $1", id)
 		if err != nil {
 			return fmt.Errorf("DeleteTraining: %w", err)
 		} else if ct.RowsAffected() == 0 {
@@ -58,17 +83,18 @@ func (pool *PostgresDbPool) DeleteTraining(id uuid.UUID) error {
 }
 
 var selectTrainingDetailsPage = `
-select t.id, t.start, t.duration_min, t.total_distance, t.created_at, t.modified_at, count(*) over ()
+select t.id, t.start, t.duration_min, t.total_distance, t.pool_length, t.pool_unit,
+    t.created_at, t.modified_at, count(*) over ()
 from trainings t
 order by t.start desc, t.duration_min, t.total_distance, t.created_at
 limit $1 offset $2
 `
 
-func (pool *PostgresDbPool) TrainingDetails(page, pageSize int) ([]Training, int, error) {
+func (pool *PostgresDbPool) TrainingDetails(ctx context.Context, page, pageSize int) ([]Training, int, error) {
 	tds := make([]Training, 0)
 
 	rows, err := pool.Query(
-		context.Background(),
+		ctx,
 		selectTrainingDetailsPage,
 		pageSize,
 		page*pageSize,
@@ -86,6 +112,8 @@ func (pool *PostgresDbPool) TrainingDetails(page, pageSize int) ([]Training, int
 			&t.Start,
 			&t.DurationMin,
 			&t.TotalDistance,
+			&t.PoolLength,
+			&t.PoolUnit,
 			&t.CreatedAt,
 			&t.ModifiedAt,
 			&count,
@@ -99,17 +127,18 @@ func (pool *PostgresDbPool) TrainingDetails(page, pageSize int) ([]Training, int
 	return tds, count, nil
 }
 
-var selectTrainingDetailsInDateRange = `
-select t.id, t.start, t.duration_min, t.total_distance, t.created_at, t.modified_at
+var selectTrainingDetailsInRange = `
+select t.id, t.start, t.duration_min, t.total_distance, t.pool_length, t.pool_unit,
+    t.created_at, t.modified_at
 from trainings t
-where date(t.start) between $1::date and $2::date
+where t.start >= $1 and t.start < $2
 order by t.start, t.duration_min, t.total_distance, t.created_at
 `
 
-func (pool *PostgresDbPool) TrainingDetailsInRange(start, end time.Time) ([]Training, error) {
+func (pool *PostgresDbPool) TrainingDetailsInRange(ctx context.Context, start, end time.Time) ([]Training, error) {
 	tds := make([]Training, 0)
 
-	rows, err := pool.Query(context.Background(), selectTrainingDetailsInDateRange, start, end)
+	rows, err := pool.Query(ctx, selectTrainingDetailsInRange, start, end)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"TrainingDetailsInRange from %s to %s query error: %w",
@@ -127,6 +156,8 @@ func (pool *PostgresDbPool) TrainingDetailsInRange(start, end time.Time) ([]Trai
 			&t.Start,
 			&t.DurationMin,
 			&t.TotalDistance,
+			&t.PoolLength,
+			&t.PoolUnit,
 			&t.CreatedAt,
 			&t.ModifiedAt,
 		)
@@ -146,7 +177,8 @@ func (pool *PostgresDbPool) TrainingDetailsInRange(start, end time.Time) ([]Trai
 
 var selectTraining = `
 select
-    t.id, t.start, t.duration_min, t.total_distance, t.created_at, t.modified_at,
+    t.id, t.start, t.duration_min, t.total_distance, t.pool_length, t.pool_unit,
+    t.created_at, t.modified_at,
     s.id, s.training_id, s.set_order, s.repeat, s.distance_meters, s.description,
     s.start_type, s.start_seconds, s.total_distance, s.equipment, s.group
 from trainings t join sets s on t.id = s.training_id
@@ -154,9 +186,9 @@ where t.id = $1
 order by s.set_order
 `
 
-func (pool *PostgresDbPool) Training(id uuid.UUID) (Training, error) {
+func (pool *PostgresDbPool) Training(ctx context.Context, id uuid.UUID) (Training, error) {
 	t := Training{}
-	rows, err := pool.Query(context.Background(), selectTraining, id)
+	rows, err := pool.Query(ctx, selectTraining, id)
 	if err != nil {
 		return Training{}, fmt.Errorf("Training query error: %w", err)
 	}
@@ -168,6 +200,8 @@ func (pool *PostgresDbPool) Training(id uuid.UUID) (Training, error) {
 			&t.Start,
 			&t.DurationMin,
 			&t.TotalDistance,
+			&t.PoolLength,
+			&t.PoolUnit,
 			&t.CreatedAt,
 			&t.ModifiedAt,
 			&s.Id,
@@ -196,9 +230,9 @@ func (pool *PostgresDbPool) Training(id uuid.UUID) (Training, error) {
 	return t, nil
 }
 
-func (pool *PostgresDbPool) EditTraining(id uuid.UUID, t Training) (Training, error) {
-	return TxWithResult(pool, func(tx pgx.Tx) (Training, error) {
-		t, err := pool.editTraining(id, t, tx)
+func (pool *PostgresDbPool) EditTraining(ctx context.Context, id uuid.UUID, t Training) (Training, error) {
+	return TxWithResult(ctx, pool, func(tx pgx.Tx) (Training, error) {
+		t, err := pool.editTraining(ctx, id, t, tx)
 		if err != nil {
 			return t, fmt.Errorf("EditTraining tx: %w", err)
 		}
@@ -207,20 +241,38 @@ func (pool *PostgresDbPool) EditTraining(id uuid.UUID, t Training) (Training, er
 }
 
 var insertTraining = `
-insert into trainings (id, start, duration_min, total_distance, created_at, modified_at)
-values ($1, $2, $3, $4, now(), now())
-returning id, start, duration_min, total_distance, created_at, modified_at
+insert into trainings (id, start, duration_min, total_distance, pool_length, pool_unit,
+    created_at, modified_at)
+values ($1, $2, $3, $4, $5, $6, now(), now())
+returning id, start, duration_min, total_distance, pool_length, pool_unit, created_at, modified_at
 `
 
-func (pool *PostgresDbPool) persistTraining(t Training, tx pgx.Tx) (Training, error) {
-	err := tx.QueryRow(context.Background(), insertTraining, t.Id, t.Start, t.DurationMin, t.TotalDistance).
-		Scan(&t.Id, &t.Start, &t.DurationMin, &t.TotalDistance, &t.CreatedAt, &t.ModifiedAt)
+func (pool *PostgresDbPool) persistTraining(ctx context.Context, t Training, tx pgx.Tx) (Training, error) {
+	err := tx.QueryRow(
+		ctx,
+		insertTraining,
+		t.Id,
+		t.Start,
+		t.DurationMin,
+		t.TotalDistance,
+		t.PoolLength,
+		t.PoolUnit,
+	).Scan(
+		&t.Id,
+		&t.Start,
+		&t.DurationMin,
+		&t.TotalDistance,
+		&t.PoolLength,
+		&t.PoolUnit,
+		&t.CreatedAt,
+		&t.ModifiedAt,
+	)
 	if err != nil {
 		return Training{}, fmt.Errorf("persistTraining persisting training: %w", err)
 	}
 
 	for i, s := range t.Sets {
-		ts, err := pool.persistSet(tx, s)
+		ts, err := pool.persistSet(ctx, tx, s)
 		if err != nil {
 			return Training{}, fmt.Errorf("persistTraining set %d: %w", i, err)
 		}
@@ -238,9 +290,9 @@ returning id, training_id, set_order, repeat, distance_meters,
     description, start_type, start_seconds, total_distance, equipment, "group"
 `
 
-func (pool *PostgresDbPool) persistSet(tx pgx.Tx, s TrainingSet) (TrainingSet, error) {
+func (pool *PostgresDbPool) persistSet(ctx context.Context, tx pgx.Tx, s TrainingSet) (TrainingSet, error) {
 	err := tx.QueryRow(
-		context.Background(),
+		ctx,
 		insertSet,
 		s.Id,
 		s.TrainingId,
@@ -278,14 +330,33 @@ update trainings
 set start          = $2,
     duration_min   = $3,
     total_distance = $4,
+    pool_length    = $5,
+    pool_unit      = $6,
     modified_at    = now()
 where id = $1
-returning id, start, duration_min, total_distance, created_at, modified_at
+returning id, start, duration_min, total_distance, pool_length, pool_unit, created_at, modified_at
 `
 
-func (pool *PostgresDbPool) editTraining(id uuid.UUID, t Training, tx pgx.Tx) (Training, error) {
-	err := tx.QueryRow(context.Background(), updateTraining, id, t.Start, t.DurationMin, t.TotalDistance).
-		Scan(&t.Id, &t.Start, &t.DurationMin, &t.TotalDistance, &t.CreatedAt, &t.ModifiedAt)
+func (pool *PostgresDbPool) editTraining(ctx context.Context, id uuid.UUID, t Training, tx pgx.Tx) (Training, error) {
+	err := tx.QueryRow(
+		ctx,
+		updateTraining,
+		id,
+		t.Start,
+		t.DurationMin,
+		t.TotalDistance,
+		t.PoolLength,
+		t.PoolUnit,
+	).Scan(
+		&t.Id,
+		&t.Start,
+		&t.DurationMin,
+		&t.TotalDistance,
+		&t.PoolLength,
+		&t.PoolUnit,
+		&t.CreatedAt,
+		&t.ModifiedAt,
+	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return Training{}, fmt.Errorf("editTraining not found: %w", ErrRowsNotFound)
@@ -294,7 +365,7 @@ func (pool *PostgresDbPool) editTraining(id uuid.UUID, t Training, tx pgx.Tx) (T
 	}
 
 	for i, s := range t.Sets {
-		ts, err := pool.editSet(tx, s)
+		ts, err := pool.editSet(ctx, tx, s)
 		if err != nil {
 			return Training{}, fmt.Errorf("editTraining set %d: %w", i, err)
 		}
@@ -320,19 +391,19 @@ returning id, training_id, set_order, repeat, distance_meters, description,
     start_type, start_seconds, total_distance, equipment, "group"
 `
 
-func (pool *PostgresDbPool) editSet(tx pgx.Tx, s TrainingSet) (TrainingSet, error) {
-	setExists, err := pool.setExists(tx, s)
+func (pool *PostgresDbPool) editSet(ctx context.Context, tx pgx.Tx, s TrainingSet) (TrainingSet, error) {
+	setExists, err := pool.setExists(ctx, tx, s)
 	if err != nil {
 		return TrainingSet{}, fmt.Errorf("editSet exists query: %w", err)
 	}
 
 	if !setExists {
 		s.Id = uuid.New()
-		return pool.persistSet(tx, s)
+		return pool.persistSet(ctx, tx, s)
 	}
 
 	err = tx.QueryRow(
-		context.Background(),
+		ctx,
 		updateSet,
 		s.Id,
 		s.SetOrder,
@@ -361,14 +432,23 @@ func (pool *PostgresDbPool) editSet(tx pgx.Tx, s TrainingSet) (TrainingSet, erro
 		return TrainingSet{}, fmt.Errorf("editSet query error: %w, id: %s", err, s.Id)
 	}
 
+	_, err = tx.Exec(ctx, deleteStaleSetResults, s.Id, s.Repeat)
+	if err != nil {
+		return TrainingSet{}, fmt.Errorf("editSet delete stale results: %w, id: %s", err, s.Id)
+	}
+
 	return s, nil
 }
 
+// deleteStaleSetResults deletes results of a set whose times no longer
+// match its repeat.
+var deleteStaleSetResults = "delete from set_results where set_id = $1 and cardinality(times_ms) <> $2"
+
 var setExists = "select exists(select 1 from sets where id = $1)"
 
-func (pool *PostgresDbPool) setExists(tx pgx.Tx, s TrainingSet) (bool, error) {
+func (pool *PostgresDbPool) setExists(ctx context.Context, tx pgx.Tx, s TrainingSet) (bool, error) {
 	var exists bool
-	err := tx.QueryRow(context.Background(), setExists, s.Id).Scan(&exists)
+	err := tx.QueryRow(ctx, setExists, s.Id).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("isSetNew query error: %w, id: %s", err, s.Id)
 	}
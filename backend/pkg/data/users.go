@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type User struct {
+	Id   uuid.UUID
+	Name string
+
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+var insertUser = `
+insert into users (id, name, created_at, modified_at)
+values ($1, $2, now(), now())
+returning id, name, created_at, modified_at
+`
+
+func (pool *PostgresDbPool) PersistUser(ctx context.Context, u User) (User, error) {
+	err := pool.QueryRow(ctx, insertUser, u.Id, u.Name).
+		Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+	if err != nil {
+		return User{}, fmt.Errorf("PersistUser: %w", err)
+	}
+	return u, nil
+}
+
+var selectUser = `
+select u.id, u.name, u.created_at, u.modified_at
+from users u
+where u.id = $1
+`
+
+func (pool *PostgresDbPool) User(ctx context.Context, id uuid.UUID) (User, error) {
+	var u User
+	err := pool.QueryRow(ctx, selectUser, id).
+		Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return User{}, fmt.Errorf("User id doesnt exist: %w", ErrRowsNotFound)
+	} else if err != nil {
+		return User{}, fmt.Errorf("User query error: %w", err)
+	}
+	return u, nil
+}
+
+var selectUsers = `
+select u.id, u.name, u.created_at, u.modified_at
+from users u
+order by u.name, u.created_at
+`
+
+func (pool *PostgresDbPool) Users(ctx context.Context) ([]User, error) {
+	users := make([]User, 0)
+
+	rows, err := pool.Query(ctx, selectUsers)
+	if err != nil {
+		return nil, fmt.Errorf("Users query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u User
+		err := rows.Scan(&u.Id, &u.Name, &u.CreatedAt, &u.ModifiedAt)
+		if err != nil {
+			return nil, fmt.Errorf("Users scanning row: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, nil
+}
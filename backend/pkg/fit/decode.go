@@ -0,0 +1,193 @@
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidFile = errors.New("invalid FIT file")
+
+const (
+	compressedTimestampHeader = 0x80
+	developerDataFlag         = 0x20
+)
+
+// Message is a decoded data message, fields are by their number. Developer
+// fields are skipped.
+type Message struct {
+	Num    uint16
+	fields map[byte]rawField
+}
+
+type rawField struct {
+	typ   baseType
+	order binary.ByteOrder
+	value []byte
+}
+
+// IsFIT reports whether b starts with a FIT file header.
+func IsFIT(b []byte) bool {
+	return len(b) >= 12 && (b[0] == 12 || b[0] == headerSize) && string(b[8:12]) == ".FIT"
+}
+
+// Decode decodes data messages of the first FIT file in b, checking its
+// size and CRC.
+func Decode(b []byte) ([]Message, error) {
+	if !IsFIT(b) || len(b) < int(b[0]) {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidFile)
+	}
+	size := int(b[0])
+	dataSize := int(binary.LittleEndian.Uint32(b[4:8]))
+	if len(b) < size+dataSize+2 {
+		return nil, fmt.Errorf("%w: truncated, %d of %d bytes", ErrInvalidFile, len(b), size+dataSize+2)
+	}
+	if CRC(b[:size+dataSize+2]) != 0 {
+		return nil, fmt.Errorf("%w: CRC mismatch", ErrInvalidFile)
+	}
+
+	type fieldDef struct {
+		num  byte
+		size int
+		typ  baseType
+	}
+	type definition struct {
+		num       uint16
+		order     binary.ByteOrder
+		fields    []fieldDef
+		devFields int
+	}
+
+	definitions := map[byte]definition{}
+	var messages []Message
+	r := bytes.NewReader(b[size : size+dataSize])
+	for r.Len() > 0 {
+		header, _ := r.ReadByte()
+		local := header & 0x0F
+		if header&compressedTimestampHeader != 0 {
+			local = (header >> 5) & 0x03
+		} else if header&definitionHeader != 0 {
+			fixed, err := readN(r, 5)
+			if err != nil {
+				return nil, fmt.Errorf("%w: truncated definition", ErrInvalidFile)
+			}
+			d := definition{order: binary.ByteOrder(binary.LittleEndian)}
+			if fixed[1] == 1 {
+				d.order = binary.BigEndian
+			}
+			d.num = d.order.Uint16(fixed[2:4])
+
+			defs, err := readN(r, 3*int(fixed[4]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: truncated definition", ErrInvalidFile)
+			}
+			for i := 0; i < len(defs); i += 3 {
+				d.fields = append(d.fields, fieldDef{defs[i], int(defs[i+1]), baseType(defs[i+2])})
+			}
+
+			if header&developerDataFlag != 0 {
+				n, err := r.ReadByte()
+				if err != nil {
+					return nil, fmt.Errorf("%w: truncated definition", ErrInvalidFile)
+				}
+				devDefs, err := readN(r, 3*int(n))
+				if err != nil {
+					return nil, fmt.Errorf("%w: truncated definition", ErrInvalidFile)
+				}
+				for i := 0; i < len(devDefs); i += 3 {
+					d.devFields += int(devDefs[i+1])
+				}
+			}
+			definitions[local] = d
+			continue
+		}
+
+		d, ok := definitions[local]
+		if !ok {
+			return nil, fmt.Errorf("%w: message of undefined local type %d", ErrInvalidFile, local)
+		}
+		m := Message{Num: d.num, fields: make(map[byte]rawField, len(d.fields))}
+		for _, f := range d.fields {
+			value, err := readN(r, f.size)
+			if err != nil {
+				return nil, fmt.Errorf("%w: truncated message %d", ErrInvalidFile, d.num)
+			}
+			m.fields[f.num] = rawField{f.typ, d.order, value}
+		}
+		if _, err := readN(r, d.devFields); err != nil {
+			return nil, fmt.Errorf("%w: truncated message %d", ErrInvalidFile, d.num)
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+func readN(r *bytes.Reader, n int) ([]byte, error) {
+	if r.Len() < n {
+		return nil, errors.New("unexpected end of data")
+	} else if n == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, n)
+	_, err := r.Read(buf)
+	return buf, err
+}
+
+// Uint returns value of an unsigned integer or enum field, the first one
+// of an array. False if the message doesn't have the field or it is invalid.
+func (m Message) Uint(num byte) (uint64, bool) {
+	f, ok := m.fields[num]
+	if !ok {
+		return 0, false
+	}
+
+	var v, invalid uint64
+	switch f.typ {
+	case typeEnum, typeUint8, typeByte:
+		if len(f.value) < 1 {
+			return 0, false
+		}
+		v, invalid = uint64(f.value[0]), 0xFF
+	case typeUint8z:
+		if len(f.value) < 1 {
+			return 0, false
+		}
+		v, invalid = uint64(f.value[0]), 0
+	case typeUint16, typeUint16z:
+		if len(f.value) < 2 {
+			return 0, false
+		}
+		v, invalid = uint64(f.order.Uint16(f.value)), 0xFFFF
+	case typeUint32, typeUint32z:
+		if len(f.value) < 4 {
+			return 0, false
+		}
+		v, invalid = uint64(f.order.Uint32(f.value)), 0xFFFFFFFF
+	default:
+		return 0, false
+	}
+	if f.typ == typeUint16z || f.typ == typeUint32z {
+		invalid = 0
+	}
+	return v, v != invalid
+}
+
+// Time returns value of a date_time field.
+func (m Message) Time(num byte) (time.Time, bool) {
+	v, ok := m.Uint(num)
+	if !ok {
+		return time.Time{}, false
+	}
+	return epoch.Add(time.Duration(v) * time.Second), true
+}
+
+// Duration returns value of a field of seconds scaled by 1000.
+func (m Message) Duration(num byte) (time.Duration, bool) {
+	v, ok := m.Uint(num)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(v) * time.Millisecond, true
+}
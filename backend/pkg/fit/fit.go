@@ -0,0 +1,135 @@
+// Package fit encodes Garmin FIT files, only as much of the protocol as
+// structured workouts need. See the FIT protocol description in the FIT SDK
+// at https://developer.garmin.com/fit/protocol/.
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "application/vnd.ant.fit"
+
+	protocolVersion = 0x10
+	profileVersion  = 2140
+	headerSize      = 14
+
+	definitionHeader = 0x40
+)
+
+// epoch of FIT date_time values, 1989-12-31T00:00:00Z
+var epoch = time.Date(1989, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+type baseType byte
+
+const (
+	typeEnum    baseType = 0x00
+	typeUint8   baseType = 0x02
+	typeString  baseType = 0x07
+	typeUint8z  baseType = 0x0A
+	typeByte    baseType = 0x0D
+	typeUint16  baseType = 0x84
+	typeUint32  baseType = 0x86
+	typeUint16z baseType = 0x8B
+	typeUint32z baseType = 0x8C
+)
+
+type field struct {
+	num   byte
+	typ   baseType
+	value []byte
+}
+
+func enumField(num byte, v byte) field {
+	return field{num, typeEnum, []byte{v}}
+}
+
+func uint16Field(num byte, v uint16) field {
+	return field{num, typeUint16, binary.LittleEndian.AppendUint16(nil, v)}
+}
+
+func uint32Field(num byte, v uint32) field {
+	return field{num, typeUint32, binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+func uint32zField(num byte, v uint32) field {
+	return field{num, typeUint32z, binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+func dateTimeField(num byte, t time.Time) field {
+	return uint32Field(num, uint32(max(t.Sub(epoch)/time.Second, 0)))
+}
+
+// stringField is s null terminated, truncated to at most maxBytes bytes of
+// whole runes.
+func stringField(num byte, s string, maxBytes int) field {
+	for len(s) > maxBytes {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return field{num, typeString, append([]byte(s), 0)}
+}
+
+// encoder writes data records of a FIT file. All messages use local message
+// type 0, which is redefined whenever the fields of a message differ from
+// the previous one.
+type encoder struct {
+	data       bytes.Buffer
+	definition []byte
+}
+
+func (e *encoder) write(globalNum uint16, fields ...field) {
+	definition := []byte{0, 0} // reserved, little endian architecture
+	definition = binary.LittleEndian.AppendUint16(definition, globalNum)
+	definition = append(definition, byte(len(fields)))
+	for _, f := range fields {
+		definition = append(definition, f.num, byte(len(f.value)), byte(f.typ))
+	}
+	if !bytes.Equal(definition, e.definition) {
+		e.data.WriteByte(definitionHeader)
+		e.data.Write(definition)
+		e.definition = definition
+	}
+
+	e.data.WriteByte(0)
+	for _, f := range fields {
+		e.data.Write(f.value)
+	}
+}
+
+// bytes returns the whole file, header, data records and CRC.
+func (e *encoder) bytes() []byte {
+	file := make([]byte, 0, headerSize+e.data.Len()+2)
+	file = append(file, headerSize, protocolVersion)
+	file = binary.LittleEndian.AppendUint16(file, profileVersion)
+	file = binary.LittleEndian.AppendUint32(file, uint32(e.data.Len()))
+	file = append(file, ".FIT"...)
+	file = binary.LittleEndian.AppendUint16(file, CRC(file))
+
+	file = append(file, e.data.Bytes()...)
+	return binary.LittleEndian.AppendUint16(file, CRC(file))
+}
+
+var crcTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+// CRC is the FIT checksum of b. A whole file, including its trailing CRC,
+// checks to 0.
+func CRC(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		tmp := crcTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ crcTable[c&0xF]
+
+		tmp = crcTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ crcTable[(c>>4)&0xF]
+	}
+	return crc
+}
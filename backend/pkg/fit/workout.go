@@ -0,0 +1,173 @@
+package fit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var ErrInvalidTraining = errors.New("training can't be a workout")
+
+// Global message numbers
+const (
+	MesgFileId      uint16 = 0
+	MesgWorkout     uint16 = 26
+	MesgWorkoutStep uint16 = 27
+)
+
+// Values of wkt_step_duration
+const (
+	DurationTime             byte = 0
+	DurationDistance         byte = 1
+	DurationRepeatUntilSteps byte = 6
+	DurationRepetitionTime   byte = 28
+)
+
+// Values of intensity
+const (
+	IntensityActive byte = 0
+	IntensityRest   byte = 1
+)
+
+const (
+	fileWorkout         = 5
+	manufacturerDev     = 255
+	sportSwimming       = 5
+	subSportLapSwimming = 17
+	targetOpen          = 2
+	displayMetric       = 0
+	displayStatute      = 1
+	metersPerYard       = 0.9144
+	maxNameBytes        = 50
+	maxNotesBytes       = 100
+)
+
+// workout_equipment of equipment, FIT has no monofin so those are fins
+var equipment = map[apidef.EquipmentEnum]byte{
+	apidef.Fins:    1,
+	apidef.Monofin: 1,
+	apidef.Board:   2,
+	apidef.Paddles: 3,
+	apidef.Snorkel: 5,
+}
+
+// Workout encodes t as a FIT pool swimming workout named name. Each set is
+// a distance step, followed by a rest step when it has a start. Pause is a
+// rest of fixed time, Interval rests until the repetition time is up. Sets
+// with more repeats are wrapped in a repeat step. The file is identified by
+// id and start of t, so exporting one training again gives the same file.
+func Workout(t data.Training, name string) ([]byte, error) {
+	poolLength := float64(t.PoolLength)
+	unit := byte(displayMetric)
+	switch apidef.PoolUnitEnum(t.PoolUnit) {
+	case apidef.Meters:
+	case apidef.Yards:
+		poolLength *= metersPerYard
+		unit = displayStatute
+	default:
+		return nil, fmt.Errorf("%w: unknown pool unit %q", ErrInvalidTraining, t.PoolUnit)
+	}
+	if t.PoolLength <= 0 {
+		return nil, fmt.Errorf("%w: pool length %d", ErrInvalidTraining, t.PoolLength)
+	}
+
+	sets := slices.Clone(t.Sets)
+	slices.SortStableFunc(sets, func(a, b data.TrainingSet) int { return a.SetOrder - b.SetOrder })
+
+	var steps [][]field
+	for _, s := range sets {
+		if s.Repeat <= 0 {
+			return nil, fmt.Errorf("%w: set %d repeats %d times", ErrInvalidTraining, s.SetOrder, s.Repeat)
+		}
+		first := len(steps)
+
+		// distances are stored in meters, in yard pools those are rounded
+		// from whole lengths in yards
+		lengths := math.Round(float64(s.DistanceMeters) / poolLength)
+		swim := []field{
+			enumField(1, DurationDistance),
+			uint32Field(2, uint32(math.Round(lengths*poolLength*100))),
+			enumField(3, targetOpen),
+			enumField(7, IntensityActive),
+		}
+		if s.Description != nil && *s.Description != "" {
+			swim = append(swim, stringField(0, *s.Description, maxNameBytes))
+		}
+		if s.Group != nil {
+			swim = append(swim, stringField(8, *s.Group+" group", maxNotesBytes))
+		}
+		if s.Equipment != nil {
+			for _, e := range *s.Equipment {
+				if v, ok := equipment[apidef.EquipmentEnum(e)]; ok {
+					swim = append(swim, enumField(9, v))
+					break
+				}
+			}
+		}
+		steps = append(steps, swim)
+
+		if rest, ok := restDuration(s); ok {
+			steps = append(steps, []field{
+				enumField(1, rest),
+				uint32Field(2, uint32(*s.StartSeconds)*1000),
+				enumField(3, targetOpen),
+				enumField(7, IntensityRest),
+			})
+		}
+
+		if s.Repeat > 1 {
+			steps = append(steps, []field{
+				enumField(1, DurationRepeatUntilSteps),
+				uint32Field(2, uint32(first)),
+				uint32Field(4, uint32(s.Repeat)),
+			})
+		}
+	}
+	if len(steps) > math.MaxUint16-1 {
+		return nil, fmt.Errorf("%w: %d steps", ErrInvalidTraining, len(steps))
+	}
+
+	var e encoder
+	e.write(
+		MesgFileId,
+		enumField(0, fileWorkout),
+		uint16Field(1, manufacturerDev),
+		uint16Field(2, 0),
+		uint32zField(3, max(binary.BigEndian.Uint32(t.Id[:4]), 1)),
+		dateTimeField(4, t.Start),
+		stringField(8, "swimlogs", maxNameBytes),
+	)
+	e.write(
+		MesgWorkout,
+		enumField(4, sportSwimming),
+		uint16Field(6, uint16(len(steps))),
+		stringField(8, name, maxNameBytes),
+		enumField(11, subSportLapSwimming),
+		uint16Field(14, uint16(math.Round(poolLength*100))),
+		enumField(15, unit),
+	)
+	for i, step := range steps {
+		e.write(MesgWorkoutStep, append([]field{uint16Field(254, uint16(i))}, step...)...)
+	}
+	return e.bytes(), nil
+}
+
+// restDuration returns duration type of the rest step after a repetition of
+// s, false if s has no start.
+func restDuration(s data.TrainingSet) (byte, bool) {
+	if s.StartSeconds == nil || *s.StartSeconds <= 0 {
+		return 0, false
+	}
+	switch apidef.StartTypeEnum(s.StartType) {
+	case apidef.Pause:
+		return DurationTime, true
+	case apidef.Interval:
+		return DurationRepetitionTime, true
+	}
+	return 0, false
+}
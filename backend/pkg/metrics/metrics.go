@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "swimlogs"
+
+// Registry holds all metrics exposed by Handler.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	httpRequests = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of handled HTTP requests by OpenAPI operation id and status code.",
+		},
+		[]string{"operation", "status"},
+	)
+	httpDuration = factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled HTTP requests by OpenAPI operation id and status code.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"operation", "status"},
+	)
+
+	TrainingsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "trainings_created_total",
+		Help:      "Number of created trainings.",
+	})
+	TrainingsEdited = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "trainings_edited_total",
+		Help:      "Number of edited trainings.",
+	})
+	TrainingsDeleted = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "trainings_deleted_total",
+		Help:      "Number of deleted trainings.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records one handled request of an OpenAPI operation.
+func ObserveRequest(operation string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(operation, code).Inc()
+	httpDuration.WithLabelValues(operation, code).Observe(duration.Seconds())
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pgxPoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// PgxPoolCollector exposes connection statistics of a pgx pool.
+type PgxPoolCollector struct {
+	pool pgxPoolStater
+
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	totalConns      *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+}
+
+func NewPgxPoolCollector(pool pgxPoolStater) *PgxPoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+
+	return &PgxPoolCollector{
+		pool:          pool,
+		acquiredConns: desc("acquired_connections", "Number of currently acquired connections."),
+		idleConns:     desc("idle_connections", "Number of currently idle connections."),
+		totalConns:    desc("total_connections", "Number of all open connections."),
+		maxConns:      desc("max_connections", "Maximum size of the pool."),
+		acquireCount:  desc("acquires_total", "Number of successful acquires from the pool."),
+		acquireDuration: desc(
+			"acquire_duration_seconds_total",
+			"Total duration of all successful acquires from the pool.",
+		),
+		emptyAcquire: desc(
+			"empty_acquires_total",
+			"Number of acquires that waited for a connection because the pool was empty.",
+		),
+	}
+}
+
+func (c *PgxPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PgxPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(
+		c.acquireDuration,
+		prometheus.CounterValue,
+		s.AcquireDuration().Seconds(),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.emptyAcquire,
+		prometheus.CounterValue,
+		float64(s.EmptyAcquireCount()),
+	)
+}
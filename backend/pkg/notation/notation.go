@@ -0,0 +1,296 @@
+// Package notation is the plain-text workout notation coaches use to write
+// sets, one set per line:
+//
+//	[REPEATx]DISTANCE [description] [@INTERVAL | PPAUSE] [equipment] [[group]]
+//
+// like "8x100 fr @1:40 fins [sprint]" or "4x50 kick P20". Distances are in
+// units of the pool, times are seconds or minutes:seconds. Equipment and
+// group are their API names, case insensitive. Words which aren't anything
+// else are the description, a description in double quotes is taken as is.
+// Empty lines and lines starting with # are skipped.
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+var ErrInvalid = errors.New("invalid notation")
+
+// SyntaxError lists every invalid line of a notation.
+type SyntaxError struct {
+	Lines []LineError
+}
+
+type LineError struct {
+	// Line number, starting at 1
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	msgs := make([]string, 0, len(e.Lines))
+	for _, l := range e.Lines {
+		msgs = append(msgs, fmt.Sprintf("line %d: %s", l.Line, l.Msg))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalid
+}
+
+var equipment = []apidef.EquipmentEnum{
+	apidef.Fins,
+	apidef.Monofin,
+	apidef.Snorkel,
+	apidef.Board,
+	apidef.Paddles,
+}
+
+var groups = []apidef.GroupEnum{
+	apidef.Sprint,
+	apidef.Middle,
+	apidef.Long,
+	apidef.Mono,
+	apidef.Bifi,
+}
+
+// Parse parses sets of a training in pool p, ordered as written. Distances
+// are set to DistanceYards in yard pools and to DistanceMeters otherwise,
+// totals aren't calculated. On invalid lines it returns *SyntaxError.
+func Parse(text string, p apidef.Pool) ([]apidef.NewTrainingSet, error) {
+	sets := make([]apidef.NewTrainingSet, 0)
+	var errs []LineError
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		set, err := parseLine(line, p)
+		if err != nil {
+			errs = append(errs, LineError{Line: i + 1, Msg: err.Error()})
+			continue
+		}
+		set.SetOrder = len(sets)
+		sets = append(sets, set)
+	}
+
+	if len(errs) > 0 {
+		return nil, &SyntaxError{Lines: errs}
+	}
+	return sets, nil
+}
+
+func parseLine(line string, p apidef.Pool) (apidef.NewTrainingSet, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return apidef.NewTrainingSet{}, err
+	}
+
+	set := apidef.NewTrainingSet{Repeat: 1, StartType: apidef.None}
+	distance, err := parseDistance(tokens[0].text, &set.Repeat)
+	if err != nil {
+		return apidef.NewTrainingSet{}, err
+	}
+	if distance%p.Length != 0 {
+		return apidef.NewTrainingSet{}, fmt.Errorf(
+			"distance %d isn't a multiple of pool length %d",
+			distance,
+			p.Length,
+		)
+	}
+	if p.Unit == apidef.Yards {
+		set.DistanceYards = &distance
+	} else {
+		set.DistanceMeters = distance
+	}
+
+	var description []string
+	for _, t := range tokens[1:] {
+		if t.quoted {
+			description = append(description, t.text)
+			continue
+		}
+
+		switch kind, value := classify(t.text); kind {
+		case interval, pause:
+			if set.StartType != apidef.None {
+				return apidef.NewTrainingSet{}, fmt.Errorf("second start %q", t.text)
+			}
+			seconds, err := parseSeconds(value)
+			if err != nil {
+				return apidef.NewTrainingSet{}, err
+			}
+			set.StartType = apidef.Interval
+			if kind == pause {
+				set.StartType = apidef.Pause
+			}
+			set.StartSeconds = &seconds
+		case equipmentItem:
+			item := apidef.EquipmentEnum(value)
+			if set.Equipment == nil {
+				set.Equipment = &[]apidef.EquipmentEnum{}
+			}
+			if !slices.Contains(*set.Equipment, item) {
+				*set.Equipment = append(*set.Equipment, item)
+			}
+		case group:
+			g, ok := findGroup(value)
+			if !ok {
+				return apidef.NewTrainingSet{}, fmt.Errorf("unknown group %q", value)
+			}
+			if set.Group != nil {
+				return apidef.NewTrainingSet{}, fmt.Errorf("second group %q", value)
+			}
+			set.Group = &g
+		default:
+			description = append(description, t.text)
+		}
+	}
+
+	if len(description) > 0 {
+		d := strings.Join(description, " ")
+		set.Description = &d
+	}
+	return set, nil
+}
+
+// parseDistance parses "8x100" or "100", setting repeat if present.
+func parseDistance(s string, repeat *int) (int, error) {
+	if r, d, ok := cutRepeat(s); ok {
+		n, err := strconv.Atoi(r)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid repeat %q", r)
+		}
+		*repeat = n
+		s = d
+	}
+
+	distance, err := strconv.Atoi(s)
+	if err != nil || distance <= 0 {
+		return 0, fmt.Errorf("invalid distance %q, a set starts with [REPEATx]DISTANCE", s)
+	}
+	return distance, nil
+}
+
+func cutRepeat(s string) (string, string, bool) {
+	for _, sep := range []string{"x", "X", "×"} {
+		if r, d, ok := strings.Cut(s, sep); ok {
+			return r, d, true
+		}
+	}
+	return "", "", false
+}
+
+// parseSeconds parses "100" or "1:40".
+func parseSeconds(s string) (int, error) {
+	minutes, seconds := "0", s
+	if m, sec, ok := strings.Cut(s, ":"); ok {
+		minutes, seconds = m, sec
+		if len(sec) != 2 {
+			return 0, fmt.Errorf("invalid time %q, must be seconds or minutes:seconds", s)
+		}
+	}
+
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 {
+		return 0, fmt.Errorf("invalid time %q, must be seconds or minutes:seconds", s)
+	}
+	sec, err := strconv.Atoi(seconds)
+	if err != nil || sec < 0 || (m > 0 && sec >= 60) {
+		return 0, fmt.Errorf("invalid time %q, must be seconds or minutes:seconds", s)
+	}
+
+	total := m*60 + sec
+	if total == 0 {
+		return 0, fmt.Errorf("invalid time %q, must be positive", s)
+	}
+	return total, nil
+}
+
+type tokenKind int
+
+const (
+	word tokenKind = iota
+	interval
+	pause
+	equipmentItem
+	group
+)
+
+// classify returns what an unquoted token is and its value without the
+// marker.
+func classify(token string) (tokenKind, string) {
+	switch {
+	case strings.HasPrefix(token, "@"):
+		return interval, token[1:]
+	case len(token) > 1 && (token[0] == 'P' || token[0] == 'p') && isTime(token[1:]):
+		return pause, token[1:]
+	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
+		return group, token[1 : len(token)-1]
+	}
+	for _, e := range equipment {
+		if strings.EqualFold(token, string(e)) {
+			return equipmentItem, string(e)
+		}
+	}
+	return word, token
+}
+
+// isTime reports whether s looks like a time, it can still be invalid.
+func isTime(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != ':'
+	}) == -1
+}
+
+func findGroup(s string) (apidef.GroupEnum, bool) {
+	for _, g := range groups {
+		if strings.EqualFold(s, string(g)) {
+			return g, true
+		}
+	}
+	return "", false
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits line by spaces, double quoted strings are one token with
+// Go escapes.
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		if line[0] != '"' {
+			end := strings.IndexFunc(line, unicode.IsSpace)
+			if end == -1 {
+				end = len(line)
+			}
+			tokens = append(tokens, token{text: line[:end]})
+			line = line[end:]
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("unterminated quote in %q", line)
+		}
+		text, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted description %s", quoted)
+		}
+		tokens = append(tokens, token{text: text, quoted: true})
+		line = line[len(quoted):]
+	}
+	return tokens, nil
+}
@@ -0,0 +1,99 @@
+package notation
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+const metersPerYard = 0.9144
+
+// Render writes sets of t in the notation, one line per set ordered by set
+// order. Parsing the result gives back the same sets.
+func Render(t apidef.Training) string {
+	p := apidef.Pool{Length: 25, Unit: apidef.Meters}
+	if t.Pool != nil {
+		p = *t.Pool
+	}
+
+	sets := slices.Clone(t.Sets)
+	slices.SortStableFunc(sets, func(a, b apidef.TrainingSet) int { return a.SetOrder - b.SetOrder })
+
+	var b strings.Builder
+	for _, s := range sets {
+		b.WriteString(RenderSet(s, p))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// RenderSet writes one set of a training in pool p in the notation.
+func RenderSet(s apidef.TrainingSet, p apidef.Pool) string {
+	distance := SetDistance(s, p)
+	parts := []string{strconv.Itoa(distance)}
+	if s.Repeat != 1 {
+		parts[0] = fmt.Sprintf("%dx%d", s.Repeat, distance)
+	}
+	if s.Description != nil && *s.Description != "" {
+		parts = append(parts, renderDescription(*s.Description))
+	}
+	if s.StartSeconds != nil {
+		switch s.StartType {
+		case apidef.Interval:
+			parts = append(parts, "@"+FormatSeconds(*s.StartSeconds))
+		case apidef.Pause:
+			parts = append(parts, "P"+FormatSeconds(*s.StartSeconds))
+		}
+	}
+	if s.Equipment != nil {
+		for _, e := range *s.Equipment {
+			parts = append(parts, strings.ToLower(string(e)))
+		}
+	}
+	if s.Group != nil {
+		parts = append(parts, "["+string(*s.Group)+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// SetDistance returns distance of one repetition of s in units of pool p.
+// In yard pools it is DistanceYards, or DistanceMeters converted to yards
+// and rounded to the closest multiple of pool length when it is missing.
+func SetDistance(s apidef.TrainingSet, p apidef.Pool) int {
+	if p.Unit != apidef.Yards {
+		return s.DistanceMeters
+	}
+	if s.DistanceYards != nil {
+		return *s.DistanceYards
+	}
+	lengths := math.Round(float64(s.DistanceMeters) / metersPerYard / float64(p.Length))
+	return int(lengths) * p.Length
+}
+
+// FormatSeconds formats seconds as minutes:seconds, or just seconds when
+// shorter than a minute.
+func FormatSeconds(seconds int) string {
+	if seconds < 60 {
+		return strconv.Itoa(seconds)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// renderDescription quotes description if its words wouldn't be parsed
+// back as the same description.
+func renderDescription(description string) string {
+	words := strings.Fields(description)
+	if strings.Join(words, " ") != description || strings.ContainsRune(description, '"') {
+		return strconv.Quote(description)
+	}
+	for _, w := range words {
+		if kind, _ := classify(w); kind != word {
+			return strconv.Quote(description)
+		}
+	}
+	return description
+}
@@ -0,0 +1,265 @@
+// Package seed generates fake but plausible trainings, for filling a
+// database during development and for larger test fixtures. Generated
+// trainings depend only on options, the same options always generate the
+// same trainings, including their ids.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+var ErrInvalidOptions = errors.New("invalid seed options")
+
+type Options struct {
+	// Seed of the random generator
+	Seed int64
+	// Count of generated trainings
+	Count int
+	// From and To bound days on which generated trainings start, including
+	// both, trainings start in From's location
+	From time.Time
+	To   time.Time
+}
+
+// DefaultOptions generates 200 trainings during the year before now.
+func DefaultOptions(now time.Time) Options {
+	return Options{Seed: 1, Count: 200, From: now.AddDate(-1, 0, 0), To: now}
+}
+
+func (o Options) validate() error {
+	if o.Count < 0 {
+		return fmt.Errorf("%w: count %d is negative", ErrInvalidOptions, o.Count)
+	}
+	if o.To.Before(o.From) {
+		return fmt.Errorf("%w: to %s is before from %s", ErrInvalidOptions, o.To, o.From)
+	}
+	return nil
+}
+
+// Trainings generates trainings, ordered by start.
+func Trainings(opts Options) ([]data.Training, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("Trainings: %w", err)
+	}
+
+	g := generator{rand.New(rand.NewSource(opts.Seed))}
+	loc := opts.From.Location()
+	first := time.Date(opts.From.Year(), opts.From.Month(), opts.From.Day(), 0, 0, 0, 0, loc)
+	last := time.Date(opts.To.Year(), opts.To.Month(), opts.To.Day(), 0, 0, 0, 0, loc)
+	days := int(last.Sub(first).Hours()/24+0.5) + 1
+
+	starts := make([]time.Time, opts.Count)
+	for i := range starts {
+		day := first.AddDate(0, 0, g.Intn(days))
+		hour := pick(g, trainingHours)
+		starts[i] = day.Add(hour)
+	}
+	slices.SortFunc(starts, time.Time.Compare)
+
+	trainings := make([]data.Training, 0, opts.Count)
+	for _, start := range starts {
+		trainings = append(trainings, g.training(start))
+	}
+	return trainings, nil
+}
+
+// Persist generates trainings and persists them with repo in one
+// transaction. Trainings whose ids are already stored, seeded by an earlier
+// run with the same options, are skipped and only the persisted ones are
+// returned.
+func Persist(ctx context.Context, repo data.TrainingRepository, opts Options) ([]data.Training, error) {
+	trainings, err := Trainings(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Persist: %w", err)
+	}
+
+	trainings, err = repo.PersistNewTrainings(ctx, trainings)
+	if err != nil {
+		return nil, fmt.Errorf("Persist: %w", err)
+	}
+	return trainings, nil
+}
+
+// trainingHours are offsets from midnight at which the club trains
+var trainingHours = []time.Duration{
+	6 * time.Hour,
+	6*time.Hour + 30*time.Minute,
+	17 * time.Hour,
+	18*time.Hour + 30*time.Minute,
+	19 * time.Hour,
+}
+
+var (
+	fins    = string(apidef.Fins)
+	monofin = string(apidef.Monofin)
+	snorkel = string(apidef.Snorkel)
+	board   = string(apidef.Board)
+	paddles = string(apidef.Paddles)
+)
+
+var groups = []string{
+	string(apidef.Sprint),
+	string(apidef.Middle),
+	string(apidef.Long),
+	string(apidef.Mono),
+	string(apidef.Bifi),
+}
+
+// block is a part of a training, blocks are generated in order, optional
+// ones only sometimes.
+type block struct {
+	optional     bool
+	repeats      []int
+	distances    []int
+	descriptions []string
+	equipment    [][]string
+	startType    apidef.StartTypeEnum
+	// startSeconds are per 100 meters for intervals and absolute for pauses
+	startSeconds []int
+	grouped      bool
+}
+
+var blocks = []block{
+	{
+		repeats:      []int{1},
+		distances:    []int{200, 300, 400},
+		descriptions: []string{"warm up", "easy freestyle", "choice, easy"},
+		equipment:    [][]string{nil, {fins}},
+		startType:    apidef.None,
+	},
+	{
+		optional:     true,
+		repeats:      []int{4, 6, 8},
+		distances:    []int{50},
+		descriptions: []string{"kick", "drill, catch up", "kick on side", "dolphin kick"},
+		equipment:    [][]string{{board}, {fins}, {board, fins}, {snorkel}},
+		startType:    apidef.Pause,
+		startSeconds: []int{15, 20, 30},
+	},
+	{
+		optional:     true,
+		repeats:      []int{3, 4},
+		distances:    []int{100, 200},
+		descriptions: []string{"pull", "pull, breathe every 3"},
+		equipment:    [][]string{{paddles}, {paddles, snorkel}},
+		startType:    apidef.Pause,
+		startSeconds: []int{20, 30},
+	},
+	{
+		repeats:      []int{5, 6, 8, 10, 12},
+		distances:    []int{100, 100, 200},
+		descriptions: []string{"main set", "threshold", "descend 1-4", "race pace"},
+		equipment:    [][]string{nil, {fins}, {monofin}, {monofin, snorkel}},
+		startType:    apidef.Interval,
+		startSeconds: []int{80, 90, 100, 110},
+		grouped:      true,
+	},
+	{
+		optional:     true,
+		repeats:      []int{4, 6, 8},
+		distances:    []int{25, 50},
+		descriptions: []string{"sprint", "max speed", "fast start"},
+		equipment:    [][]string{{fins}, {monofin}, {monofin, snorkel}},
+		startType:    apidef.Interval,
+		startSeconds: []int{120, 160},
+		grouped:      true,
+	},
+	{
+		repeats:      []int{1},
+		distances:    []int{100, 200},
+		descriptions: []string{"cool down", "easy"},
+		equipment:    [][]string{nil},
+		startType:    apidef.None,
+	},
+}
+
+type generator struct {
+	*rand.Rand
+}
+
+func pick[T any](g generator, items []T) T {
+	return items[g.Intn(len(items))]
+}
+
+func (g generator) training(start time.Time) data.Training {
+	t := data.Training{
+		Id:         g.uuid(),
+		Start:      start,
+		PoolLength: pick(g, []int{25, 25, 50}),
+		PoolUnit:   string(apidef.Meters),
+	}
+
+	for _, b := range blocks {
+		if b.optional && g.Intn(2) == 0 {
+			continue
+		}
+		set := g.set(t, b)
+		t.Sets = append(t.Sets, set)
+		t.TotalDistance += set.TotalDistance
+	}
+
+	// roughly 40 meters a minute with rests, rounded up to quarter hours
+	minutes := t.TotalDistance / 40
+	t.DurationMin = max(45, (minutes+14)/15*15)
+	return t
+}
+
+func (g generator) set(t data.Training, b block) data.TrainingSet {
+	distance := pick(g, b.distances)
+	if distance < t.PoolLength {
+		distance = t.PoolLength
+	}
+	distance = distance / t.PoolLength * t.PoolLength
+	repeat := pick(g, b.repeats)
+
+	set := data.TrainingSet{
+		Id:             g.uuid(),
+		TrainingId:     t.Id,
+		SetOrder:       len(t.Sets),
+		Repeat:         repeat,
+		DistanceMeters: distance,
+		TotalDistance:  repeat * distance,
+		StartType:      string(b.startType),
+	}
+
+	description := pick(g, b.descriptions)
+	set.Description = &description
+
+	equipment := append([]string{}, pick(g, b.equipment)...)
+	set.Equipment = &equipment
+
+	switch b.startType {
+	case apidef.Interval:
+		seconds := pick(g, b.startSeconds) * distance / 100
+		seconds = max(20, (seconds+4)/5*5)
+		set.StartSeconds = &seconds
+	case apidef.Pause:
+		seconds := pick(g, b.startSeconds)
+		set.StartSeconds = &seconds
+	}
+
+	if b.grouped && g.Intn(3) > 0 {
+		group := pick(g, groups)
+		set.Group = &group
+	}
+	return set
+}
+
+func (g generator) uuid() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(g)
+	if err != nil {
+		// reading from rand.Rand never fails
+		panic(err)
+	}
+	return id
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+// MaxRestoreBytes limits archives uploaded to /admin/restore. Admin
+// endpoints aren't under the operation limits, archives are much larger
+// than other bodies.
+const MaxRestoreBytes = 64 << 20
+
+// requireAdmin allows only requests with token as their bearer token. When
+// token is empty admin endpoints are disabled and not found.
+func requireAdmin(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				respondWithCode(w, http.StatusNotFound)
+				return
+			}
+
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				log.Warn().Str("path", r.URL.Path).Msg("unauthorized admin request")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="swimlogs admin"`)
+				respondWithCode(w, http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// (GET /admin/backup)
+func (s *SwimLogsServer) Backup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(ContentType, ApplicationJSON)
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="swimlogs-backup-%s.json"`, time.Now().Format(time.DateOnly)),
+	)
+
+	bw := &writtenWriter{w: w}
+	contents, err := s.app.Backup(r.Context(), bw)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to back up")
+		// once the archive started streaming, the status is already sent
+		if !bw.written {
+			w.Header().Del("Content-Disposition")
+			respondWithCode(w, http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Info().Interface("contents", contents).Msg("backed up")
+}
+
+// (POST /admin/restore)
+func (s *SwimLogsServer) Restore(w http.ResponseWriter, r *http.Request) {
+	mode := app.RestoreMerge
+	if m := r.URL.Query().Get("mode"); m != "" {
+		mode = app.RestoreMode(m)
+	}
+
+	if r.ContentLength > MaxRestoreBytes {
+		log.Warn().Int64("content_length", r.ContentLength).Msg("backup archive too large")
+		respondWithCode(w, http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRestoreBytes)
+
+	contents, err := s.app.Restore(r.Context(), r.Body, mode)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		log.Warn().Err(err).Msg("backup archive too large")
+		respondWithCode(w, http.StatusRequestEntityTooLarge)
+		return
+	} else if errors.Is(err, app.ErrInvalidRestoreMode) || errors.Is(err, app.ErrInvalidBackup) {
+		log.Warn().Err(err).Msg("invalid restore request")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("failed to restore")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Interface("contents", contents).Str("mode", string(mode)).Msg("restored")
+	respondWithJSON(w, http.StatusOK, contents)
+}
+
+// writtenWriter records whether anything was written to w.
+type writtenWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (ww *writtenWriter) Write(b []byte) (int, error) {
+	ww.written = true
+	return ww.w.Write(b)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/oapi-codegen/runtime/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+// (PUT /trainings/{id}/attendance)
+func (s *SwimLogsServer) MarkAttendance(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	req, err := readJSON[apidef.MarkAttendanceRequest](w, r)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	attendance, err := s.app.MarkAttendance(r.Context(), id, req.Attendance)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("training or user not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if errors.Is(err, app.ErrInvalidAttendance) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("invalid attendance")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.AttendanceResponse{Attendance: attendance}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// (GET /trainings/{id}/attendance)
+func (s *SwimLogsServer) Attendance(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	attendance, err := s.app.Attendance(r.Context(), id)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("training not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.AttendanceResponse{Attendance: attendance}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// (GET /attendance/report)
+func (s *SwimLogsServer) AttendanceReport(
+	w http.ResponseWriter,
+	r *http.Request,
+	params apidef.AttendanceReportParams,
+) {
+	trainings, summaries, err := s.app.AttendanceReport(
+		r.Context(),
+		params.From.Time,
+		params.To.Time,
+		params.Tz,
+	)
+	if errors.Is(err, app.ErrInvalidDateRange) || errors.Is(err, app.ErrInvalidTimezone) {
+		log.Warn().
+			Err(err).
+			Str("from", params.From.String()).
+			Str("to", params.To.String()).
+			Msg("invalid query params")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.AttendanceReportResponse{Trainings: trainings, Summaries: summaries}
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AnyOrigin in Cors.Origins allows requests from every origin, meant only
+// for development.
+const AnyOrigin = "*"
+
+// Cors configures which cross-origin requests browsers are allowed to make.
+type Cors struct {
+	// Origins allowed to make requests, like https://www.swimlogs.com
+	Origins []string
+	Methods []string
+	Headers []string
+	// AllowCredentials allows requests with cookies and Authorization
+	AllowCredentials bool
+	// MaxAge is for how long can browsers cache a preflight response
+	MaxAge time.Duration
+}
+
+var DefaultCors = Cors{
+	Methods: []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodDelete,
+		http.MethodOptions,
+	},
+	Headers: []string{"Content-Type", "Authorization", "If-Match"},
+	MaxAge:  10 * time.Minute,
+}
+
+func (c Cors) allowsOrigin(origin string) bool {
+	return slices.Contains(c.Origins, AnyOrigin) || slices.Contains(c.Origins, origin)
+}
+
+func (c Cors) allowsMethod(method string) bool {
+	return slices.Contains(c.Methods, method)
+}
+
+func (c Cors) allowsHeaders(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if !slices.ContainsFunc(c.Headers, func(allowed string) bool { return strings.EqualFold(allowed, h) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// cors adds CORS headers to responses for allowed origins and responds to
+// preflight requests. Requests from other origins are passed through without
+// CORS headers, so browsers block them.
+func cors(opts Cors) func(http.Handler) http.Handler {
+	methods := strings.Join(opts.Methods, ", ")
+	headers := strings.Join(opts.Headers, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions &&
+				r.Header.Get("Access-Control-Request-Method") != ""
+
+			h.Add("Vary", "Origin")
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !opts.allowsOrigin(origin) {
+				if preflight {
+					respondWithCode(w, http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// browsers reject wildcard when credentials are allowed
+			if slices.Contains(opts.Origins, AnyOrigin) && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", AnyOrigin)
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if opts.allowsMethod(r.Header.Get("Access-Control-Request-Method")) &&
+				opts.allowsHeaders(r.Header.Get("Access-Control-Request-Headers")) {
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				if opts.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", maxAge)
+				}
+			}
+			respondWithCode(w, http.StatusNoContent)
+		})
+	}
+}
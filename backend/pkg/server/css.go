@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/oapi-codegen/runtime/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+// (POST /users/{id}/css-tests)
+func (s *SwimLogsServer) CreateCssTest(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	req, err := readJSON[apidef.CreateCssTestRequest](w, r)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	c, err := s.app.CreateCssTest(r.Context(), id, req)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("user not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if errors.Is(err, app.ErrInvalidCssTest) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("invalid css test")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.CssTestResponse(c)
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// (GET /users/{id}/css-tests)
+func (s *SwimLogsServer) CssTests(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	tests, err := s.app.CssTests(r.Context(), id)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("user not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.CssTestsResponse{Tests: tests}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// (GET /trainings/{id}/interval-suggestions)
+func (s *SwimLogsServer) IntervalSuggestions(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+	params apidef.IntervalSuggestionsParams,
+) {
+	c, suggestions, err := s.app.IntervalSuggestions(r.Context(), id, params.UserId)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().
+			Err(err).
+			Str("id", id.String()).
+			Str("userId", params.UserId.String()).
+			Msg("training or css test not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.IntervalSuggestionsResponse{CssTest: c, Sets: suggestions}
+	respondWithJSON(w, http.StatusOK, response)
+}
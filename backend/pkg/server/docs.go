@@ -0,0 +1,33 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+)
+
+const ApplicationYAML = "application/yaml"
+
+//go:embed docs.html
+var docsPage []byte
+
+// (GET /api/openapi.yaml)
+func (s *SwimLogsServer) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	respondWithBytes(w, ApplicationYAML, apidef.Spec)
+}
+
+// (GET /api/docs)
+func (s *SwimLogsServer) Docs(w http.ResponseWriter, r *http.Request) {
+	respondWithBytes(w, "text/html; charset=utf-8", docsPage)
+}
+
+func respondWithBytes(w http.ResponseWriter, contentType string, b []byte) {
+	w.Header().Set(ContentType, contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		log.Error().Err(err).Msg("Failed to write response")
+	}
+}
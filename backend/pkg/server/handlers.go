@@ -2,13 +2,18 @@ package server
 
 import (
 	"errors"
+	"io"
+	"mime"
 	"net/http"
 
 	"github.com/oapi-codegen/runtime/types"
 	"github.com/rs/zerolog/log"
 
 	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/activity"
 	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/fit"
+	"github.com/Nesquiko/swimlogs/pkg/notation"
 )
 
 // (POST /trainings)
@@ -20,7 +25,7 @@ func (s *SwimLogsServer) CreateTraining(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	td, err := s.app.CreateTraining(req)
+	td, err := s.app.CreateTraining(r.Context(), req)
 	if err != nil {
 		log.Warn().Err(err).Msg("invalid request")
 		respondWithCode(w, http.StatusBadRequest)
@@ -29,6 +34,87 @@ func (s *SwimLogsServer) CreateTraining(w http.ResponseWriter, r *http.Request)
 	respondWithJSON(w, http.StatusCreated, td)
 }
 
+// (POST /trainings/parse)
+func (s *SwimLogsServer) ParseNotation(w http.ResponseWriter, r *http.Request) {
+	req, err := readJSON[apidef.ParseNotationRequest](w, r)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	parsed, err := s.app.ParseNotation(r.Context(), req)
+	var syntaxErr *notation.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		log.Warn().Err(err).Msg("invalid notation")
+		respondWithJSON(w, http.StatusBadRequest, apidef.ErrorDetail{
+			Title:  "Invalid notation",
+			Status: http.StatusBadRequest,
+			Code:   "invalid_notation",
+			Detail: syntaxErr.Error(),
+		})
+		return
+	} else if errors.Is(err, app.ErrInvalidPool) || errors.Is(err, app.ErrInvalidDistance) {
+		log.Warn().Err(err).Msg("invalid pool")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, parsed)
+}
+
+// (POST /trainings/import)
+func (s *SwimLogsServer) ImportActivity(
+	w http.ResponseWriter,
+	r *http.Request,
+	params apidef.ImportActivityParams,
+) {
+	file, err := io.ReadAll(r.Body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		log.Warn().Err(err).Msg("activity file too large")
+		respondWithCode(w, http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	var pool *apidef.Pool
+	if params.PoolLength != nil {
+		pool = &apidef.Pool{Length: *params.PoolLength, Unit: apidef.Meters}
+		if params.PoolUnit != nil {
+			pool.Unit = *params.PoolUnit
+		}
+	}
+
+	td, err := s.app.ImportActivity(r.Context(), file, pool)
+	var activityErr *activity.Error
+	if errors.As(err, &activityErr) {
+		log.Warn().Err(err).Msg("invalid activity")
+		respondWithJSON(w, http.StatusBadRequest, apidef.ErrorDetail{
+			Title:  "Invalid activity",
+			Status: http.StatusBadRequest,
+			Code:   "invalid_activity",
+			Detail: activityErr.Reason,
+		})
+		return
+	} else if errors.Is(err, app.ErrInvalidPool) || errors.Is(err, app.ErrInvalidDistance) {
+		log.Warn().Err(err).Msg("invalid pool")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, td)
+}
+
 // (GET /trainings/details)
 func (s *SwimLogsServer) TrainingDetails(
 	w http.ResponseWriter,
@@ -44,7 +130,11 @@ func (s *SwimLogsServer) TrainingDetails(
 		return
 	}
 
-	details, total, err := s.app.TrainingDetailsPage(params.Page, params.PageSize)
+	details, total, err := s.app.TrainingDetailsPage(
+		r.Context(),
+		params.Page,
+		params.PageSize,
+	)
 	if err != nil {
 		log.Warn().Err(err).Int("page", params.Page).Int("pageSize", params.PageSize)
 		respondWithCode(w, http.StatusInternalServerError)
@@ -57,9 +147,17 @@ func (s *SwimLogsServer) TrainingDetails(
 }
 
 // (GET /trainings/details/current-week)
-func (s *SwimLogsServer) TrainingDetailsCurrentWeek(w http.ResponseWriter, r *http.Request) {
-	details, err := s.app.TrainingDetailsCurrentWeek()
-	if err != nil {
+func (s *SwimLogsServer) TrainingDetailsCurrentWeek(
+	w http.ResponseWriter,
+	r *http.Request,
+	params apidef.TrainingDetailsCurrentWeekParams,
+) {
+	details, err := s.app.TrainingDetailsCurrentWeek(r.Context(), params.Tz)
+	if errors.Is(err, app.ErrInvalidTimezone) {
+		log.Warn().Err(err).Msg("invalid query params")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
 		log.Error().Err(err).Msg("internal server error")
 		respondWithCode(w, http.StatusInternalServerError)
 		return
@@ -77,7 +175,7 @@ func (s *SwimLogsServer) DeleteTraining(
 	r *http.Request,
 	id types.UUID,
 ) {
-	err := s.app.DeleteTraining(id)
+	err := s.app.DeleteTraining(r.Context(), id)
 	if errors.Is(err, app.ErrNotFound) {
 		log.Warn().Err(err).Str("id", id.String()).Msg("training not found")
 		respondWithCode(w, http.StatusNotFound)
@@ -96,8 +194,18 @@ func (s *SwimLogsServer) Training(
 	w http.ResponseWriter,
 	r *http.Request,
 	id types.UUID,
+	params apidef.TrainingParams,
 ) {
-	t, err := s.app.Training(id)
+	w.Header().Add("Vary", Accept)
+	// plain text first of texts, so clients accepting any text get it
+	mediaType := negotiate(r, ApplicationJSON, TextPlain, TextMarkdown, TextNotation)
+	if mediaType == "" {
+		log.Warn().Str("accept", r.Header.Get(Accept)).Msg("not acceptable")
+		respondWithCode(w, http.StatusNotAcceptable)
+		return
+	}
+
+	t, err := s.app.Training(r.Context(), id)
 	if errors.Is(err, app.ErrNotFound) {
 		log.Warn().Err(err).Str("id", id.String()).Msg("training not found")
 		respondWithCode(w, http.StatusNotFound)
@@ -108,8 +216,53 @@ func (s *SwimLogsServer) Training(
 		return
 	}
 
-	response := apidef.TrainingResponse(t)
-	respondWithJSON(w, http.StatusOK, response)
+	switch mediaType {
+	case ApplicationJSON:
+		response := apidef.TrainingResponse(t)
+		respondWithJSON(w, http.StatusOK, response)
+		return
+	case TextNotation:
+		respondWithBytes(w, TextNotation+"; charset=utf-8", []byte(notation.Render(t)))
+		return
+	}
+
+	format := app.PlainText
+	if mediaType == TextMarkdown {
+		format = app.Markdown
+	}
+	text, err := s.app.RenderTraining(t, format, params.Tz)
+	if errors.Is(err, app.ErrInvalidTimezone) {
+		log.Warn().Err(err).Msg("invalid query params")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+	respondWithBytes(w, mediaType+"; charset=utf-8", []byte(text))
+}
+
+// (GET /trainings/{id}/workout.fit)
+func (s *SwimLogsServer) TrainingWorkout(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	workout, err := s.app.TrainingWorkout(r.Context(), id)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("training not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": workout.Filename})
+	w.Header().Set("Content-Disposition", disposition)
+	respondWithBytes(w, fit.ContentType, workout.Content)
 }
 
 // (PUT /trainings/{id})
@@ -125,7 +278,7 @@ func (s *SwimLogsServer) EditTraining(
 		return
 	}
 
-	td, err := s.app.EditTraining(id, req)
+	td, err := s.app.EditTraining(r.Context(), id, req)
 	if errors.Is(err, app.ErrNotFound) {
 		log.Warn().Err(err).Str("id", id.String()).Msg("training not found")
 		respondWithCode(w, http.StatusNotFound)
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// limitersIdleTimeout is after how long are limiters of clients which
+	// didn't make any requests forgotten
+	limitersIdleTimeout = 5 * time.Minute
+)
+
+// Limit is the token bucket rate limit of one client and the maximum size
+// of request body of an operation.
+type Limit struct {
+	// Rate is how many requests per second a client can make, rate.Inf
+	// disables rate limiting
+	Rate rate.Limit
+	// Burst is how many requests a client can make at once
+	Burst    int
+	MaxBytes int64
+}
+
+// Limits are limits of operations, by OpenAPI operation id, operations
+// without their own use Default.
+type Limits struct {
+	Default    Limit
+	Operations map[string]Limit
+	// TrustedProxies are addresses of reverse proxies whose X-Real-Ip is
+	// the client address, it is ignored from anyone else
+	TrustedProxies []netip.Prefix
+}
+
+func (l Limits) of(operation string) Limit {
+	if ol, ok := l.Operations[operation]; ok {
+		return ol
+	}
+	return l.Default
+}
+
+var DefaultLimits = Limits{
+	Default: Limit{Rate: 10, Burst: 20, MaxBytes: MaxBytes},
+	Operations: map[string]Limit{
+		"CreateUser":     {Rate: 0.1, Burst: 5, MaxBytes: 4 << 10},
+		"CreateTraining": {Rate: 1, Burst: 10, MaxBytes: 256 << 10},
+		"EditTraining":   {Rate: 1, Burst: 10, MaxBytes: 256 << 10},
+		"ImportActivity": {Rate: 0.2, Burst: 5, MaxBytes: 8 << 20},
+	},
+}
+
+// limitRequests rejects requests of clients which exceeded rate limit of the
+// operation with 429 and bodies larger than its limit with 413. Clients are
+// identified by their IP address, from X-Real-Ip when the request comes from
+// a trusted proxy. Per user limits are out of scope, requests don't carry a
+// user until the API has user authentication.
+func limitRequests(operations map[string]string, limits Limits) func(http.Handler) http.Handler {
+	limiters := newClientLimiters()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			operation := operationId(operations, r)
+			l := limits.of(operation)
+
+			ip := clientIp(r, limits.TrustedProxies)
+			if delay := limiters.reserve(operation, ip, l); delay > 0 {
+				log.Warn().
+					Str("operation", operation).
+					Str("ip", ip).
+					Msg("rate limit exceeded")
+				seconds := int(math.Ceil(delay.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				respondWithCode(w, http.StatusTooManyRequests)
+				return
+			}
+
+			if r.ContentLength > l.MaxBytes {
+				log.Warn().
+					Str("operation", operation).
+					Int64("content_length", r.ContentLength).
+					Msg("request body too large")
+				respondWithCode(w, http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, l.MaxBytes)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIp(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	for _, proxy := range trustedProxies {
+		if !proxy.Contains(peer.Unmap()) {
+			continue
+		}
+		if ip, err := netip.ParseAddr(r.Header.Get("X-Real-Ip")); err == nil {
+			return ip.String()
+		}
+		break
+	}
+	return peer.String()
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// clientLimiters holds a token bucket for every client and operation.
+type clientLimiters struct {
+	mu        sync.Mutex
+	limiters  map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+func newClientLimiters() *clientLimiters {
+	return &clientLimiters{limiters: make(map[string]*clientLimiter), lastSweep: time.Now()}
+}
+
+// reserve takes a token from the bucket of client for operation, returning
+// how long the client must wait before the request is allowed, zero if it is
+// allowed right away.
+func (cl *clientLimiters) reserve(operation, client string, l Limit) time.Duration {
+	now := time.Now()
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if now.Sub(cl.lastSweep) > limitersIdleTimeout {
+		for key, c := range cl.limiters {
+			if now.Sub(c.lastSeen) > limitersIdleTimeout {
+				delete(cl.limiters, key)
+			}
+		}
+		cl.lastSweep = now
+	}
+
+	key := operation + " " + client
+	c, ok := cl.limiters[key]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.Rate, l.Burst)}
+		cl.limiters[key] = c
+	}
+	c.lastSeen = now
+
+	res := c.limiter.ReserveN(now, 1)
+	if !res.OK() {
+		// zero burst never allows any request
+		return time.Hour
+	}
+	delay := res.DelayFrom(now)
+	if delay > 0 {
+		res.CancelAt(now)
+	}
+	return delay
+}
@@ -1,23 +1,33 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
 	chiMidleware "github.com/go-chi/chi/v5/middleware"
 	nethttpmiddleware "github.com/oapi-codegen/nethttp-middleware"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/metrics"
 )
 
 // type StrictHTTPHandlerFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (response interface{}, err error)
 // type StrictHTTPMiddlewareFunc func(f StrictHTTPHandlerFunc, operationID string) StrictHTTPHandlerFunc
 
-func publicMiddleware(feOrigin string) []apidef.MiddlewareFunc {
+func publicMiddleware(corsOpts Cors, limits Limits) []apidef.MiddlewareFunc {
 	l := zerolog.New(os.Stdout).
 		With().
 		Timestamp().
@@ -29,10 +39,13 @@ func publicMiddleware(feOrigin string) []apidef.MiddlewareFunc {
 		log.Fatal().Err(err).Msg("failed to load OpenAPI spec")
 	}
 	oas.Servers = nil // removes validation of server, since we are using proxy
+	operations := operationIds(oas)
 
 	// dont move things around, order matters, executes last to first
 	return []apidef.MiddlewareFunc{
 		nethttpmiddleware.OapiRequestValidator(oas),
+		limitRequests(operations, limits),
+		observeOperations(operations),
 		hlog.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
 			hlog.FromRequest(r).Info().
 				Int("status", status).
@@ -44,29 +57,108 @@ func publicMiddleware(feOrigin string) []apidef.MiddlewareFunc {
 		hlog.RequestHandler("req"),
 		hlog.UserAgentHandler("user_agent"),
 		hlog.CustomHeaderHandler("ip", "X-Real-Ip"),
+		traceRequests(operations),
 		hlog.NewHandler(l),
-		cors(feOrigin),
+		cors(corsOpts),
 		chiMidleware.Recoverer,
 	}
 }
 
-func cors(feOrigin string) func(http.Handler) http.Handler {
+// OperationIds returns ids of all operations in the API spec, which limits
+// and metrics are keyed by. They are capitalized, like CreateUser.
+func OperationIds() ([]string, error) {
+	oas, err := apidef.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("OperationIds: %w", err)
+	}
+	ids := make([]string, 0)
+	for _, id := range operationIds(oas) {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// operationIds maps "METHOD /path/{param}" of every operation in oas to its
+// operation id.
+func operationIds(oas *openapi3.T) map[string]string {
+	operations := make(map[string]string)
+	for path, item := range oas.Paths.Map() {
+		for method, op := range item.Operations() {
+			operations[method+" "+path] = op.OperationID
+		}
+	}
+	return operations
+}
+
+// operationId returns the operation id of the route matched by r.
+func operationId(operations map[string]string, r *http.Request) string {
+	operation, ok := operations[r.Method+" "+chi.RouteContext(r.Context()).RoutePattern()]
+	if !ok {
+		return "unknown"
+	}
+	return operation
+}
+
+// observeOperations records request metrics labeled by the OpenAPI operation
+// id of the matched route.
+func observeOperations(operations map[string]string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// w.Header().Add("Vary", "Origin") not needed, because there is only one allowed origin
-			w.Header().Add("Vary", "Access-Control-Request-Method")
-			w.Header().Set("Access-Control-Allow-Origin", feOrigin)
+			start := time.Now()
+			ww := chiMidleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
 
-			if r.Method == http.MethodOptions &&
-				r.Header.Get("Access-Control-Request-Method") != "" {
-				w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, PUT, DELETE")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			metrics.ObserveRequest(operationId(operations, r), responseStatus(ww), time.Since(start))
+		})
+	}
+}
+
+// traceRequests starts a span named by the OpenAPI operation id for every
+// request, continuing a trace propagated by the caller, and adds its trace
+// id to the request logger.
+func traceRequests(operations map[string]string) func(http.Handler) http.Handler {
+	tracer := otel.Tracer("github.com/Nesquiko/swimlogs/pkg/server")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().
+				Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(
+				ctx,
+				operationId(operations, r),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(chi.RouteContext(r.Context()).RoutePattern()),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
 
-				w.WriteHeader(http.StatusOK)
-				return
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				hlog.FromRequest(r).UpdateContext(func(c zerolog.Context) zerolog.Context {
+					return c.Str("trace_id", sc.TraceID().String())
+				})
 			}
 
-			next.ServeHTTP(w, r)
+			ww := chiMidleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := responseStatus(ww)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
 		})
 	}
 }
+
+// responseStatus is the status written to ww, handlers which don't write
+// any respond with 200.
+func responseStatus(ww chiMidleware.WrapResponseWriter) int {
+	if ww.Status() == 0 {
+		return http.StatusOK
+	}
+	return ww.Status()
+}
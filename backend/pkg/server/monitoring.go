@@ -1,10 +1,79 @@
 package server
 
 import (
+	"context"
 	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	statusUp   = "up"
+	statusDown = "down"
 )
 
+// dependencyStatus is public, why a dependency is down is only logged
+type dependencyStatus struct {
+	Status string `json:"status"`
+}
+
+type readiness struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]dependencyStatus `json:"dependencies"`
+}
+
 // (GET /monitoring/heartbeat)
 func (s *SwimLogsServer) Heartbeat(w http.ResponseWriter, r *http.Request) {
 	respondWithCode(w, http.StatusOK)
 }
+
+// (GET /monitoring/ready)
+func (s *SwimLogsServer) Ready(w http.ResponseWriter, r *http.Request) {
+	res := readiness{Status: statusUp, Dependencies: map[string]dependencyStatus{}}
+	if s.shuttingDown.Load() {
+		res.Status = statusDown
+	}
+
+	for name, err := range s.app.Readiness(r.Context()) {
+		if err != nil {
+			log.Error().Err(err).Str("dependency", name).Msg("dependency isn't ready")
+			res.Status = statusDown
+			res.Dependencies[name] = dependencyStatus{Status: statusDown}
+			continue
+		}
+		res.Dependencies[name] = dependencyStatus{Status: statusUp}
+	}
+
+	code := http.StatusOK
+	if res.Status == statusDown {
+		code = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, code, res)
+}
+
+// GracefulShutdown marks the server as shutting down, so readiness probes
+// get 503, and keeps serving for drainDelay until load balancers notice and
+// stop sending requests. Then it closes listeners of srv and waits at most
+// timeout for in-flight requests. Done ctx cuts the drain delay short.
+func GracefulShutdown(
+	ctx context.Context,
+	srv *http.Server,
+	shuttingDown *atomic.Bool,
+	drainDelay, timeout time.Duration,
+) error {
+	shuttingDown.Store(true)
+
+	drain := time.NewTimer(drainDelay)
+	defer drain.Stop()
+	select {
+	case <-drain.C:
+	case <-ctx.Done():
+		log.Warn().Msg("drain delay cut short")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
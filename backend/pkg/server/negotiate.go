@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	Accept       = "Accept"
+	TextMarkdown = "text/markdown"
+	TextPlain    = "text/plain"
+	// TextNotation is sets of a training in the notation of POST
+	// /trainings/parse
+	TextNotation = "text/x-swimlogs-notation"
+)
+
+// negotiate returns the offered media type the client accepts the most by
+// the Accept header of r. Ties go to the earlier offer, so the first offer
+// is the default for requests without Accept. Returns "" if the client
+// accepts none of the offers.
+func negotiate(r *http.Request, offers ...string) string {
+	header := strings.Join(r.Header.Values(Accept), ",")
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(header, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality of the most specific media range in the
+// Accept header matching offer, 0 if none matches.
+func acceptQuality(header, offer string) float64 {
+	offerType, _, _ := strings.Cut(offer, "/")
+
+	q, specificity := 0.0, -1
+	for _, mediaRange := range strings.Split(header, ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		s := -1
+		switch {
+		case mediaType == offer:
+			s = 2
+		case mediaType == offerType+"/*":
+			s = 1
+		case mediaType == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		for _, p := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(name, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 && v <= 1 {
+					q = v
+				}
+			}
+		}
+	}
+	return q
+}
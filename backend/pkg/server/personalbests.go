@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/oapi-codegen/runtime/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+// (POST /users/{id}/race-results)
+func (s *SwimLogsServer) CreateRaceResult(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	req, err := readJSON[apidef.CreateRaceResultRequest](w, r)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.app.CreateRaceResult(r.Context(), id, req)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("user or training not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if errors.Is(err, app.ErrInvalidRaceResult) ||
+		errors.Is(err, app.ErrInvalidPool) ||
+		errors.Is(err, app.ErrInvalidDistance) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("invalid race result")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.RaceResultResponse(result)
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// (GET /users/{id}/personal-bests)
+func (s *SwimLogsServer) PersonalBests(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	pbs, err := s.app.PersonalBests(r.Context(), id)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("user not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.PersonalBestsResponse{PersonalBests: pbs}
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/oapi-codegen/runtime/types"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+// (POST /sets/{id}/results)
+func (s *SwimLogsServer) SubmitSetResult(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	req, err := readJSON[apidef.SubmitSetResultRequest](w, r)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.app.SubmitSetResult(r.Context(), id, req)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("set or user not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if errors.Is(err, app.ErrInvalidResult) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("invalid result")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.SetResultResponse(result)
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// (GET /sets/{id}/results)
+func (s *SwimLogsServer) SetResults(
+	w http.ResponseWriter,
+	r *http.Request,
+	id types.UUID,
+) {
+	results, err := s.app.SetResults(r.Context(), id)
+	if errors.Is(err, app.ErrNotFound) {
+		log.Warn().Err(err).Str("id", id.String()).Msg("set not found")
+		respondWithCode(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.SetResultsResponse{Results: results}
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -7,12 +7,14 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
 
 	"github.com/Nesquiko/swimlogs/apidef"
 	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/metrics"
 )
 
 const (
@@ -23,25 +25,51 @@ const (
 
 type SwimLogsServer struct {
 	app app.SwimLogsApp
+
+	// shuttingDown is set when the server stopped accepting new connections
+	// and is only draining in-flight requests
+	shuttingDown *atomic.Bool
 }
 
-func NewServerHandler(app app.SwimLogsApp, feOrigin string) http.Handler {
-	s := SwimLogsServer{app}
+func NewServerHandler(
+	app app.SwimLogsApp,
+	corsOpts Cors,
+	limits Limits,
+	adminToken string,
+	shuttingDown *atomic.Bool,
+) http.Handler {
+	s := SwimLogsServer{app, shuttingDown}
 
 	r := chi.NewRouter()
 	serverOpts := apidef.ChiServerOptions{
 		BaseRouter:  r,
-		Middlewares: publicMiddleware(feOrigin),
+		Middlewares: publicMiddleware(corsOpts, limits),
 	}
 
 	// group for handling OPTIONS requests
 	r.Group(func(r chi.Router) {
-		r.Use(cors(feOrigin))
-		r.Options(serverOpts.BaseURL+"/*", nil)
+		r.Use(cors(corsOpts))
+		r.Options(serverOpts.BaseURL+"/*", func(w http.ResponseWriter, r *http.Request) {
+			respondWithCode(w, http.StatusNoContent)
+		})
 	})
 
 	r.Group(func(r chi.Router) {
 		r.Get(serverOpts.BaseURL+"/monitoring/heartbeat", s.Heartbeat)
+		r.Get(serverOpts.BaseURL+"/monitoring/ready", s.Ready)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(cors(corsOpts))
+		r.Get(serverOpts.BaseURL+"/api/openapi.yaml", s.OpenAPISpec)
+		r.Get(serverOpts.BaseURL+"/api/docs", s.Docs)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(requireAdmin(adminToken))
+		r.Method(http.MethodGet, serverOpts.BaseURL+"/monitoring/metrics", metrics.Handler())
+		r.Get(serverOpts.BaseURL+"/admin/backup", s.Backup)
+		r.Post(serverOpts.BaseURL+"/admin/restore", s.Restore)
 	})
 
 	return apidef.HandlerWithOptions(&s, serverOpts)
@@ -73,9 +101,9 @@ func readJSON[T any](w http.ResponseWriter, r *http.Request) (T, error) {
 		var syntaxErr *json.SyntaxError
 		var unmarshalTypeErr *json.UnmarshalTypeError
 		var invalidUnmarshalErr *json.InvalidUnmarshalError
+		var maxBytesErr *http.MaxBytesError
 
 		invalidFieldPrefix := "json: unknown field "
-		largeBodyErrorStr := "http: request body too large"
 
 		switch {
 		case errors.As(err, &syntaxErr):
@@ -118,9 +146,9 @@ func readJSON[T any](w http.ResponseWriter, r *http.Request) (T, error) {
 			log.Debug().Msgf("body contains unknown key %s", fieldName)
 			return dst, fmt.Errorf("body contains unknown key %s", fieldName)
 
-		case err.Error() == largeBodyErrorStr:
+		case errors.As(err, &maxBytesErr):
 			log.Debug().Err(err).Msg("body is too large")
-			return dst, fmt.Errorf("body must not be larger than %d bytes", MaxBytes)
+			return dst, fmt.Errorf("body must not be larger than %d bytes", maxBytesErr.Limit)
 
 		case errors.As(err, &invalidUnmarshalErr):
 			log.Error().Err(err).Msg("invalid unmarshal target")
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+)
+
+// (POST /users)
+func (s *SwimLogsServer) CreateUser(w http.ResponseWriter, r *http.Request) {
+	req, err := readJSON[apidef.CreateUserRequest](w, r)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read request body")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	}
+
+	u, err := s.app.CreateUser(r.Context(), req)
+	if errors.Is(err, app.ErrInvalidUser) {
+		log.Warn().Err(err).Msg("invalid request")
+		respondWithCode(w, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, u)
+}
+
+// (GET /users)
+func (s *SwimLogsServer) Users(w http.ResponseWriter, r *http.Request) {
+	users, err := s.app.Users(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("internal server error")
+		respondWithCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	response := apidef.UsersResponse{Users: users}
+	respondWithJSON(w, http.StatusOK, response)
+}
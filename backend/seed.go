@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/Nesquiko/swimlogs/pkg/seed"
+)
+
+const seedUsage = `Usage: swimlogs seed [flags]
+
+Generates fake trainings and stores them in the database in one
+transaction, the same seed, count and dates always generate the same
+trainings. Trainings already stored by an earlier run are skipped. It
+refuses to run without -dev-database, confirming the database is only for
+development.
+
+Seed flags:
+  -dev-database confirms the database is a development database
+  -seed N       seed of the random generator (default 1)
+  -count N      how many trainings to generate (default 200)
+  -from DATE    first day of generated trainings, like 2024-01-31 (default
+                a year before today)
+  -to DATE      last day of generated trainings (default today)
+
+Days are in the timezone from -tz. Other flags are the same as the
+server's, run swimlogs -h to list them.
+`
+
+// seedCommand runs the seed subcommand with args after "seed" and returns
+// exit code.
+func seedCommand(args []string) int {
+	opts := seed.DefaultOptions(time.Now())
+	var from, to string
+	var devDatabase bool
+	cfg, code, ok := loadCommandConfig("swimlogs seed", args, seedUsage, func(fs *flag.FlagSet) {
+		fs.BoolVar(&devDatabase, "dev-database", false, "confirms the database is a development database")
+		fs.Int64Var(&opts.Seed, "seed", opts.Seed, "seed of the random generator")
+		fs.IntVar(&opts.Count, "count", opts.Count, "how many trainings to generate")
+		fs.StringVar(&from, "from", "", "first day of generated trainings")
+		fs.StringVar(&to, "to", "", "last day of generated trainings")
+	})
+	if !ok {
+		return code
+	}
+	if len(cfg.Args) > 0 {
+		fmt.Fprint(os.Stderr, seedUsage)
+		return 2
+	}
+	if !devDatabase {
+		fmt.Fprintf(os.Stderr, "refusing to seed without -dev-database\n\n%s", seedUsage)
+		return 2
+	}
+
+	loc, err := time.LoadLocation(cfg.TZ)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	opts.From, opts.To = opts.From.In(loc), opts.To.In(loc)
+	for _, d := range []struct {
+		value string
+		day   *time.Time
+	}{{from, &opts.From}, {to, &opts.To}} {
+		if d.value == "" {
+			continue
+		}
+		if *d.day, err = time.ParseInLocation(time.DateOnly, d.value, loc); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid date %q\n\n%s", d.value, seedUsage)
+			return 2
+		}
+	}
+
+	setupLogger(cfg, os.Stdout)
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		log.Error().Err(err).Str("driver", cfg.Database.Driver).Msg("failed to connect to database")
+		return 1
+	}
+	defer db.Close()
+
+	if cfg.Database.SkipMigrations {
+		log.Info().Msg("skipping migrations")
+	} else if err := db.MigrateUp(true); err != nil {
+		log.Error().Err(err).Msg("failed to migrate up")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	trainings, err := seed.Persist(ctx, db, opts)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to seed")
+		return 1
+	}
+
+	sets := 0
+	for _, t := range trainings {
+		sets += len(t.Sets)
+	}
+	log.Info().
+		Int64("seed", opts.Seed).
+		Str("from", opts.From.Format(time.DateOnly)).
+		Str("to", opts.To.Format(time.DateOnly)).
+		Int("trainings", len(trainings)).
+		Int("sets", sets).
+		Msg("seeded")
+	return 0
+}
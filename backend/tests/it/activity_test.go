@@ -0,0 +1,297 @@
+package it
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/fit"
+)
+
+var fitEpoch = time.Date(1989, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+type fitField struct {
+	num   byte
+	typ   byte
+	value uint32
+}
+
+type fitRecord struct {
+	num       uint16
+	bigEndian bool
+	fields    []fitField
+}
+
+// encodeFIT writes records as a FIT file, each with its own definition.
+func encodeFIT(records ...fitRecord) []byte {
+	var data []byte
+	for _, r := range records {
+		var order binary.AppendByteOrder = binary.LittleEndian
+		arch := byte(0)
+		if r.bigEndian {
+			order, arch = binary.BigEndian, 1
+		}
+
+		data = append(data, 0x40, 0, arch)
+		data = order.AppendUint16(data, r.num)
+		data = append(data, byte(len(r.fields)))
+		for _, f := range r.fields {
+			data = append(data, f.num, fitSize(f.typ), f.typ)
+		}
+
+		data = append(data, 0)
+		for _, f := range r.fields {
+			switch fitSize(f.typ) {
+			case 1:
+				data = append(data, byte(f.value))
+			case 2:
+				data = order.AppendUint16(data, uint16(f.value))
+			default:
+				data = order.AppendUint32(data, f.value)
+			}
+		}
+	}
+
+	file := []byte{14, 0x10, 0x5C, 0x08}
+	file = binary.LittleEndian.AppendUint32(file, uint32(len(data)))
+	file = append(file, ".FIT"...)
+	file = binary.LittleEndian.AppendUint16(file, fit.CRC(file))
+	file = append(file, data...)
+	return binary.LittleEndian.AppendUint16(file, fit.CRC(file))
+}
+
+func fitSize(typ byte) byte {
+	switch typ {
+	case 0x84:
+		return 2
+	case 0x86:
+		return 4
+	}
+	return 1
+}
+
+type fitLength struct {
+	stroke  byte
+	seconds uint32
+	idle    bool
+}
+
+// fitActivity is a pool swim activity of lengths, with session at the end
+// like watches write it.
+func fitActivity(start time.Time, subSport byte, poolCm uint32, unit byte, lengths []fitLength) []byte {
+	at := uint32(start.Sub(fitEpoch) / time.Second)
+	records := []fitRecord{{num: 0, fields: []fitField{{0, 0x00, 4}}}}
+	for _, l := range lengths {
+		lengthType, stroke := uint32(1), uint32(l.stroke)
+		if l.idle {
+			lengthType, stroke = 0, 0xFF
+		}
+		records = append(records, fitRecord{num: 101, fields: []fitField{
+			{2, 0x86, at},
+			{3, 0x86, l.seconds * 1000},
+			{7, 0x00, stroke},
+			{12, 0x00, lengthType},
+		}})
+		at += l.seconds
+	}
+
+	records = append(records, fitRecord{num: 18, bigEndian: true, fields: []fitField{
+		{2, 0x86, uint32(start.Sub(fitEpoch) / time.Second)},
+		{7, 0x86, (at - uint32(start.Sub(fitEpoch)/time.Second)) * 1000},
+		{5, 0x00, 5},
+		{6, 0x00, uint32(subSport)},
+		{44, 0x84, poolCm},
+		{46, 0x00, uint32(unit)},
+	}})
+	return encodeFIT(records...)
+}
+
+func repeatLengths(n int, l fitLength) []fitLength {
+	lengths := make([]fitLength, n)
+	for i := range lengths {
+		lengths[i] = l
+	}
+	return lengths
+}
+
+func importActivity(t *testing.T, file []byte, query string) *http.Response {
+	url := TH.ts.URL + "/trainings/import" + query
+	res, err := http.Post(url, "application/octet-stream", bytes.NewReader(file))
+	require.NoError(t, err)
+	return res
+}
+
+func importedTraining(t *testing.T, res *http.Response) apidef.Training {
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var detail apidef.TrainingDetail
+	err := json.NewDecoder(res.Body).Decode(&detail)
+	res.Body.Close()
+	require.NoError(t, err)
+	return trainingById(t, detail.Id)
+}
+
+func TestImportActivity_FIT(t *testing.T) {
+	const freestyle, backstroke, breaststroke, butterfly = 0, 1, 2, 3
+	start := time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC)
+
+	var lengths []fitLength
+	for i := 0; i < 4; i++ {
+		lengths = append(lengths, repeatLengths(2, fitLength{stroke: freestyle, seconds: 40})...)
+		lengths = append(lengths, fitLength{seconds: 20, idle: true})
+	}
+	lengths[len(lengths)-1].seconds = 60
+	lengths = append(lengths, repeatLengths(8, fitLength{stroke: breaststroke, seconds: 50})...)
+	lengths = append(lengths, fitLength{seconds: 30, idle: true})
+	for i := 0; i < 2; i++ {
+		lengths = append(lengths,
+			fitLength{stroke: backstroke, seconds: 45},
+			fitLength{stroke: butterfly, seconds: 45},
+			fitLength{stroke: backstroke, seconds: 45},
+			fitLength{stroke: butterfly, seconds: 45},
+			fitLength{seconds: 15, idle: true},
+		)
+	}
+	lengths[len(lengths)-1].seconds = 300
+
+	file := fitActivity(start, 17, 2500, 0, lengths)
+	training := importedTraining(t, importActivity(t, file, ""))
+
+	assert.True(t, start.Equal(training.Start))
+	assert.Equal(t, 26, training.DurationMin)
+	assert.Equal(t, apidef.Pool{Length: 25, Unit: apidef.Meters}, *training.Pool)
+	assert.Equal(t, 600, training.TotalDistance)
+
+	require.Len(t, training.Sets, 3)
+	expected := []struct {
+		repeat      int
+		distance    int
+		description string
+		rest        int
+	}{
+		{4, 50, "freestyle", 20},
+		{1, 200, "breaststroke", 30},
+		{2, 100, "mixed", 15},
+	}
+	for i, e := range expected {
+		s := training.Sets[i]
+		assert.Equal(t, i, s.SetOrder)
+		assert.Equal(t, e.repeat, s.Repeat, i)
+		assert.Equal(t, e.distance, s.DistanceMeters, i)
+		assert.Equal(t, asPtr(e.description), s.Description, i)
+		assert.Equal(t, apidef.Pause, s.StartType, i)
+		assert.Equal(t, asPtr(e.rest), s.StartSeconds, i)
+	}
+}
+
+func TestImportActivity_FITYards(t *testing.T) {
+	lengths := repeatLengths(4, fitLength{seconds: 20})
+	file := fitActivity(time.Now().Truncate(time.Second), 17, 2286, 1, lengths)
+
+	// the pool of the file wins over query
+	training := importedTraining(t, importActivity(t, file, "?poolLength=50"))
+	assert.Equal(t, apidef.Pool{Length: 25, Unit: apidef.Yards}, *training.Pool)
+	require.Len(t, training.Sets, 1)
+	assert.Equal(t, 1, training.Sets[0].Repeat)
+	assert.Equal(t, asPtr(100), training.Sets[0].DistanceYards)
+	assert.Equal(t, apidef.None, training.Sets[0].StartType)
+	assert.Equal(t, 1, training.DurationMin)
+}
+
+const tcxActivity = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Other">
+      <Id>2024-03-06T06:30:00.000Z</Id>
+      <Lap StartTime="2024-03-06T06:30:00.000Z">
+        <TotalTimeSeconds>360.0</TotalTimeSeconds>
+        <DistanceMeters>400.0</DistanceMeters>
+        <Intensity>Active</Intensity>
+      </Lap>
+      <Lap StartTime="2024-03-06T06:36:00.000Z">
+        <TotalTimeSeconds>30.0</TotalTimeSeconds>
+        <DistanceMeters>0.0</DistanceMeters>
+        <Intensity>Resting</Intensity>
+      </Lap>
+      <Lap StartTime="2024-03-06T06:36:30.000Z">
+        <TotalTimeSeconds>100.0</TotalTimeSeconds>
+        <DistanceMeters>150.0</DistanceMeters>
+      </Lap>
+      <Lap StartTime="2024-03-06T06:38:30.000Z">
+        <TotalTimeSeconds>100.0</TotalTimeSeconds>
+        <DistanceMeters>150.0</DistanceMeters>
+      </Lap>
+      <Lap StartTime="2024-03-06T06:40:30.000Z">
+        <TotalTimeSeconds>99.6</TotalTimeSeconds>
+        <DistanceMeters>150.0</DistanceMeters>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestImportActivity_TCX(t *testing.T) {
+	training := importedTraining(t, importActivity(t, []byte(tcxActivity), "?poolLength=50"))
+
+	assert.True(t, time.Date(2024, 3, 6, 6, 30, 0, 0, time.UTC).Equal(training.Start))
+	assert.Equal(t, 12, training.DurationMin)
+	assert.Equal(t, apidef.Pool{Length: 50, Unit: apidef.Meters}, *training.Pool)
+	assert.Equal(t, 850, training.TotalDistance)
+
+	require.Len(t, training.Sets, 2)
+	assert.Equal(t, 400, training.Sets[0].DistanceMeters)
+	assert.Equal(t, asPtr(30), training.Sets[0].StartSeconds)
+	assert.Nil(t, training.Sets[0].Description)
+	assert.Equal(t, 3, training.Sets[1].Repeat)
+	assert.Equal(t, 150, training.Sets[1].DistanceMeters)
+	assert.Equal(t, apidef.Pause, training.Sets[1].StartType)
+	assert.Equal(t, asPtr(20), training.Sets[1].StartSeconds)
+}
+
+func TestImportActivity_Invalid(t *testing.T) {
+	start := time.Now()
+	lengths := repeatLengths(4, fitLength{seconds: 20})
+	corrupted := fitActivity(start, 17, 2500, 0, lengths)
+	corrupted[20] ^= 0xFF
+
+	cases := []struct {
+		name   string
+		file   []byte
+		detail string
+	}{
+		{"text", []byte("8x100 @1:40"), "neither FIT nor TCX"},
+		{"open water", fitActivity(start, 18, 2500, 0, lengths), "isn't pool swimming"},
+		{"no lengths", fitActivity(start, 17, 2500, 0, nil), "no swum lengths"},
+		{"corrupted", corrupted, "CRC"},
+		{"truncated", fitActivity(start, 17, 2500, 0, lengths)[:40], "truncated"},
+		{"no laps", []byte(strings.Split(tcxActivity, "<Lap ")[0] + "</Activity></Activities></TrainingCenterDatabase>"), "no laps"},
+		{"invalid xml", []byte("<TrainingCenterDatabase>"), "EOF"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := importActivity(t, c.file, "")
+			require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			var detail apidef.ErrorDetail
+			err := json.NewDecoder(res.Body).Decode(&detail)
+			res.Body.Close()
+			require.NoError(t, err)
+			assert.Equal(t, "invalid_activity", detail.Code)
+			assert.Contains(t, detail.Detail, c.detail)
+		})
+	}
+
+	res := importActivity(t, nil, "")
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "empty file")
+
+	res = importActivity(t, []byte(tcxActivity), "?poolLength=0")
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
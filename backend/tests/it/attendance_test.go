@@ -0,0 +1,192 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestMarkAttendance_PartialWithoutMeters(t *testing.T) {
+	user := createUser(t, "swimmer")
+	id := createTraining(t, nil).Id
+
+	res := markAttendance(t, id, []apidef.Attendance{
+		{UserId: user.Id, Status: apidef.Partial},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestMarkAttendance_UserNotFound(t *testing.T) {
+	id := createTraining(t, nil).Id
+
+	res := markAttendance(t, id, []apidef.Attendance{
+		{UserId: uuid.New(), Status: apidef.Present},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestMarkAttendance(t *testing.T) {
+	first := createUser(t, "first swimmer")
+	second := createUser(t, "second swimmer")
+	id := createTraining(t, nil).Id
+
+	res := markAttendance(t, id, []apidef.Attendance{
+		{UserId: first.Id, Status: apidef.Absent},
+		{UserId: second.Id, Status: apidef.Present},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = markAttendance(t, id, []apidef.Attendance{
+		{UserId: first.Id, Status: apidef.Partial, MetersCompleted: asPtr(50)},
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var attendance apidef.AttendanceResponse
+	err := json.NewDecoder(res.Body).Decode(&attendance)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	assert.Equal(t, []apidef.Attendance{
+		{UserId: first.Id, Status: apidef.Partial, MetersCompleted: asPtr(50)},
+		{UserId: second.Id, Status: apidef.Present},
+	}, attendance.Attendance)
+}
+
+func TestAttendanceReport(t *testing.T) {
+	attending := createUser(t, "attending swimmer")
+	excused := createUser(t, "excused swimmer")
+
+	day := time.Date(2001, time.March, 5, 18, 0, 0, 0, time.Local)
+	training := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Sets: []apidef.NewTrainingSet{
+			{DistanceMeters: 400, Repeat: 1, SetOrder: 0, StartType: apidef.None},
+		},
+		Start: day,
+	}
+	firstId := createTraining(t, &training).Id
+	training.Start = day.AddDate(0, 0, 1)
+	secondId := createTraining(t, &training).Id
+
+	res := markAttendance(t, firstId, []apidef.Attendance{
+		{UserId: attending.Id, Status: apidef.Present},
+		{UserId: excused.Id, Status: apidef.Excused},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res = markAttendance(t, secondId, []apidef.Attendance{
+		{UserId: attending.Id, Status: apidef.Partial, MetersCompleted: asPtr(200)},
+		{UserId: excused.Id, Status: apidef.Absent},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	url := fmt.Sprintf("%s/attendance/report?from=2001-03-01&to=2001-03-31", TH.ts.URL)
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var report apidef.AttendanceReportResponse
+	err = json.NewDecoder(res.Body).Decode(&report)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	summaries := make(map[uuid.UUID]apidef.AttendanceSummary)
+	for _, s := range report.Summaries {
+		summaries[s.UserId] = s
+	}
+
+	assert := assert.New(t)
+	assert.Equal(2, report.Trainings)
+
+	a := summaries[attending.Id]
+	assert.Equal(1, a.Present)
+	assert.Equal(1, a.Partial)
+	assert.Equal(1.0, a.AttendanceRate)
+	assert.Equal(600, a.CompletedDistance)
+
+	e := summaries[excused.Id]
+	assert.Equal(1, e.Excused)
+	assert.Equal(1, e.Absent)
+	assert.Equal(0.0, e.AttendanceRate)
+	assert.Equal(0, e.CompletedDistance)
+}
+
+func markAttendance(t *testing.T, trainingId uuid.UUID, attendance []apidef.Attendance) *http.Response {
+	req, err := json.Marshal(apidef.MarkAttendanceRequest{Attendance: attendance})
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/trainings/" + trainingId.String() + "/attendance"
+	request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", server.ApplicationJSON)
+
+	client := http.Client{}
+	res, err := client.Do(request)
+	require.NoError(t, err)
+	return res
+}
+
+func TestAttendanceReport_Timezone(t *testing.T) {
+	// 00:30 on the first of May in Bratislava, still April in UTC
+	start := time.Date(2002, time.April, 30, 22, 30, 0, 0, time.UTC)
+	training := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Sets: []apidef.NewTrainingSet{
+			{DistanceMeters: 400, Repeat: 1, SetOrder: 0, StartType: apidef.None},
+		},
+		Start: start,
+	}
+	createTraining(t, &training)
+
+	tests := []struct {
+		day  string
+		tz   string
+		want int
+	}{
+		{"2002-05-01", "Europe/Bratislava", 1},
+		{"2002-04-30", "Europe/Bratislava", 0},
+		{"2002-05-01", "UTC", 0},
+		{"2002-04-30", "UTC", 1},
+		{"2002-04-30", "America/New_York", 1},
+	}
+	for _, test := range tests {
+		url := fmt.Sprintf(
+			"%s/attendance/report?from=%s&to=%s&tz=%s",
+			TH.ts.URL,
+			test.day,
+			test.day,
+			test.tz,
+		)
+		res, err := http.Get(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var report apidef.AttendanceReportResponse
+		err = json.NewDecoder(res.Body).Decode(&report)
+		res.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, test.want, report.Trainings, "%s in %s", test.day, test.tz)
+	}
+}
+
+func TestAttendanceReport_InvalidTimezone(t *testing.T) {
+	url := fmt.Sprintf("%s/attendance/report?from=2002-05-01&to=2002-05-01&tz=Mars/Olympus", TH.ts.URL)
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
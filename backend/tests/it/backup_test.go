@@ -0,0 +1,200 @@
+package it
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/backup"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestBackup_Unauthorized(t *testing.T) {
+	for _, auth := range []string{"", "Bearer wrong-token", adminToken} {
+		req, err := http.NewRequest(http.MethodGet, TH.ts.URL+"/admin/backup", nil)
+		require.NoError(t, err)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode, auth)
+		assert.NotEmpty(t, res.Header.Get("WWW-Authenticate"))
+	}
+}
+
+func TestBackup_Disabled(t *testing.T) {
+	h := server.NewServerHandler(
+		app.New(TH.repo, 0, nil),
+		server.DefaultCors,
+		unlimited,
+		"",
+		TH.shuttingDown,
+	)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	res := adminRequest(t, ts, http.MethodGet, "/admin/backup", nil)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestBackupRestore(t *testing.T) {
+	TH.CleanTrainings(t)
+	TH.CleanUsers(t)
+	user := createUser(t, "swimmer")
+	training := createTraining(t, nil)
+
+	res := adminRequest(t, TH.ts, http.MethodGet, "/admin/backup", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, server.ApplicationJSON, res.Header.Get("Content-Type"))
+	assert.Contains(t, res.Header.Get("Content-Disposition"), "attachment")
+	archive, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	var header struct {
+		Format   string          `json:"format"`
+		Version  int             `json:"version"`
+		Contents backup.Contents `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(archive, &header))
+	assert.Equal(t, backup.Format, header.Format)
+	assert.Equal(t, backup.Version, header.Version)
+	assert.Equal(t, backup.Contents{Users: 1, Trainings: 1, Sets: 1}, header.Contents)
+
+	TH.CleanTrainings(t)
+	extra := createUser(t, "extra")
+
+	res = adminRequest(t, TH.ts, http.MethodPost, "/admin/restore", archive)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var contents backup.Contents
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&contents))
+	res.Body.Close()
+	assert.Equal(t, header.Contents, contents)
+
+	restored := trainingById(t, training.Id)
+	assert.Len(t, restored.Sets, 1)
+	users, err := TH.repo.Users(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, users, 2, "merge keeps stored users")
+
+	res = adminRequest(t, TH.ts, http.MethodPost, "/admin/restore?mode=replace", archive)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	users, err = TH.repo.Users(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 1, "replace deletes stored users")
+	assert.Equal(t, user.Id, users[0].Id)
+	assert.NotEqual(t, extra.Id, users[0].Id)
+}
+
+func TestRestore_MergeEditedTraining(t *testing.T) {
+	TH.CleanTrainings(t)
+	training := trainingById(t, createTraining(t, nil).Id)
+
+	res := adminRequest(t, TH.ts, http.MethodGet, "/admin/backup", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	archive, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	edited := training
+	edited.Sets = []apidef.TrainingSet{
+		{Id: uuid.New(), SetOrder: 0, Repeat: 4, DistanceMeters: 50, StartType: apidef.None},
+		training.Sets[0],
+	}
+	edited.Sets[1].SetOrder = 1
+	body, err := json.Marshal(edited)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, TH.ts.URL+"/trainings/"+training.Id.String(), bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", server.ApplicationJSON)
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = adminRequest(t, TH.ts, http.MethodPost, "/admin/restore", archive)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var count, orders int
+	err = TH.db.QueryRow(
+		"select count(*), count(distinct set_order) from sets where training_id = $1",
+		training.Id,
+	).Scan(&count, &orders)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "set added after the backup is removed")
+	assert.Equal(t, 1, orders)
+
+	restored := trainingById(t, training.Id)
+	assert.Equal(t, training.Sets, restored.Sets)
+	assert.Equal(t, training.TotalDistance, restored.TotalDistance)
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	valid := `{"format":"swimlogs-backup","version":1,"createdAt":"2024-01-01T00:00:00Z",` +
+		`"contents":{"users":0,"trainings":0,"sets":0,"setResults":0,"raceResults":0,"cssTests":0,"attendance":0},` +
+		`"users":[],"trainings":[],"setResults":[],"raceResults":[],"cssTests":[],"attendance":[]}`
+
+	cases := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"unknown mode", "/admin/restore?mode=append", valid},
+		{"not json", "/admin/restore", "not a backup"},
+		{"unknown format", "/admin/restore", strings.Replace(valid, "swimlogs-backup", "other", 1)},
+		{"newer version", "/admin/restore", strings.Replace(valid, `"version":1`, `"version":99`, 1)},
+		{"contents mismatch", "/admin/restore", strings.Replace(valid, `"users":0`, `"users":1`, 1)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := adminRequest(t, TH.ts, http.MethodPost, c.path, []byte(c.body))
+			res.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		})
+	}
+}
+
+func TestRestore_TooLarge(t *testing.T) {
+	// MultiReader hides the length, so the limit applies while reading
+	archive := io.MultiReader(
+		strings.NewReader(`{"format":"`),
+		bytes.NewReader(bytes.Repeat([]byte("a"), server.MaxRestoreBytes)),
+	)
+	req, err := http.NewRequest(http.MethodPost, TH.ts.URL+"/admin/restore", archive)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
+
+func adminRequest(t *testing.T, ts *httptest.Server, method, path string, body []byte) *http.Response {
+	req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return res
+}
@@ -0,0 +1,142 @@
+package it
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/cli"
+)
+
+const cliTraining = `
+start: 2024-03-04T18:00:00Z
+durationMin: 60
+pool:
+  length: 25
+  unit: meters
+sets:
+  - repeat: 1
+    distanceMeters: 400
+    startType: None
+    description: warm up
+  - repeat: 8
+    distanceMeters: 100
+    startType: Interval
+    startSeconds: 100
+    equipment: [Fins]
+    group: sprint
+`
+
+type cliResult struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runCli(t *testing.T, env map[string]string, stdin string, args ...string) cliResult {
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(context.Background(), args, cli.Env{
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		LookupEnv: func(key string) (string, bool) {
+			if key == cli.ServerEnvVar {
+				return TH.ts.URL, true
+			}
+			v, ok := env[key]
+			return v, ok
+		},
+	})
+	return cliResult{code, stdout.String(), stderr.String()}
+}
+
+func TestCli(t *testing.T) {
+	TH.CleanTrainings(t)
+
+	res := runCli(t, nil, cliTraining, "-output", "json", "create", "-")
+	require.Equal(t, 0, res.code, res.stderr)
+	var created apidef.TrainingDetail
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &created))
+	assert.Equal(t, 1200, created.TotalDistance)
+	id := created.Id.String()
+
+	res = runCli(t, nil, "", "details")
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Contains(t, res.stdout, id)
+	assert.Contains(t, res.stdout, "1200m")
+	assert.Contains(t, res.stdout, "page 1 of 1, 1 trainings")
+
+	res = runCli(t, nil, "", "-tz", "Europe/Bratislava", "show", id)
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "Mon 2024-03-04 19:00")
+	assert.Contains(t, res.stdout, "8x100m")
+	assert.Contains(t, res.stdout, "@1:40")
+	assert.Contains(t, res.stdout, "sprint")
+
+	// the editor doubles duration and lengthens the warm up
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\n" +
+		"sed -i -e 's/durationMin: 60/durationMin: 120/' -e 's/distanceMeters: 400/distanceMeters: 600/' \"$1\"\n"
+	require.NoError(t, os.WriteFile(editor, []byte(script), 0o700))
+	res = runCli(t, map[string]string{cli.EditorEnvVar: editor}, "", "edit", id)
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "edited training "+id)
+
+	edited := trainingById(t, created.Id)
+	assert.Equal(t, 120, edited.DurationMin)
+	require.Len(t, edited.Sets, 2)
+	assert.Equal(t, 600, edited.Sets[0].TotalDistance)
+	assert.Equal(t, 1400, edited.TotalDistance)
+
+	res = runCli(t, map[string]string{cli.EditorEnvVar: "true"}, "", "edit", id)
+	assert.Equal(t, 0, res.code, res.stderr)
+	assert.Contains(t, res.stderr, "not changed")
+
+	res = runCli(t, nil, "", "delete", id)
+	require.Equal(t, 0, res.code, res.stderr)
+	res = runCli(t, nil, "", "show", id)
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "404")
+}
+
+func TestCli_Week(t *testing.T) {
+	TH.CleanTrainings(t)
+	training := createTraining(t, nil)
+
+	res := runCli(t, nil, "", "-output", "json", "-tz", "UTC", "week")
+	require.Equal(t, 0, res.code, res.stderr)
+	var week apidef.TrainingDetailsCurrentWeekResponse
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &week))
+	require.Len(t, week.Details, 1)
+	assert.Equal(t, training.Id, week.Details[0].Id)
+}
+
+func TestCli_InvalidUsage(t *testing.T) {
+	cases := [][]string{
+		{},
+		{"bogus"},
+		{"show"},
+		{"show", "not-an-id"},
+		{"details", "-size", "0"},
+		{"-output", "xml", "details"},
+	}
+
+	for _, args := range cases {
+		res := runCli(t, nil, "", args...)
+		assert.Equal(t, 2, res.code, args)
+	}
+}
+
+func TestCli_InvalidFile(t *testing.T) {
+	res := runCli(t, nil, "durationMinutes: 60\n", "create", "-")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "unknown field")
+}
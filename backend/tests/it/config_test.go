@@ -0,0 +1,117 @@
+package it
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/pkg/config"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestConfig_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+server:
+  port: "1111"
+  readTimeout: 1s
+  writeTimeout: 1s
+database:
+  driver: sqlite
+  path: file.db
+limits:
+  operations:
+    Users:
+      rate: 1
+      burst: 1
+      maxBodyBytes: 10
+`), 0o600)
+	require.NoError(t, err)
+
+	cfg, err := config.Load(
+		[]string{"-config", path, "-write-timeout", "3s"},
+		env(map[string]string{
+			config.AppPortEnvVar:         "2222",
+			config.AppWriteTimeoutEnvVar: "2s",
+			config.TrustedProxiesEnvVar:  "10.0.0.1, 172.16.0.0/12",
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Second, cfg.Server.ReadTimeout, "file overrides default")
+	assert.Equal(t, "2222", cfg.Server.Port, "env overrides file")
+	assert.Equal(t, 3*time.Second, cfg.Server.WriteTimeout, "flag overrides env")
+	assert.Equal(t, 120*time.Second, cfg.Server.IdleTimeout, "default is kept")
+	assert.Equal(t, "file.db", cfg.Database.Path)
+
+	limits := cfg.Limits.ServerLimits()
+	assert.Equal(t, int64(10), limits.Operations["Users"].MaxBytes)
+	assert.Contains(t, limits.Operations, "CreateUser", "default operation limits are kept")
+	assert.Equal(
+		t,
+		[]netip.Prefix{netip.MustParsePrefix("10.0.0.1/32"), netip.MustParsePrefix("172.16.0.0/12")},
+		limits.TrustedProxies,
+	)
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	_, err := config.Load(
+		[]string{"-port", "http", "-tz", "Nowhere/City", "-db-url", "mysql://db", "-trusted-proxies", "nginx"},
+		env(nil),
+	)
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "port")
+	assert.ErrorContains(t, err, "timezone")
+	assert.ErrorContains(t, err, "database url")
+	assert.ErrorContains(t, err, "trusted proxy")
+
+	_, err = config.Load([]string{"-json-logs=maybe"}, env(nil))
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+}
+
+func TestConfig_UnknownOperationLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+limits:
+  operations:
+    createUser:
+      rate: 1
+      burst: 1
+      maxBodyBytes: 10
+    DeleteEverything:
+      rate: 1
+      burst: 1
+      maxBodyBytes: 10
+`), 0o600)
+	require.NoError(t, err)
+
+	_, err = config.Load([]string{"-config", path}, env(nil))
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+	assert.ErrorContains(t, err, `unknown operation "createUser" in limits, did you mean "CreateUser"`)
+	assert.ErrorContains(t, err, `unknown operation "DeleteEverything"`)
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg, err := config.Load(
+		[]string{"-print-config", "-db-url", "postgres://swimlogs:secret@db:5432/swimlogs"},
+		env(map[string]string{config.DbPassEnvVar: "secret"}),
+	)
+	require.NoError(t, err)
+	require.True(t, cfg.PrintConfig)
+
+	b, err := cfg.Redacted().Yaml()
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+	assert.Contains(t, string(b), "postgres://swimlogs:REDACTED@db:5432/swimlogs")
+}
@@ -0,0 +1,113 @@
+package it
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func corsServer(t *testing.T, cors server.Cors) *httptest.Server {
+	h := server.NewServerHandler(app.New(TH.repo, 5*time.Second, nil), cors, unlimited, "", &atomic.Bool{})
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func corsRequest(
+	t *testing.T,
+	ts *httptest.Server,
+	method, path, origin string,
+	headers map[string]string,
+) *http.Response {
+	req, err := http.NewRequest(method, ts.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", origin)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	return res
+}
+
+func preflight(t *testing.T, ts *httptest.Server, origin, method, headers string) *http.Response {
+	return corsRequest(t, ts, http.MethodOptions, "/trainings", origin, map[string]string{
+		"Access-Control-Request-Method":  method,
+		"Access-Control-Request-Headers": headers,
+	})
+}
+
+func TestCors_AllowedOrigins(t *testing.T) {
+	cors := server.DefaultCors
+	cors.Origins = []string{"https://www.swimlogs.com", "http://localhost:3000"}
+	ts := corsServer(t, cors)
+
+	for _, origin := range cors.Origins {
+		res := corsRequest(t, ts, http.MethodGet, "/users", origin, nil)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, origin, res.Header.Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, res.Header.Values("Vary"), "Origin")
+		assert.Empty(t, res.Header.Get("Access-Control-Allow-Credentials"))
+	}
+
+	res := corsRequest(t, ts, http.MethodGet, "/users", "https://evil.example.com", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, res.Header.Values("Vary"), "Origin")
+}
+
+func TestCors_Preflight(t *testing.T) {
+	cors := server.DefaultCors
+	cors.Origins = []string{"https://www.swimlogs.com"}
+	ts := corsServer(t, cors)
+
+	res := preflight(t, ts, "https://www.swimlogs.com", http.MethodPost, "content-type, authorization")
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Equal(t, "https://www.swimlogs.com", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", res.Header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, If-Match", res.Header.Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", res.Header.Get("Access-Control-Max-Age"))
+	assert.Subset(
+		t,
+		res.Header.Values("Vary"),
+		[]string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+	)
+
+	res = preflight(t, ts, "https://www.swimlogs.com", http.MethodPatch, "")
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Methods"))
+
+	res = preflight(t, ts, "https://www.swimlogs.com", http.MethodPut, "X-Custom")
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Headers"))
+
+	res = preflight(t, ts, "https://evil.example.com", http.MethodPost, "")
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Methods"))
+}
+
+func TestCors_AnyOrigin(t *testing.T) {
+	cors := server.DefaultCors
+	cors.Origins = []string{server.AnyOrigin}
+	ts := corsServer(t, cors)
+
+	res := corsRequest(t, ts, http.MethodGet, "/users", "http://localhost:5173", nil)
+	assert.Equal(t, "*", res.Header.Get("Access-Control-Allow-Origin"))
+
+	cors.AllowCredentials = true
+	ts = corsServer(t, cors)
+
+	res = corsRequest(t, ts, http.MethodGet, "/users", "http://localhost:5173", nil)
+	assert.Equal(t, "http://localhost:5173", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", res.Header.Get("Access-Control-Allow-Credentials"))
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Nesquiko/swimlogs/apidef"
-	"github.com/Nesquiko/swimlogs/pkg/data"
 	"github.com/Nesquiko/swimlogs/pkg/server"
 )
 
@@ -63,12 +62,10 @@ func TestCreateTraining_ResponseMatches(t *testing.T) {
 		trainingCount int
 		setCount      int
 	}{}
-	err = data.SqlWithResult(
-		TH.pool,
+	err = TH.db.QueryRow(
 		"select (select count(*) from trainings where id = $1), (select count(*) from sets where training_id = $1)",
-		[]any{trainingDetail.Id},
-		[]any{&result.trainingCount, &result.setCount},
-	)
+		trainingDetail.Id,
+	).Scan(&result.trainingCount, &result.setCount)
 	require.NoError(t, err)
 	assert.Equal(t, 1, result.trainingCount)
 	assert.Equal(t, 2, result.setCount)
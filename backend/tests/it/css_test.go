@@ -0,0 +1,115 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestCreateCssTest_400FasterThan200(t *testing.T) {
+	user := createUser(t, "swimmer")
+
+	res := createCssTest(t, user.Id, 150_000, 155_000)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestCreateCssTest(t *testing.T) {
+	user := createUser(t, "swimmer")
+
+	res := createCssTest(t, user.Id, 330_000, 155_000)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var cssTest apidef.CssTest
+	err := json.NewDecoder(res.Body).Decode(&cssTest)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	assert.Equal(t, 87_500, cssTest.Pace100Ms)
+
+	url := TH.ts.URL + "/users/" + user.Id.String() + "/css-tests"
+	res, err = http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var tests apidef.CssTestsResponse
+	err = json.NewDecoder(res.Body).Decode(&tests)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	require.Len(t, tests.Tests, 1)
+	assert.Equal(t, cssTest, tests.Tests[0])
+}
+
+func TestIntervalSuggestions_NoCssTest(t *testing.T) {
+	user := createUser(t, "swimmer")
+	id := createTraining(t, &eightHundredsTraining).Id
+
+	res := intervalSuggestions(t, id, user.Id)
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestIntervalSuggestions(t *testing.T) {
+	user := createUser(t, "swimmer")
+	res := createCssTest(t, user.Id, 330_000, 155_000)
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	training := trainingById(t, createTraining(t, &eightHundredsTraining).Id)
+
+	res = intervalSuggestions(t, training.Id, user.Id)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var suggestions apidef.IntervalSuggestionsResponse
+	err := json.NewDecoder(res.Body).Decode(&suggestions)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	require.Len(t, suggestions.Sets, 1)
+	set := suggestions.Sets[0]
+	assert.Equal(t, training.Sets[0].Id, set.SetId)
+
+	intervals := make(map[apidef.IntensityEnum]int)
+	for _, i := range set.Intervals {
+		intervals[i.Intensity] = i.StartSeconds
+	}
+	assert.Equal(t, 105, intervals[apidef.Threshold])
+	assert.Equal(t, 115, intervals[apidef.Speed])
+}
+
+func createCssTest(t *testing.T, userId uuid.UUID, time400Ms, time200Ms int) *http.Response {
+	req, err := json.Marshal(apidef.NewCssTest{
+		Time400Ms: time400Ms,
+		Time200Ms: time200Ms,
+		Date:      types.Date{Time: time.Now()},
+	})
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/users/" + userId.String() + "/css-tests"
+	res, err := http.Post(url, server.ApplicationJSON, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	return res
+}
+
+func intervalSuggestions(t *testing.T, trainingId, userId uuid.UUID) *http.Response {
+	url := fmt.Sprintf(
+		"%s/trainings/%s/interval-suggestions?userId=%s",
+		TH.ts.URL,
+		trainingId,
+		userId,
+	)
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	return res
+}
@@ -7,8 +7,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
-	"github.com/Nesquiko/swimlogs/pkg/data"
 )
 
 func TestDeleteTraining_NotFound(t *testing.T) {
@@ -38,12 +36,10 @@ func TestDeleteTraining(t *testing.T) {
 		trainingCount int
 		setCount      int
 	}{}
-	err = data.SqlWithResult(
-		TH.pool,
+	err = TH.db.QueryRow(
 		"select (select count(*) from trainings where id = $1), (select count(*) from sets where training_id = $1)",
-		[]any{tId},
-		[]any{&result.trainingCount, &result.setCount},
-	)
+		tId,
+	).Scan(&result.trainingCount, &result.setCount)
 	require.NoError(t, err)
 	assert.Zero(t, result.trainingCount)
 	assert.Zero(t, result.setCount)
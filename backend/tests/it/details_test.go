@@ -110,3 +110,26 @@ func TestTrainingDetails(t *testing.T) {
 
 	assert.Len(t, details.Details, trainingsCount)
 }
+
+func TestTrainingDetailsCurrentWeek_Timezone(t *testing.T) {
+	TH.CleanTrainings(t)
+	created := createTraining(t, nil)
+
+	for _, tz := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		res, err := http.Get(TH.ts.URL + "/trainings/details/current-week?tz=" + tz)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var details apidef.TrainingDetailsCurrentWeekResponse
+		err = json.NewDecoder(res.Body).Decode(&details)
+		res.Body.Close()
+		require.NoError(t, err)
+		require.Len(t, details.Details, 1, tz)
+		assert.Equal(t, created.Id, details.Details[0].Id, tz)
+	}
+
+	res, err := http.Get(TH.ts.URL + "/trainings/details/current-week?tz=Mars/Olympus")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
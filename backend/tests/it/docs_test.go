@@ -0,0 +1,38 @@
+package it
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	res, err := http.Get(TH.ts.URL + "/api/openapi.yaml")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/yaml", res.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	spec, err := openapi3.NewLoader().LoadFromData(body)
+	require.NoError(t, err)
+	assert.Equal(t, "SwimLogs API", spec.Info.Title)
+	assert.NotNil(t, spec.Paths.Find("/trainings"))
+}
+
+func TestDocs(t *testing.T) {
+	res, err := http.Get(TH.ts.URL + "/api/docs")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, res.Header.Get("Content-Type"), "text/html")
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `url: "openapi.yaml"`)
+}
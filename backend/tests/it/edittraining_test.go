@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +13,6 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Nesquiko/swimlogs/apidef"
-	"github.com/Nesquiko/swimlogs/pkg/data"
 	"github.com/Nesquiko/swimlogs/pkg/server"
 )
 
@@ -118,77 +118,67 @@ func TestEditTraining(t *testing.T) {
 	assert.Equal(exptectedTraining.Start.Minute(), response.Start.Minute())
 
 	var count int
-	err = data.SqlWithResult(
-		TH.pool,
+	err = TH.db.QueryRow(
 		"select count(*) from sets where training_id = $1",
-		[]any{exptectedTraining.Id},
-		[]any{&count},
-	)
+		exptectedTraining.Id,
+	).Scan(&count)
 	require.NoError(t, err)
 
 	assert.Equal(3, count)
 
-	result := struct {
-		repeat         int
-		distanceMeters int
-		totalDistance  int
-		equipment      []string
-		group          *string
-	}{}
-	err = data.SqlWithResult(
-		TH.pool,
-		"select repeat, distance_meters, equipment, total_distance, \"group\" from sets where id = $1",
-		[]any{exptectedTraining.Sets[0].Id},
-		[]any{
-			&result.repeat,
-			&result.distanceMeters,
-			&result.equipment,
-			&result.totalDistance,
-			&result.group,
-		},
+	set := storedSet(t, "id = $1", exptectedTraining.Sets[0].Id)
+	assert.Equal(2, set.repeat)
+	assert.Equal(800, set.distanceMeters)
+	assert.Equal(1600, set.totalDistance)
+	assert.Equal([]string{string(apidef.Board)}, set.equipment)
+	require.NotNil(t, set.group)
+	assert.Equal(string(apidef.Long), *set.group)
+
+	set = storedSet(t, "id = $1", exptectedTraining.Sets[1].Id)
+	assert.Equal(4, set.repeat)
+	assert.Equal(200, set.distanceMeters)
+	assert.Equal(800, set.totalDistance)
+	assert.Nil(set.equipment)
+
+	// I don't have the Id of the newly created set
+	set = storedSet(
+		t,
+		"training_id = $1 and id not in ($2, $3)",
+		exptectedTraining.Id,
+		exptectedTraining.Sets[0].Id,
+		exptectedTraining.Sets[1].Id,
 	)
-	require.NoError(t, err)
+	assert.Equal(5, set.repeat)
+	assert.Equal(50, set.distanceMeters)
+	assert.Equal(250, set.totalDistance)
+	assert.Equal([]string{string(apidef.Monofin), string(apidef.Snorkel)}, set.equipment)
+}
 
-	assert.Equal(2, result.repeat)
-	assert.Equal(800, result.distanceMeters)
-	assert.Equal(1600, result.totalDistance)
-	assert.Equal(string(apidef.Board), result.equipment[0])
-	assert.Equal(apidef.Long, apidef.GroupEnum(*result.group))
-
-	err = data.SqlWithResult(
-		TH.pool,
-		"select repeat, distance_meters, equipment, total_distance, \"group\" from sets where id = $1",
-		[]any{exptectedTraining.Sets[1].Id},
-		[]any{
-			&result.repeat,
-			&result.distanceMeters,
-			&result.equipment,
-			&result.totalDistance,
-			&result.group,
-		},
-	)
-	require.NoError(t, err)
+type setRow struct {
+	repeat         int
+	distanceMeters int
+	totalDistance  int
+	equipment      []string
+	group          *string
+}
 
-	assert.Equal(4, result.repeat)
-	assert.Equal(200, result.distanceMeters)
-	assert.Equal(800, result.totalDistance)
-	assert.Nil(result.equipment)
-
-	err = data.SqlWithResult(
-		TH.pool,
-		"select repeat, distance_meters, equipment, total_distance from sets where id != $1 and id != $2 and training_id = $3",
-		[]any{
-			exptectedTraining.Sets[0].Id,
-			exptectedTraining.Sets[1].Id,
-			exptectedTraining.Id,
-		}, // I don't have the Id of the newly created set
-		[]any{&result.repeat, &result.distanceMeters, &result.equipment, &result.totalDistance},
-	)
+// storedSet reads the only set matching where straight from the sets table.
+// Equipment is a Postgres array or a sqlite JSON array, so it is read as
+// text of either.
+func storedSet(t *testing.T, where string, args ...any) setRow {
+	var s setRow
+	var equipment *string
+	err := TH.db.QueryRow(
+		`select repeat, distance_meters, total_distance, cast(equipment as text), cast("group" as text)
+		from sets where `+where,
+		args...,
+	).Scan(&s.repeat, &s.distanceMeters, &s.totalDistance, &equipment, &s.group)
 	require.NoError(t, err)
 
-	assert.Equal(5, result.repeat)
-	assert.Equal(50, result.distanceMeters)
-	assert.Equal(250, result.totalDistance)
-	assert.Equal(string(apidef.Monofin), result.equipment[0])
-	assert.Equal(string(apidef.Snorkel), result.equipment[1])
+	if equipment != nil {
+		for _, e := range strings.Split(strings.Trim(*equipment, "{}[]"), ",") {
+			s.equipment = append(s.equipment, strings.Trim(e, `"`))
+		}
+	}
+	return s
 }
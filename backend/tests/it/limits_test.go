@@ -0,0 +1,100 @@
+package it
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func limitedServer(t *testing.T, limits server.Limits) *httptest.Server {
+	h := server.NewServerHandler(app.New(TH.repo, 5*time.Second, nil), server.DefaultCors, limits, "", &atomic.Bool{})
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func getUsers(t *testing.T, ts *httptest.Server, ip string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/users", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Real-Ip", ip)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	return res
+}
+
+func TestRateLimit_PerOperationAndIp(t *testing.T) {
+	ts := limitedServer(t, server.Limits{
+		Default: server.Limit{Rate: 100, Burst: 100, MaxBytes: server.MaxBytes},
+		Operations: map[string]server.Limit{
+			"Users": {Rate: 0.5, Burst: 2, MaxBytes: server.MaxBytes},
+		},
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+	})
+
+	require.Equal(t, http.StatusOK, getUsers(t, ts, "10.0.0.1").StatusCode)
+	require.Equal(t, http.StatusOK, getUsers(t, ts, "10.0.0.1").StatusCode)
+
+	res := getUsers(t, ts, "10.0.0.1")
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	retryAfter, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, retryAfter)
+
+	// other clients and operations have their own buckets
+	assert.Equal(t, http.StatusOK, getUsers(t, ts, "10.0.0.2").StatusCode)
+	res, err = http.Get(ts.URL + "/monitoring/heartbeat")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = http.Get(ts.URL + "/trainings/details?page=0&pageSize=1")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestRateLimit_UntrustedRealIp(t *testing.T) {
+	ts := limitedServer(t, server.Limits{
+		Default: server.Limit{Rate: 100, Burst: 100, MaxBytes: server.MaxBytes},
+		Operations: map[string]server.Limit{
+			"Users": {Rate: 0.5, Burst: 1, MaxBytes: server.MaxBytes},
+		},
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	})
+
+	require.Equal(t, http.StatusOK, getUsers(t, ts, "10.0.0.1").StatusCode)
+	res := getUsers(t, ts, "10.0.0.2")
+	assert.Equal(
+		t,
+		http.StatusTooManyRequests,
+		res.StatusCode,
+		"X-Real-Ip from a client which isn't a trusted proxy doesn't get a new bucket",
+	)
+}
+
+func TestBodyLimit_PerOperation(t *testing.T) {
+	ts := limitedServer(t, server.Limits{
+		Default: server.Limit{Rate: 100, Burst: 100, MaxBytes: server.MaxBytes},
+		Operations: map[string]server.Limit{
+			"CreateUser": {Rate: 100, Burst: 100, MaxBytes: 32},
+		},
+	})
+
+	body := `{"name":"` + strings.Repeat("a", 32) + `"}`
+	res, err := http.Post(ts.URL+"/users", server.ApplicationJSON, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
@@ -0,0 +1,63 @@
+package it
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/migrations"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db, err := data.NewSqliteDb(filepath.Join(t.TempDir(), "migrations.db"), migrations.Sqlite())
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	status, err := db.Migrations(ctx)
+	require.NoError(t, err, "fresh database has no migrations table")
+	assert.Equal(t, uint(0), status.Version)
+	require.NotEmpty(t, status.Available)
+	assert.Equal(t, status.Available, status.Pending())
+	latest := status.Latest
+
+	require.NoError(t, db.MigrateTo(2, false))
+	status, err = db.Migrations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), status.Version)
+	assert.Equal(t, uint(3), status.Pending()[0])
+
+	require.NoError(t, db.MigrateUp(false))
+	status, err = db.Migrations(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.UpToDate())
+	assert.Empty(t, status.Pending())
+
+	require.NoError(t, db.MigrateDown(2, false))
+	status, err = db.Migrations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest-2, status.Version)
+
+	_, err = db.Exec("update schema_migrations set dirty = true")
+	require.NoError(t, err)
+	status, err = db.Migrations(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.Dirty)
+	require.Error(t, db.MigrateUp(false), "dirty database can't be migrated")
+
+	require.NoError(t, db.ForceMigration(int(latest-2)))
+	require.NoError(t, db.MigrateUp(false))
+	status, err = db.Migrations(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.UpToDate())
+
+	require.NoError(t, db.ForceMigration(-1))
+	status, err = db.Migrations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.False(t, status.Dirty)
+}
@@ -0,0 +1,193 @@
+package it
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestHeartbeat(t *testing.T) {
+	res, err := http.Get(TH.ts.URL + "/monitoring/heartbeat")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestHeartbeat_IgnoresShutdown(t *testing.T) {
+	TH.shuttingDown.Store(true)
+	defer TH.shuttingDown.Store(false)
+
+	res, err := http.Get(TH.ts.URL + "/monitoring/heartbeat")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode, "process is alive while shutting down")
+
+	res, err = http.Get(TH.ts.URL + "/monitoring/ready")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, "but isn't ready")
+}
+
+func TestReady(t *testing.T) {
+	res, err := http.Get(TH.ts.URL + "/monitoring/ready")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	readiness := decodeReadiness(t, res)
+	assert.Equal(t, "up", readiness.Status)
+	assert.Equal(t, "up", readiness.Dependencies["database"].Status)
+	assert.Equal(t, "up", readiness.Dependencies["migrations"].Status)
+}
+
+func TestReady_ShuttingDown(t *testing.T) {
+	TH.shuttingDown.Store(true)
+	defer TH.shuttingDown.Store(false)
+
+	res, err := http.Get(TH.ts.URL + "/monitoring/ready")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	readiness := decodeReadiness(t, res)
+	assert.Equal(t, "down", readiness.Status)
+	assert.Equal(t, "up", readiness.Dependencies["database"].Status)
+}
+
+func TestReady_MigrationsBehind(t *testing.T) {
+	var version int
+	err := TH.db.QueryRow("select version from schema_migrations").Scan(&version)
+	require.NoError(t, err)
+	_, err = TH.db.Exec("update schema_migrations set version = $1", version-1)
+	require.NoError(t, err)
+	defer func() {
+		_, err := TH.db.Exec("update schema_migrations set version = $1", version)
+		require.NoError(t, err)
+	}()
+
+	res, err := http.Get(TH.ts.URL + "/monitoring/ready")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	readiness := decodeReadiness(t, res)
+	assert.Equal(t, "down", readiness.Status)
+	assert.Equal(t, "up", readiness.Dependencies["database"].Status)
+	assert.Equal(t, "down", readiness.Dependencies["migrations"].Status)
+	assert.Empty(t, readiness.Dependencies["migrations"].Error, "details are only logged")
+}
+
+func TestGracefulShutdown_DrainDelay(t *testing.T) {
+	shuttingDown := &atomic.Bool{}
+	h := server.NewServerHandler(
+		app.New(TH.repo, 5*time.Second, nil),
+		server.DefaultCors,
+		unlimited,
+		"",
+		shuttingDown,
+	)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{Handler: h}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(l) }()
+
+	url := "http://" + l.Addr().String() + "/monitoring/ready"
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	ready := func() (int, error) {
+		res, err := client.Get(url)
+		if err != nil {
+			return 0, err
+		}
+		res.Body.Close()
+		return res.StatusCode, nil
+	}
+
+	status, err := ready()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.GracefulShutdown(
+			context.Background(),
+			srv,
+			shuttingDown,
+			300*time.Millisecond,
+			5*time.Second,
+		)
+	}()
+
+	require.Eventually(t, shuttingDown.Load, time.Second, time.Millisecond)
+	status, err = ready()
+	require.NoError(t, err, "server must keep serving during the drain delay")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	require.NoError(t, <-shutdown)
+	assert.ErrorIs(t, <-served, http.ErrServerClosed)
+	_, err = ready()
+	assert.Error(t, err, "server must stop listening after the drain delay")
+}
+
+func TestGracefulShutdown_CutShort(t *testing.T) {
+	srv := &http.Server{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := server.GracefulShutdown(ctx, srv, &atomic.Bool{}, time.Minute, time.Second)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+type readinessResponse struct {
+	Status       string `json:"status"`
+	Dependencies map[string]struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"dependencies"`
+}
+
+func decodeReadiness(t *testing.T, res *http.Response) readinessResponse {
+	defer res.Body.Close()
+	var readiness readinessResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&readiness))
+	return readiness
+}
+
+func TestMetrics(t *testing.T) {
+	createTraining(t, nil)
+
+	res := adminRequest(t, TH.ts, http.MethodGet, "/monitoring/metrics", nil)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(
+		t,
+		string(body),
+		`swimlogs_http_requests_total{operation="CreateTraining",status="201"}`,
+	)
+	assert.Contains(
+		t,
+		string(body),
+		`swimlogs_http_request_duration_seconds_count{operation="CreateTraining",status="201"}`,
+	)
+	assert.Contains(t, string(body), "swimlogs_trainings_created_total")
+}
+
+func TestMetrics_Unauthorized(t *testing.T) {
+	res, err := http.Get(TH.ts.URL + "/monitoring/metrics")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
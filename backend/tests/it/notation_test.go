@@ -0,0 +1,203 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/notation"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+var meters25 = apidef.Pool{Length: 25, Unit: apidef.Meters}
+
+func TestNotationParse(t *testing.T) {
+	cases := []struct {
+		line string
+		want apidef.NewTrainingSet
+	}{
+		{"400", apidef.NewTrainingSet{Repeat: 1, DistanceMeters: 400, StartType: apidef.None}},
+		{
+			"8x100 fr @1:40 fins",
+			apidef.NewTrainingSet{
+				Repeat:         8,
+				DistanceMeters: 100,
+				Description:    asPtr("fr"),
+				StartType:      apidef.Interval,
+				StartSeconds:   asPtr(100),
+				Equipment:      &[]apidef.EquipmentEnum{apidef.Fins},
+			},
+		},
+		{
+			"4x50 kick P20",
+			apidef.NewTrainingSet{
+				Repeat:         4,
+				DistanceMeters: 50,
+				Description:    asPtr("kick"),
+				StartType:      apidef.Pause,
+				StartSeconds:   asPtr(20),
+			},
+		},
+		{
+			`6X200 "fins off" pull P1:00 Paddles snorkel PADDLES [Long]`,
+			apidef.NewTrainingSet{
+				Repeat:         6,
+				DistanceMeters: 200,
+				Description:    asPtr("fins off pull"),
+				StartType:      apidef.Pause,
+				StartSeconds:   asPtr(60),
+				Equipment:      &[]apidef.EquipmentEnum{apidef.Paddles, apidef.Snorkel},
+				Group:          asPtr(apidef.Long),
+			},
+		},
+		{
+			"10×25 max @45 monofin [mono]",
+			apidef.NewTrainingSet{
+				Repeat:         10,
+				DistanceMeters: 25,
+				Description:    asPtr("max"),
+				StartType:      apidef.Interval,
+				StartSeconds:   asPtr(45),
+				Equipment:      &[]apidef.EquipmentEnum{apidef.Monofin},
+				Group:          asPtr(apidef.Mono),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.line, func(t *testing.T) {
+			sets, err := notation.Parse(c.line, meters25)
+			require.NoError(t, err)
+			require.Len(t, sets, 1)
+			assert.Equal(t, c.want, sets[0])
+		})
+	}
+}
+
+func TestNotationParse_Lines(t *testing.T) {
+	sets, err := notation.Parse("# warm up\n400\n\n  8x100 @1:40\n", meters25)
+	require.NoError(t, err)
+	require.Len(t, sets, 2)
+	assert.Equal(t, 0, sets[0].SetOrder)
+	assert.Equal(t, 1, sets[1].SetOrder)
+
+	sets, err = notation.Parse("4x100 P15", apidef.Pool{Length: 25, Unit: apidef.Yards})
+	require.NoError(t, err)
+	assert.Equal(t, asPtr(100), sets[0].DistanceYards)
+	assert.Zero(t, sets[0].DistanceMeters)
+}
+
+func TestNotationParse_Invalid(t *testing.T) {
+	_, err := notation.Parse(
+		"fast 100\n8x100 @1:40 P20\n4x30\n4x50 [fast]\n100 @1:70\n0x100\n100 \"open",
+		meters25,
+	)
+	assert.ErrorIs(t, err, notation.ErrInvalid)
+
+	var syntaxErr *notation.SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	lines := make([]int, 0, len(syntaxErr.Lines))
+	for _, l := range syntaxErr.Lines {
+		lines = append(lines, l.Line)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, lines)
+}
+
+func TestNotationRender_RoundTrip(t *testing.T) {
+	text := "400 warm up\n" +
+		"8x100 fr @1:40 fins [sprint]\n" +
+		"4x50 kick P20 board\n" +
+		`3x200 "pull, no fins" P30 paddles snorkel` + "\n" +
+		`100 "say \"easy\""` + "\n"
+
+	for _, pool := range []apidef.Pool{meters25, {Length: 25, Unit: apidef.Yards}} {
+		sets, err := notation.Parse(text, pool)
+		require.NoError(t, err)
+
+		training := apidef.Training{Id: uuid.New(), Pool: &pool}
+		// stored order differs from written order
+		for i := len(sets) - 1; i >= 0; i-- {
+			s := sets[i]
+			training.Sets = append(training.Sets, apidef.TrainingSet{
+				Id:             uuid.New(),
+				SetOrder:       s.SetOrder,
+				Repeat:         s.Repeat,
+				DistanceMeters: s.DistanceMeters,
+				DistanceYards:  s.DistanceYards,
+				Description:    s.Description,
+				StartType:      s.StartType,
+				StartSeconds:   s.StartSeconds,
+				Equipment:      s.Equipment,
+				Group:          s.Group,
+			})
+		}
+
+		rendered := notation.Render(training)
+		assert.Equal(t, text, rendered)
+		reparsed, err := notation.Parse(rendered, pool)
+		require.NoError(t, err)
+		assert.Equal(t, sets, reparsed)
+	}
+}
+
+func TestNotationRender_YardsFromMeters(t *testing.T) {
+	pool := apidef.Pool{Length: 25, Unit: apidef.Yards}
+	set := apidef.TrainingSet{Repeat: 4, DistanceMeters: 96, StartType: apidef.None}
+	assert.Equal(t, "4x100", notation.RenderSet(set, pool))
+
+	rendered, err := app.New(TH.repo, 0, time.UTC).RenderTraining(
+		apidef.Training{Pool: &pool, Sets: []apidef.TrainingSet{set}},
+		app.PlainText,
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "1. 4 × 100yd\n")
+}
+
+func TestParseNotation(t *testing.T) {
+	req, err := json.Marshal(apidef.ParseNotationRequest{
+		Notation: "400 warm up\n8x100 @1:40 fins",
+		Pool:     &apidef.Pool{Length: 25, Unit: apidef.Yards},
+	})
+	require.NoError(t, err)
+
+	res, err := http.Post(TH.ts.URL+"/trainings/parse", server.ApplicationJSON, bytes.NewReader(req))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var parsed apidef.ParseNotationResponse
+	err = json.NewDecoder(res.Body).Decode(&parsed)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	require.Len(t, parsed.Sets, 2)
+	assert.Equal(t, 366, parsed.Sets[0].DistanceMeters, "400 yards")
+	assert.Equal(t, asPtr(800), parsed.Sets[1].TotalDistanceYards)
+	assert.Equal(t, 1200, parsed.TotalDistanceYards)
+	// totals convert from yards at once, not as a sum of converted sets
+	assert.Equal(t, 1097, parsed.TotalDistance)
+}
+
+func TestParseNotation_Invalid(t *testing.T) {
+	req, err := json.Marshal(apidef.ParseNotationRequest{Notation: "400\n8x100 @soon"})
+	require.NoError(t, err)
+
+	res, err := http.Post(TH.ts.URL+"/trainings/parse", server.ApplicationJSON, bytes.NewReader(req))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	var detail apidef.ErrorDetail
+	err = json.NewDecoder(res.Body).Decode(&detail)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_notation", detail.Code)
+	assert.Contains(t, detail.Detail, "line 2")
+}
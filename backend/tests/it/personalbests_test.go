@@ -0,0 +1,99 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestCreateRaceResult_UserNotFound(t *testing.T) {
+	res := createRaceResult(t, uuid.New(), raceResult(100, 60_000, 0))
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestCreateRaceResult_DistanceNotMultipleOfPool(t *testing.T) {
+	user := createUser(t, "swimmer")
+
+	res := createRaceResult(t, user.Id, raceResult(110, 60_000, 0))
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestPersonalBests(t *testing.T) {
+	user := createUser(t, "swimmer")
+
+	assert := assert.New(t)
+	first := decodeRaceResult(t, createRaceResult(t, user.Id, raceResult(100, 62_000, 0)))
+	assert.True(first.IsPersonalBest)
+	slower := decodeRaceResult(t, createRaceResult(t, user.Id, raceResult(100, 63_000, 1)))
+	assert.False(slower.IsPersonalBest)
+	faster := decodeRaceResult(t, createRaceResult(t, user.Id, raceResult(100, 60_500, 2)))
+	assert.True(faster.IsPersonalBest)
+	other := decodeRaceResult(t, createRaceResult(t, user.Id, raceResult(50, 28_000, 2)))
+	assert.True(other.IsPersonalBest)
+	backDated := decodeRaceResult(t, createRaceResult(t, user.Id, raceResult(50, 27_000, 1)))
+	assert.True(backDated.IsPersonalBest)
+
+	url := TH.ts.URL + "/users/" + user.Id.String() + "/personal-bests"
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var pbs apidef.PersonalBestsResponse
+	err = json.NewDecoder(res.Body).Decode(&pbs)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	require.Len(t, pbs.PersonalBests, 2)
+	assert.Equal(50, pbs.PersonalBests[0].Distance)
+	assert.Equal(backDated.Id, pbs.PersonalBests[0].Best.Id)
+	require.Len(t, pbs.PersonalBests[0].Progression, 1, "back-dated result was swum first")
+	assert.True(pbs.PersonalBests[0].Progression[0].IsPersonalBest)
+
+	assert.Equal(100, pbs.PersonalBests[1].Distance)
+	assert.Equal(faster.Id, pbs.PersonalBests[1].Best.Id)
+	require.Len(t, pbs.PersonalBests[1].Progression, 2)
+	assert.Equal(first.Id, pbs.PersonalBests[1].Progression[0].Id)
+	assert.Equal(faster.Id, pbs.PersonalBests[1].Progression[1].Id)
+}
+
+func raceResult(distance, timeMs, day int) apidef.NewRaceResult {
+	return apidef.NewRaceResult{
+		Distance: distance,
+		Stroke:   apidef.Freestyle,
+		Pool:     apidef.Pool{Length: 25, Unit: apidef.Meters},
+		TimeMs:   timeMs,
+		Date:     types.Date{Time: time.Now().AddDate(0, 0, day-10)},
+	}
+}
+
+func createRaceResult(t *testing.T, userId uuid.UUID, r apidef.NewRaceResult) *http.Response {
+	req, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/users/" + userId.String() + "/race-results"
+	res, err := http.Post(url, server.ApplicationJSON, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	return res
+}
+
+func decodeRaceResult(t *testing.T, res *http.Response) apidef.RaceResult {
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var result apidef.RaceResult
+	err := json.NewDecoder(res.Body).Decode(&result)
+	res.Body.Close()
+	require.NoError(t, err)
+	return result
+}
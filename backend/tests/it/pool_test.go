@@ -0,0 +1,164 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestPool_DefaultsTo25Meters(t *testing.T) {
+	id := createTraining(t, nil).Id
+	training := trainingById(t, id)
+
+	require.NotNil(t, training.Pool)
+	assert.Equal(t, 25, training.Pool.Length)
+	assert.Equal(t, apidef.Meters, training.Pool.Unit)
+}
+
+func TestPool_Yards(t *testing.T) {
+	request := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Pool:        &apidef.Pool{Length: 25, Unit: apidef.Yards},
+		Sets: []apidef.NewTrainingSet{
+			{
+				DistanceYards: asPtr(275),
+				Repeat:        1,
+				SetOrder:      0,
+				StartType:     apidef.None,
+			},
+			{
+				DistanceYards: asPtr(100),
+				Repeat:        8,
+				SetOrder:      1,
+				StartSeconds:  asPtr(90),
+				StartType:     apidef.Interval,
+			},
+		},
+		Start: time.Now(),
+	}
+	detail := createTraining(t, &request)
+
+	assert := assert.New(t)
+	assert.Equal(apidef.Yards, detail.Pool.Unit)
+	assert.Equal(1075, detail.TotalDistanceYards)
+	assert.Equal(983, detail.TotalDistance)
+
+	training := trainingById(t, detail.Id)
+	require.Len(t, training.Sets, 2)
+
+	assert.Equal(275, *training.Sets[0].DistanceYards)
+	assert.Equal(251, training.Sets[0].DistanceMeters)
+	assert.Equal(100, *training.Sets[1].DistanceYards)
+	assert.Equal(91, training.Sets[1].DistanceMeters)
+	assert.Equal(800, *training.Sets[1].TotalDistanceYards)
+	assert.Equal(732, training.Sets[1].TotalDistance)
+}
+
+func TestPool_YardsTotalFromPoolLengths(t *testing.T) {
+	request := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Pool:        &apidef.Pool{Length: 25, Unit: apidef.Yards},
+		Start:       time.Now(),
+	}
+	for i := 0; i < 100; i++ {
+		request.Sets = append(request.Sets, apidef.NewTrainingSet{
+			DistanceYards: asPtr(25),
+			Repeat:        1,
+			SetOrder:      i,
+			StartType:     apidef.None,
+		})
+	}
+	detail := createTraining(t, &request)
+
+	// each 25 yards is 22.86 meters rounded to 23, summing those is 2525 yards
+	assert.Equal(t, 2500, detail.TotalDistanceYards)
+	assert.Equal(t, 2286, detail.TotalDistance)
+
+	training := trainingById(t, detail.Id)
+	require.NotNil(t, training.TotalDistanceYards)
+	assert.Equal(t, 2500, *training.TotalDistanceYards)
+	assert.Equal(t, 2286, training.TotalDistance)
+	assert.Equal(t, 25, *training.Sets[99].TotalDistanceYards)
+}
+
+func TestPool_EditKeepsPool(t *testing.T) {
+	request := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Pool:        &apidef.Pool{Length: 25, Unit: apidef.Yards},
+		Sets: []apidef.NewTrainingSet{
+			{DistanceYards: asPtr(100), Repeat: 4, SetOrder: 0, StartType: apidef.None},
+		},
+		Start: time.Now(),
+	}
+	training := trainingById(t, createTraining(t, &request).Id)
+
+	training.Pool = nil
+	training.Sets[0].Repeat = 2
+	body, err := json.Marshal(training)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, TH.ts.URL+"/trainings/"+training.Id.String(), bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", server.ApplicationJSON)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	edited := trainingById(t, training.Id)
+	assert.Equal(t, apidef.Pool{Length: 25, Unit: apidef.Yards}, *edited.Pool)
+	assert.Equal(t, 200, *edited.TotalDistanceYards)
+	assert.Equal(t, 100, *edited.Sets[0].DistanceYards)
+}
+
+func TestPool_YardsWithoutMeters(t *testing.T) {
+	body := `{"durationMin":30,"totalDistance":0,"start":"2024-03-04T17:00:00Z","pool":{"length":25,"unit":"yards"},` +
+		`"sets":[{"setOrder":0,"repeat":2,"distanceYards":50,"totalDistance":0,"startType":"None"}]}`
+	res, err := http.Post(TH.ts.URL+"/trainings", server.ApplicationJSON, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var detail apidef.TrainingDetail
+	err = json.NewDecoder(res.Body).Decode(&detail)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, 100, detail.TotalDistanceYards)
+
+	body = `{"durationMin":30,"totalDistance":0,"start":"2024-03-04T17:00:00Z",` +
+		`"sets":[{"setOrder":0,"repeat":2,"distanceYards":50,"totalDistance":0,"startType":"None"}]}`
+	res, err = http.Post(TH.ts.URL+"/trainings", server.ApplicationJSON, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "meter pools need distanceMeters")
+}
+
+func TestPool_DistanceNotMultipleOfLength(t *testing.T) {
+	request := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Pool:        &apidef.Pool{Length: 50, Unit: apidef.Meters},
+		Sets: []apidef.NewTrainingSet{
+			{
+				DistanceMeters: 75,
+				Repeat:         2,
+				SetOrder:       0,
+				StartType:      apidef.None,
+			},
+		},
+		Start: time.Now(),
+	}
+	req, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/trainings"
+	res, err := http.Post(url, server.ApplicationJSON, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,16 @@
+package it
+
+import (
+	"testing"
+
+	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/data/datatest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	datatest.RunContract(t, func(t *testing.T) data.Repository {
+		TH.CleanTrainings(t)
+		TH.CleanUsers(t)
+		return TH.repo
+	})
+}
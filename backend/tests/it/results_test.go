@@ -0,0 +1,104 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestSubmitSetResult_SetNotFound(t *testing.T) {
+	user := createUser(t, "swimmer")
+
+	res := submitSetResult(t, uuid.New(), apidef.NewSetResult{
+		UserId:  user.Id,
+		TimesMs: []int{60_000},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestSubmitSetResult_WrongRepetitionCount(t *testing.T) {
+	user := createUser(t, "swimmer")
+	training := trainingById(t, createTraining(t, &eightHundredsTraining).Id)
+
+	res := submitSetResult(t, training.Sets[0].Id, apidef.NewSetResult{
+		UserId:  user.Id,
+		TimesMs: []int{60_000, 61_000},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestSubmitSetResult(t *testing.T) {
+	user := createUser(t, "swimmer")
+	training := trainingById(t, createTraining(t, &eightHundredsTraining).Id)
+	setId := training.Sets[0].Id
+
+	res := submitSetResult(t, setId, apidef.NewSetResult{
+		UserId:  user.Id,
+		TimesMs: []int{1, 1, 1, 1, 1, 1, 1, 1},
+	})
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	times := []int{81_000, 82_000, 82_500, 83_000, 83_000, 84_000, 84_400, 80_900}
+	res = submitSetResult(t, setId, apidef.NewSetResult{UserId: user.Id, TimesMs: times})
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var result apidef.SetResult
+	err := json.NewDecoder(res.Body).Decode(&result)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	assert.Equal(times, result.TimesMs)
+	assert.Equal(82_600, result.AverageMs)
+	assert.Equal(80_900, result.BestMs)
+	assert.Equal(84_400, result.WorstMs)
+
+	url := TH.ts.URL + "/sets/" + setId.String() + "/results"
+	res, err = http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var results apidef.SetResultsResponse
+	err = json.NewDecoder(res.Body).Decode(&results)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	require.Len(t, results.Results, 1)
+	assert.Equal(result, results.Results[0])
+}
+
+var eightHundredsTraining = apidef.CreateTrainingRequest{
+	DurationMin: 60,
+	Sets: []apidef.NewTrainingSet{
+		{
+			DistanceMeters: 100,
+			Repeat:         8,
+			SetOrder:       0,
+			StartSeconds:   asPtr(100),
+			StartType:      apidef.Interval,
+		},
+	},
+	Start: time.Now(),
+}
+
+func submitSetResult(t *testing.T, setId uuid.UUID, result apidef.NewSetResult) *http.Response {
+	req, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/sets/" + setId.String() + "/results"
+	res, err := http.Post(url, server.ApplicationJSON, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	return res
+}
@@ -0,0 +1,118 @@
+package it
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/data"
+	"github.com/Nesquiko/swimlogs/pkg/seed"
+)
+
+var seedOptions = seed.Options{
+	Seed:  42,
+	Count: 150,
+	From:  time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+	To:    time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC),
+}
+
+func TestSeed_Deterministic(t *testing.T) {
+	first, err := seed.Trainings(seedOptions)
+	require.NoError(t, err)
+	second, err := seed.Trainings(seedOptions)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	other := seedOptions
+	other.Seed++
+	third, err := seed.Trainings(other)
+	require.NoError(t, err)
+	assert.NotEqual(t, first[0].Id, third[0].Id)
+
+	require.Len(t, first, seedOptions.Count)
+	last := seedOptions.To.AddDate(0, 0, 1)
+	for i, training := range first {
+		assert.False(t, training.Start.Before(seedOptions.From), "training %d", i)
+		assert.True(t, training.Start.Before(last), "training %d", i)
+		if i > 0 {
+			assert.False(t, training.Start.Before(first[i-1].Start), "ordered by start")
+		}
+
+		require.NotEmpty(t, training.Sets)
+		total := 0
+		for _, set := range training.Sets {
+			total += set.TotalDistance
+			assert.Zero(t, set.DistanceMeters%training.PoolLength, "whole lengths")
+			if set.StartType == string(apidef.None) {
+				assert.Nil(t, set.StartSeconds)
+			} else {
+				assert.NotNil(t, set.StartSeconds)
+			}
+		}
+		assert.Equal(t, total, training.TotalDistance)
+	}
+}
+
+func TestSeed_InvalidOptions(t *testing.T) {
+	invalid := seedOptions
+	invalid.To = invalid.From.AddDate(0, 0, -1)
+	_, err := seed.Trainings(invalid)
+	assert.ErrorIs(t, err, seed.ErrInvalidOptions)
+}
+
+func TestSeed_TrainingDetailsPages(t *testing.T) {
+	TH.CleanTrainings(t)
+	seeded, err := seed.Persist(context.Background(), TH.repo, seedOptions)
+	require.NoError(t, err)
+
+	pageSize := 40
+	seen := make([]data.Training, 0, len(seeded))
+	for page := 0; page*pageSize < len(seeded); page++ {
+		url := fmt.Sprintf("%s/trainings/details?page=%d&pageSize=%d", TH.ts.URL, page, pageSize)
+		res, err := http.Get(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var details apidef.TrainingDetailsResponse
+		err = json.NewDecoder(res.Body).Decode(&details)
+		res.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, len(seeded), details.Pagination.Total)
+
+		for _, d := range details.Details {
+			seen = append(seen, data.Training{Id: d.Id, Start: d.Start})
+		}
+	}
+
+	require.Len(t, seen, len(seeded))
+	for i, training := range seen {
+		// newest first, seeded are oldest first
+		assert.WithinDuration(t, seeded[len(seeded)-1-i].Start, training.Start, 0)
+	}
+
+	stored := trainingById(t, seeded[0].Id)
+	assert.Len(t, stored.Sets, len(seeded[0].Sets))
+}
+
+func TestSeed_PersistSkipsSeeded(t *testing.T) {
+	TH.CleanTrainings(t)
+	ctx := context.Background()
+	seeded, err := seed.Persist(ctx, TH.repo, seedOptions)
+	require.NoError(t, err)
+	require.NotEmpty(t, seeded)
+
+	again, err := seed.Persist(ctx, TH.repo, seedOptions)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	_, total, err := TH.repo.TrainingDetails(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, len(seeded), total)
+}
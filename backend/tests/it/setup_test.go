@@ -2,31 +2,56 @@ package it
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/time/rate"
 
+	"github.com/Nesquiko/swimlogs/migrations"
 	"github.com/Nesquiko/swimlogs/pkg/app"
 	"github.com/Nesquiko/swimlogs/pkg/data"
 	"github.com/Nesquiko/swimlogs/pkg/server"
 )
 
+// TestDbDriverEnvVar selects the database tests run against, postgres by
+// default or sqlite.
+const TestDbDriverEnvVar = "TEST_DB_DRIVER"
+
 type TestHarness struct {
 	ts   *httptest.Server
-	pool *data.PostgresDbPool
+	repo data.Repository
+	// db is the database behind repo, for asserting on raw tables
+	db           *sql.DB
+	driver       string
+	shuttingDown *atomic.Bool
+	// spans records every finished span
+	spans *tracetest.SpanRecorder
 }
 
 var TH TestHarness
 
+// unlimited doesn't rate limit tests, which make many requests at once
+var unlimited = server.Limits{Default: server.Limit{Rate: rate.Inf, MaxBytes: server.MaxBytes}}
+
+const adminToken = "test-admin-token-1234"
+
 func TestMain(m *testing.M) {
 	log.Logger = log.Output(
 		zerolog.ConsoleWriter{
@@ -37,6 +62,59 @@ func TestMain(m *testing.M) {
 		Caller().
 		Logger()
 
+	driver := os.Getenv(TestDbDriverEnvVar)
+	if driver == "" {
+		driver = "postgres"
+	}
+
+	var (
+		repo     data.Repository
+		db       *sql.DB
+		teardown func() error
+		err      error
+	)
+	switch driver {
+	case "postgres":
+		repo, db, teardown, err = setupPostgres()
+	case "sqlite":
+		repo, db, teardown, err = setupSqlite()
+	default:
+		err = fmt.Errorf("unknown driver %q", driver)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up %s database, %s", driver, err.Error())
+		os.Exit(1)
+	}
+
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	swimlogs := app.New(repo, 5*time.Second, nil)
+	shuttingDown := &atomic.Bool{}
+	h := server.NewServerHandler(swimlogs, server.DefaultCors, unlimited, adminToken, shuttingDown)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	TH = TestHarness{
+		ts:           ts,
+		repo:         repo,
+		db:           db,
+		driver:       driver,
+		shuttingDown: shuttingDown,
+		spans:        spans,
+	}
+
+	exitCode := m.Run()
+	if err := teardown(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to tear down %s database, %s", driver, err.Error())
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+func setupPostgres() (data.Repository, *sql.DB, func() error, error) {
 	ctx := context.Background()
 	pgContainer, err := postgres.RunContainer(
 		ctx,
@@ -51,46 +129,52 @@ func TestMain(m *testing.M) {
 		),
 	)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize container, %s", err.Error())
-		os.Exit(1)
+		return nil, nil, nil, fmt.Errorf("initialize container: %w", err)
 	}
 
 	conStr, err := pgContainer.ConnectionString(ctx, "sslmode=disable")
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get connection string, %s", err.Error())
-		os.Exit(1)
+		return nil, nil, nil, fmt.Errorf("get connection string: %w", err)
 	}
 
-	pool, err := data.NewPostgresPool(conStr, "../../migrations")
+	pool, err := data.NewPostgresPool(conStr, migrations.Postgres())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create new pool, %s", err.Error())
-		os.Exit(1)
+		return nil, nil, nil, fmt.Errorf("create new pool: %w", err)
 	}
-	defer pool.Close()
 
 	err = pool.MigrateUp(true)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to migrate db, %s", err.Error())
-		os.Exit(1)
+		return nil, nil, nil, fmt.Errorf("migrate db: %w", err)
 	}
 
-	swimlogs := app.New(pool)
-	h := server.NewServerHandler(swimlogs, "")
-	ts := httptest.NewServer(h)
-	defer ts.Close()
+	teardown := func() error {
+		pool.Close()
+		return pgContainer.Terminate(ctx)
+	}
+	return pool, stdlib.OpenDBFromPool(pool.Pool), teardown, nil
+}
 
-	TH = TestHarness{
-		ts:   ts,
-		pool: pool,
+func setupSqlite() (data.Repository, *sql.DB, func() error, error) {
+	dir, err := os.MkdirTemp("", "swimlogs-it")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("create temp dir: %w", err)
 	}
 
-	exitCode := m.Run()
-	if err := pgContainer.Terminate(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to terminate container, %s", err.Error())
-		os.Exit(1)
+	db, err := data.NewSqliteDb(filepath.Join(dir, "swimlogs.db"), migrations.Sqlite())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("open db: %w", err)
 	}
 
-	os.Exit(exitCode)
+	err = db.MigrateUp(true)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("migrate db: %w", err)
+	}
+
+	teardown := func() error {
+		db.Close()
+		return os.RemoveAll(dir)
+	}
+	return db, db.DB, teardown, nil
 }
 
 func asPtr[T any](v T) *T {
@@ -98,6 +182,11 @@ func asPtr[T any](v T) *T {
 }
 
 func (th TestHarness) CleanTrainings(t *testing.T) {
-	err := data.Sql(th.pool, "truncate trainings cascade")
+	_, err := th.db.Exec("delete from trainings")
+	require.NoError(t, err)
+}
+
+func (th TestHarness) CleanUsers(t *testing.T) {
+	_, err := th.db.Exec("delete from users")
 	require.NoError(t, err)
 }
@@ -0,0 +1,68 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestTracing_ContinuesPropagatedTrace(t *testing.T) {
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Sets: []apidef.NewTrainingSet{
+			{DistanceMeters: 100, Repeat: 1, SetOrder: 0, StartType: apidef.None},
+		},
+		Start: time.Now(),
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, TH.ts.URL+"/trainings", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set(server.ContentType, server.ApplicationJSON)
+	req.Header.Set("traceparent", "00-"+traceId+"-00f067aa0ba902b7-01")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	spans := spansOfTrace(traceId)
+	requestSpan := findSpan(t, spans, "CreateTraining")
+	assert.Equal(t, trace.SpanKindServer, requestSpan.SpanKind())
+	assert.Equal(t, "00f067aa0ba902b7", requestSpan.Parent().SpanID().String())
+
+	appSpan := findSpan(t, spans, "SwimLogsApp.CreateTraining")
+	assert.Equal(t, requestSpan.SpanContext().SpanID(), appSpan.Parent().SpanID())
+}
+
+func spansOfTrace(traceId string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, s := range TH.spans.Ended() {
+		if s.SpanContext().TraceID().String() == traceId {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+func findSpan(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, s := range spans {
+		if s.Name() == name {
+			return s
+		}
+	}
+	require.FailNowf(t, "span not found", "no span named %q", name)
+	return nil
+}
@@ -2,6 +2,7 @@ package it
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"testing"
 	"time"
@@ -11,6 +12,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/app"
+	"github.com/Nesquiko/swimlogs/pkg/notation"
+	"github.com/Nesquiko/swimlogs/pkg/server"
 )
 
 func TestTraining_NotFound(t *testing.T) {
@@ -85,6 +89,138 @@ func TestTraining(t *testing.T) {
 	}
 }
 
+func TestTraining_Text(t *testing.T) {
+	training := apidef.CreateTrainingRequest{
+		DurationMin: 90,
+		Sets: []apidef.NewTrainingSet{
+			{
+				Description:    asPtr("*easy* warm up"),
+				DistanceMeters: 400,
+				Repeat:         1,
+				StartType:      apidef.None,
+			},
+			{
+				DistanceMeters: 100,
+				Equipment:      &[]apidef.EquipmentEnum{apidef.Fins, apidef.Snorkel},
+				Group:          asPtr(apidef.Sprint),
+				Repeat:         8,
+				SetOrder:       1,
+				StartSeconds:   asPtr(100),
+				StartType:      apidef.Interval,
+			},
+			{
+				Description:    asPtr("kick"),
+				DistanceMeters: 50,
+				Repeat:         4,
+				SetOrder:       2,
+				StartSeconds:   asPtr(20),
+				StartType:      apidef.Pause,
+			},
+		},
+		Start: time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC),
+	}
+	id := createTraining(t, &training).Id
+
+	cases := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", server.ApplicationJSON},
+		{"*/*", server.ApplicationJSON},
+		{"text/markdown", server.TextMarkdown + "; charset=utf-8"},
+		{"text/plain", server.TextPlain + "; charset=utf-8"},
+		{"text/*", server.TextPlain + "; charset=utf-8"},
+		{"text/markdown;q=0.5, text/plain", server.TextPlain + "; charset=utf-8"},
+		{"application/json;q=0, */*", server.TextPlain + "; charset=utf-8"},
+		{"text/*;q=0.9, text/markdown", server.TextMarkdown + "; charset=utf-8"},
+		{server.TextNotation, server.TextNotation + "; charset=utf-8"},
+		{"text/html, */*;q=0.8", server.ApplicationJSON},
+	}
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			res := getTraining(t, id.String(), c.accept)
+			res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, c.contentType, res.Header.Get(server.ContentType))
+			assert.Contains(t, res.Header.Values("Vary"), server.Accept)
+		})
+	}
+
+	res := getTraining(t, id.String()+"?tz=America/New_York", server.TextPlain)
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Training Mon 2024-03-04 12:00\n")
+
+	res = getTraining(t, id.String(), server.TextNotation)
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, notation.Render(trainingById(t, id)), string(body))
+
+	res = getTraining(t, id.String()+"?tz=Mars/Olympus", server.TextPlain)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res = getTraining(t, id.String(), "application/xml")
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotAcceptable, res.StatusCode)
+
+	res = getTraining(t, uuid.NewString(), server.TextMarkdown)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res = getTraining(t, id.String(), server.TextPlain)
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, renderTraining(t, app.New(TH.repo, 0, nil), trainingById(t, id), app.PlainText), string(body))
+
+	rendered := trainingById(t, id)
+	utc := app.New(TH.repo, 0, time.UTC)
+	assert.Equal(t, "Training Mon 2024-03-04 17:00\n\n"+
+		"25m pool\n\n"+
+		"1. 400m *easy* warm up\n"+
+		"2. 8 × 100m, interval 1:40, fins + snorkel, group sprint\n"+
+		"3. 4 × 50m kick, pause 20\n\n"+
+		"Total 1400m in 90 min\n",
+		renderTraining(t, utc, rendered, app.PlainText),
+	)
+	assert.Equal(t, "# Training Mon 2024-03-04 17:00\n\n"+
+		"25m pool\n\n"+
+		"1. 400m \\*easy\\* warm up\n"+
+		"2. 8 × 100m, interval 1:40, fins + snorkel, group sprint\n"+
+		"3. 4 × 50m kick, pause 20\n\n"+
+		"**Total** 1400m in 90 min\n",
+		renderTraining(t, utc, rendered, app.Markdown),
+	)
+
+	rendered.Pool = &apidef.Pool{Length: 25, Unit: apidef.Yards}
+	rendered.Sets = rendered.Sets[2:]
+	rendered.Sets[0].DistanceYards = asPtr(50)
+	rendered.TotalDistanceYards = asPtr(200)
+	assert.Contains(t, renderTraining(t, utc, rendered, app.PlainText), "1. 4 × 50yd kick, pause 20\n")
+	assert.Contains(t, renderTraining(t, utc, rendered, app.PlainText), "Total 200yd in 90 min\n")
+}
+
+func renderTraining(t *testing.T, a app.SwimLogsApp, training apidef.Training, f app.TextFormat) string {
+	text, err := a.RenderTraining(training, f, nil)
+	require.NoError(t, err)
+	return text
+}
+
+// getTraining gets a training by path, an id with an optional query.
+func getTraining(t *testing.T, path string, accept string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, TH.ts.URL+"/trainings/"+path, nil)
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set(server.Accept, accept)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return res
+}
+
 func trainingById(t *testing.T, id uuid.UUID) apidef.Training {
 	url := TH.ts.URL + "/trainings/" + id.String()
 	res, err := http.Get(url)
@@ -0,0 +1,60 @@
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestCreateUser_EmptyName(t *testing.T) {
+	req, err := json.Marshal(apidef.CreateUserRequest{Name: "  "})
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/users"
+	res, err := http.Post(url, server.ApplicationJSON, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestUsers(t *testing.T) {
+	name := "swimmer " + uuid.NewString()
+	id := createUser(t, name).Id
+
+	url := TH.ts.URL + "/users"
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var users apidef.UsersResponse
+	err = json.NewDecoder(res.Body).Decode(&users)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	assert.Contains(t, users.Users, apidef.User{Id: id, Name: name})
+}
+
+func createUser(t *testing.T, name string) apidef.User {
+	req, err := json.Marshal(apidef.CreateUserRequest{Name: name})
+	require.NoError(t, err)
+
+	url := TH.ts.URL + "/users"
+	res, err := http.Post(url, server.ApplicationJSON, bytes.NewBuffer(req))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var user apidef.User
+	err = json.NewDecoder(res.Body).Decode(&user)
+	res.Body.Close()
+	require.NoError(t, err)
+
+	return user
+}
@@ -0,0 +1,220 @@
+package it
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Nesquiko/swimlogs/apidef"
+	"github.com/Nesquiko/swimlogs/pkg/fit"
+	"github.com/Nesquiko/swimlogs/pkg/server"
+)
+
+func TestTrainingWorkout(t *testing.T) {
+	training := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Sets: []apidef.NewTrainingSet{
+			{
+				Description:    asPtr("warm up"),
+				DistanceMeters: 400,
+				Repeat:         1,
+				StartType:      apidef.None,
+			},
+			{
+				DistanceMeters: 50,
+				Repeat:         4,
+				SetOrder:       2,
+				StartSeconds:   asPtr(20),
+				StartType:      apidef.Pause,
+				Equipment:      &[]apidef.EquipmentEnum{apidef.Monofin},
+			},
+			{
+				DistanceMeters: 100,
+				Equipment:      &[]apidef.EquipmentEnum{apidef.Fins, apidef.Snorkel},
+				Group:          asPtr(apidef.Sprint),
+				Repeat:         8,
+				SetOrder:       1,
+				StartSeconds:   asPtr(100),
+				StartType:      apidef.Interval,
+			},
+		},
+		Start: time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC),
+	}
+	id := createTraining(t, &training).Id
+
+	res, file := getWorkout(t, id)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, fit.ContentType, res.Header.Get(server.ContentType))
+	assert.Regexp(t, `^attachment; filename=training-2024-03-0[45]-\d{4}\.fit$`, res.Header.Get("Content-Disposition"))
+
+	messages := decodeFIT(t, file)
+	require.Len(t, messages, 9)
+
+	fileId := messages[0]
+	require.Equal(t, fit.MesgFileId, fileId.num)
+	assert.Equal(t, []byte{5}, fileId.fields[0], "workout file")
+	assert.Equal(t, uint32(1078506000), fileId.uint32(4), "seconds from FIT epoch to start")
+
+	workout := messages[1]
+	require.Equal(t, fit.MesgWorkout, workout.num)
+	assert.Equal(t, []byte{5}, workout.fields[4], "swimming")
+	assert.Equal(t, []byte{17}, workout.fields[11], "lap swimming")
+	assert.Equal(t, uint32(7), workout.uint32(6))
+	assert.Equal(t, uint32(2500), workout.uint32(14))
+	assert.Equal(t, []byte{0}, workout.fields[15], "metric")
+	assert.Regexp(t, `^Training (Mon|Tue) 2024-03-0[45] \d\d:\d\d$`, workout.string(8))
+
+	type step struct {
+		duration byte
+		value    uint32
+		target   uint32
+	}
+	expected := []step{
+		{fit.DurationDistance, 40000, 0},
+		{fit.DurationDistance, 10000, 0},
+		{fit.DurationRepetitionTime, 100000, 0},
+		{fit.DurationRepeatUntilSteps, 1, 8},
+		{fit.DurationDistance, 5000, 0},
+		{fit.DurationTime, 20000, 0},
+		{fit.DurationRepeatUntilSteps, 4, 4},
+	}
+	for i, e := range expected {
+		m := messages[i+2]
+		require.Equal(t, fit.MesgWorkoutStep, m.num)
+		assert.Equal(t, uint32(i), m.uint32(254))
+		assert.Equal(t, []byte{e.duration}, m.fields[1], i)
+		assert.Equal(t, e.value, m.uint32(2), i)
+		if e.duration == fit.DurationRepeatUntilSteps {
+			assert.Equal(t, e.target, m.uint32(4), i)
+		}
+	}
+
+	assert.Equal(t, "warm up", messages[2].string(0))
+	assert.Equal(t, []byte{fit.IntensityActive}, messages[3].fields[7])
+	assert.Equal(t, "sprint group", messages[3].string(8))
+	assert.Equal(t, []byte{1}, messages[3].fields[9], "fins")
+	assert.Equal(t, []byte{fit.IntensityRest}, messages[4].fields[7])
+	assert.Equal(t, []byte{1}, messages[6].fields[9], "monofin as fins")
+
+	_, again := getWorkout(t, id)
+	assert.Equal(t, file, again)
+}
+
+func TestTrainingWorkout_Yards(t *testing.T) {
+	training := apidef.CreateTrainingRequest{
+		DurationMin: 60,
+		Pool:        &apidef.Pool{Length: 25, Unit: apidef.Yards},
+		Sets: []apidef.NewTrainingSet{
+			{DistanceYards: asPtr(100), Repeat: 4, StartType: apidef.None},
+		},
+		Start: time.Now(),
+	}
+	id := createTraining(t, &training).Id
+
+	res, file := getWorkout(t, id)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	messages := decodeFIT(t, file)
+	require.Len(t, messages, 4)
+	assert.Equal(t, uint32(2286), messages[1].uint32(14), "25 yards")
+	assert.Equal(t, []byte{1}, messages[1].fields[15], "statute")
+	assert.Equal(t, uint32(9144), messages[2].uint32(2), "100 yards")
+}
+
+func TestTrainingWorkout_NotFound(t *testing.T) {
+	res, _ := getWorkout(t, uuid.New())
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func getWorkout(t *testing.T, id uuid.UUID) (*http.Response, []byte) {
+	res, err := http.Get(TH.ts.URL + "/trainings/" + id.String() + "/workout.fit")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	return res, body
+}
+
+type fitMessage struct {
+	num    uint16
+	fields map[byte][]byte
+}
+
+func (m fitMessage) uint32(num byte) uint32 {
+	v := m.fields[num]
+	switch len(v) {
+	case 1:
+		return uint32(v[0])
+	case 2:
+		return uint32(binary.LittleEndian.Uint16(v))
+	case 4:
+		return binary.LittleEndian.Uint32(v)
+	}
+	return 0
+}
+
+func (m fitMessage) string(num byte) string {
+	v := m.fields[num]
+	for i, b := range v {
+		if b == 0 {
+			return string(v[:i])
+		}
+	}
+	return string(v)
+}
+
+// decodeFIT reads data messages of a FIT file with normal headers and
+// little endian fields, checking its header and CRC.
+func decodeFIT(t *testing.T, file []byte) []fitMessage {
+	require.GreaterOrEqual(t, len(file), 16)
+	require.Equal(t, byte(14), file[0])
+	require.Equal(t, ".FIT", string(file[8:12]))
+	require.Equal(t, uint16(0), fit.CRC(file[:14]), "header CRC")
+	require.Equal(t, uint16(0), fit.CRC(file), "file CRC")
+	require.Equal(t, len(file)-16, int(binary.LittleEndian.Uint32(file[4:8])))
+
+	type fieldDef struct{ num, size byte }
+	type definition struct {
+		num    uint16
+		fields []fieldDef
+	}
+
+	definitions := map[byte]definition{}
+	var messages []fitMessage
+	data := file[14 : len(file)-2]
+	for len(data) > 0 {
+		header := data[0]
+		require.Zero(t, header&0x80, "compressed timestamp header")
+		local := header & 0x0F
+
+		if header&0x40 != 0 {
+			require.Equal(t, byte(0), data[2], "little endian")
+			d := definition{num: binary.LittleEndian.Uint16(data[3:5])}
+			n := int(data[5])
+			for i := 0; i < n; i++ {
+				f := data[6+3*i : 9+3*i]
+				d.fields = append(d.fields, fieldDef{f[0], f[1]})
+			}
+			definitions[local] = d
+			data = data[6+3*n:]
+			continue
+		}
+
+		d, ok := definitions[local]
+		require.True(t, ok, "data message of undefined local type %d", local)
+		m := fitMessage{num: d.num, fields: map[byte][]byte{}}
+		data = data[1:]
+		for _, f := range d.fields {
+			m.fields[f.num] = data[:f.size]
+			data = data[f.size:]
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}